@@ -0,0 +1,61 @@
+// Package algebratest provides helpers to benchmark implementations of
+// the algebra package, it is kept apart so the algebra package doesn't
+// import testing.
+package algebratest
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+)
+
+// The benchmarks run the field operations on fixed full width operands so
+// their timings are comparable across runs, the operands are built before
+// the timer starts and the results are kept in a package variable so the
+// reported allocations are those of the operations themselves.
+
+// benchSink keeps the benchmark results alive.
+var benchSink algebra.FieldElement
+
+// benchOperands returns two full width elements of the field and the
+// exponent q-2 of the inversion by Fermat's little theorem.
+func benchOperands(ff algebra.FiniteField) (x, y algebra.FieldElement, e *algebra.Integer) {
+	q := ff.Modulus()
+	x = ff.NewFieldElement(new(algebra.Integer).Sub(q, algebra.FromInt64(12345)))
+	y = ff.NewFieldElement(new(algebra.Integer).Add(new(algebra.Integer).Rsh(q, 1), algebra.FromInt64(7)))
+	e = new(algebra.Integer).Sub(q, algebra.FromInt64(2))
+	return x, y, e
+}
+
+// BenchField benchmarks the arithmetic of the field ff as sub-benchmarks
+// Add, Mul, Exp and Inv, to be called from a Benchmark function e.g
+//
+//	func BenchmarkMyField(b *testing.B) { algebratest.BenchField(b, myField) }
+func BenchField(b *testing.B, ff algebra.FiniteField) {
+
+	x, y, e := benchOperands(ff)
+	b.Run("Add", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchSink = ff.Add(x, y)
+		}
+	})
+	b.Run("Mul", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchSink = ff.Mul(x, y)
+		}
+	})
+	b.Run("Exp", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchSink = x.Exp(e)
+		}
+	})
+	b.Run("Inv", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchSink = x.Inv()
+		}
+	})
+}
@@ -0,0 +1,12 @@
+package algebratest
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+)
+
+func BenchmarkField(b *testing.B) {
+	ff, _ := algebra.NewFiniteField(new(algebra.Integer).SetUint64(3221225473))
+	BenchField(b, ff)
+}
@@ -0,0 +1,115 @@
+package algebra
+
+import (
+	"math/big"
+)
+
+// Every FiniteField operation allocates the Integer of its result and the
+// modular reduction allocates a quotient, in hot loops (polynomial
+// evaluation, FRI folding) the allocations dominate the arithmetic.
+// A FieldArena hands out results backed by integers it owns and reuses
+// them once Reset is called, the elements it returned are then invalid
+// so values that outlive the loop must be copied out with Detach.
+
+// arenaChunkSize is the number of integers allocated at once by the arena.
+const arenaChunkSize = 256
+
+// FieldArena pools the integers backing the results of field operations.
+// An arena isn't safe for concurrent use.
+type FieldArena struct {
+	field  FiniteField
+	chunks [][]big.Int
+	used   int
+	quo    big.Int
+}
+
+// NewFieldArena creates an arena for the elements of field.
+func NewFieldArena(field FiniteField) *FieldArena {
+	return &FieldArena{field: field}
+}
+
+// Field returns the field of the arena.
+func (a *FieldArena) Field() FiniteField {
+	return a.field
+}
+
+// Len returns the number of integers handed out since the last Reset.
+func (a *FieldArena) Len() int {
+	return a.used
+}
+
+// Reset releases every element handed out by the arena at once.
+func (a *FieldArena) Reset() {
+	a.used = 0
+}
+
+// alloc returns the next free integer, the chunks are never reallocated
+// so the integers handed out keep their address.
+func (a *FieldArena) alloc() *Integer {
+	chunk, i := a.used/arenaChunkSize, a.used%arenaChunkSize
+	if chunk == len(a.chunks) {
+		a.chunks = append(a.chunks, make([]big.Int, arenaChunkSize))
+	}
+	a.used++
+	return &a.chunks[chunk][i]
+}
+
+// reduce reduces z modulo q in place reusing the arena's quotient buffer.
+func (a *FieldArena) reduce(z *Integer) FieldElement {
+	a.quo.QuoRem(z, a.field.q, z)
+	if z.Sign() < 0 {
+		z.Add(z, a.field.q)
+	}
+	return FieldElement{z, a.field}
+}
+
+// NewFieldElement returns x reduced into the field.
+func (a *FieldArena) NewFieldElement(x *Integer) FieldElement {
+	return a.reduce(a.alloc().Set(x))
+}
+
+// Detach returns a copy of x that isn't backed by the arena.
+func (a *FieldArena) Detach(x FieldElement) FieldElement {
+	return FieldElement{new(big.Int).Set(x.n), x.p}
+}
+
+// Add returns x + y.
+func (a *FieldArena) Add(x, y FieldElement) FieldElement {
+	z := a.alloc().Add(x.n, y.n)
+	if z.Cmp(a.field.q) >= 0 {
+		z.Sub(z, a.field.q)
+	}
+	return FieldElement{z, a.field}
+}
+
+// Sub returns x - y.
+func (a *FieldArena) Sub(x, y FieldElement) FieldElement {
+	z := a.alloc().Sub(x.n, y.n)
+	if z.Sign() < 0 {
+		z.Add(z, a.field.q)
+	}
+	return FieldElement{z, a.field}
+}
+
+// Mul returns x * y.
+func (a *FieldArena) Mul(x, y FieldElement) FieldElement {
+	return a.reduce(a.alloc().Mul(x.n, y.n))
+}
+
+// MulAdd returns x * y + c with a single reduction, c doesn't need to be
+// reduced which suits Horner's rule over polynomial coefficients.
+func (a *FieldArena) MulAdd(x, y FieldElement, c *Integer) FieldElement {
+	z := a.alloc().Mul(x.n, y.n)
+	return a.reduce(z.Add(z, c))
+}
+
+// Square returns x^2.
+func (a *FieldArena) Square(x FieldElement) FieldElement {
+	return a.Mul(x, x)
+}
+
+// Div returns x / y.
+func (a *FieldArena) Div(x, y FieldElement) FieldElement {
+	inv := a.alloc().ModInverse(y.n, a.field.q)
+	return a.reduce(inv.Mul(inv, x.n))
+}
@@ -0,0 +1,60 @@
+package algebra
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldArena(t *testing.T) {
+	arena := NewFieldArena(testField)
+	x := testField.NewFieldElementFromInt64(3221225470)
+	y := testField.NewFieldElementFromInt64(2718281)
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, arena.Add(x, y).Equal(testField.Add(x, y)))
+		assert.True(t, arena.Sub(y, x).Equal(testField.Sub(y, x)))
+		assert.True(t, arena.Mul(x, y).Equal(testField.Mul(x, y)))
+		assert.True(t, arena.Square(x).Equal(x.Square()))
+		assert.True(t, arena.Div(x, y).Equal(testField.Div(x, y)))
+		assert.True(t, arena.NewFieldElement(FromInt64(-5)).Equal(testField.NewFieldElementFromInt64(-5)))
+		assert.True(t, arena.MulAdd(x, y, FromInt64(-7)).Equal(testField.Add(testField.Mul(x, y), testField.NewFieldElementFromInt64(-7))))
+		assert.Equal(t, 7, arena.Len())
+		arena.Reset()
+		assert.Equal(t, 0, arena.Len())
+	}
+
+	// detached elements survive the arena reset
+	z := arena.Mul(x, y)
+	detached := arena.Detach(z)
+	arena.Reset()
+	arena.Add(y, y)
+	assert.True(t, detached.Equal(testField.Mul(x, y)))
+
+	// the arena grows past a chunk without moving the elements
+	first := arena.Add(x, y)
+	for i := 0; i < 3*arenaChunkSize; i++ {
+		arena.Mul(x, y)
+	}
+	assert.True(t, first.Equal(testField.Add(x, y)))
+}
+
+func BenchmarkFieldArena(b *testing.B) {
+	x := testField.NewFieldElementFromInt64(3221225470)
+	y := testField.NewFieldElementFromInt64(2718281)
+
+	b.Run("field", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			testField.Add(testField.Mul(x, y), y)
+		}
+	})
+	b.Run("arena", func(b *testing.B) {
+		b.ReportAllocs()
+		arena := NewFieldArena(testField)
+		for i := 0; i < b.N; i++ {
+			arena.Add(arena.Mul(x, y), y)
+			arena.Reset()
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package algebra
+
+import (
+	"testing"
+)
+
+// The benchmarks run the field operations on fixed full width operands so
+// their timings are comparable across runs, the operands are built before
+// the timer starts and the results are kept in package variables so the
+// reported allocations are those of the operations themselves, see
+// algebratest.BenchField to benchmark other fields.
+
+// benchSink and benchIntSink keep the benchmark results alive.
+var (
+	benchSink    FieldElement
+	benchIntSink *Integer
+)
+
+// benchOperands returns two full width elements of the field and the
+// exponent q-2 of the inversion by Fermat's little theorem.
+func benchOperands(ff FiniteField) (x, y FieldElement, e *Integer) {
+	q := ff.Modulus()
+	x = ff.NewFieldElement(new(Integer).Sub(q, FromInt64(12345)))
+	y = ff.NewFieldElement(new(Integer).Add(new(Integer).Rsh(q, 1), FromInt64(7)))
+	e = new(Integer).Sub(q, FromInt64(2))
+	return x, y, e
+}
+
+func BenchmarkModMul(b *testing.B) {
+	x, y, _ := benchOperands(testField)
+	q := testField.Modulus()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchIntSink = ModMul(x.n, y.n, q)
+	}
+}
+
+func BenchmarkModExp(b *testing.B) {
+	x, _, e := benchOperands(testField)
+	q := testField.Modulus()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchIntSink = ModExp(x.n, e, q)
+	}
+}
+
+func BenchmarkModInv(b *testing.B) {
+	x, _, _ := benchOperands(testField)
+	q := testField.Modulus()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchIntSink = ModInv(x.n, q)
+	}
+}
+
+func BenchmarkFieldAdd(b *testing.B) {
+	x, y, _ := benchOperands(testField)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchSink = testField.Add(x, y)
+	}
+}
+
+func BenchmarkSlices(b *testing.B) {
+	x, y := sliceOperands(testField, 1024)
+	b.Run("Add/naive", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r := make([]FieldElement, len(x))
+			for j := range r {
+				r[j] = testField.Add(x[j], y[j])
+			}
+		}
+	})
+	b.Run("Add/slices", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			testField.AddSlices(x, y)
+		}
+	})
+	b.Run("Mul/naive", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r := make([]FieldElement, len(x))
+			for j := range r {
+				r[j] = testField.Mul(x[j], y[j])
+			}
+		}
+	})
+	b.Run("Mul/slices", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			testField.MulSlices(x, y)
+		}
+	})
+}
@@ -0,0 +1,35 @@
+//go:build starkdebug
+
+package algebra
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFieldElementUncheckedAssertion(t *testing.T) {
+	assert.Panics(t, func() { testField.NewFieldElementUnchecked(testField.Modulus()) })
+	assert.Panics(t, func() { testField.NewFieldElementUnchecked(FromInt64(-1)) })
+	assert.NotPanics(t, func() { testField.NewFieldElementUnchecked(new(Integer).SetUint64(3221225472)) })
+}
+
+func TestAssertValid(t *testing.T) {
+	x, y := testField.NewFieldElementFromInt64(3), testField.NewFieldElementFromInt64(-5)
+	assert.NotPanics(t, func() { testField.Div(testField.Mul(x, y), testField.Sub(x, y)) })
+
+	corrupted := FieldElement{new(Integer).Add(testField.Modulus(), One), testField}
+	for name, op := range map[string]func(){
+		"Add": func() { testField.Add(x, corrupted) },
+		"Sub": func() { testField.Sub(corrupted, y) },
+		"Mul": func() { testField.Mul(x, corrupted) },
+		"Div": func() { testField.Div(corrupted, y) },
+		"Exp": func() { corrupted.Exp(FromInt64(3)) },
+		"Inv": func() { corrupted.Inv() },
+	} {
+		assert.Panics(t, op, name)
+	}
+	assert.PanicsWithValue(t, "corrupted field element -1 isn't reduced modulo 3221225473", func() {
+		FieldElement{FromInt64(-1), testField}.Inv()
+	})
+}
@@ -0,0 +1,129 @@
+package algebra
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The quadratic extension Fq2 of a prime field Fq is Fq[u]/(u^2 - r) where
+// r is a quadratic non-residue of Fq, its elements are a + b.u for a, b in
+// Fq. Sampling the challenges of a proof from Fq2 rather than Fq squares
+// the size of the space they're drawn from, which is what small fields
+// need to reach a sound number of bits.
+
+var errQuadraticResidue = errors.New("extension non-residue is a square of the base field")
+
+// ExtensionField is the quadratic extension of a prime field.
+type ExtensionField struct {
+	base       FiniteField
+	nonResidue FieldElement
+}
+
+// NewExtensionField creates the extension Fq[u]/(u^2 - nonResidue) of the
+// base field, nonResidue must not be a square of the base field (e.g a
+// generator of its multiplicative group).
+func NewExtensionField(base FiniteField, nonResidue FieldElement) (ExtensionField, error) {
+
+	// Euler's criterion : r is a square iff r^((q-1)/2) = 1
+	e := new(Integer).Rsh(new(Integer).Sub(base.Modulus(), One), 1)
+	if nonResidue.IsZero() || nonResidue.Exp(e).Equal(base.One()) {
+		return ExtensionField{}, errQuadraticResidue
+	}
+	return ExtensionField{base: base, nonResidue: nonResidue}, nil
+}
+
+// Base returns the base field.
+func (ef ExtensionField) Base() FiniteField {
+	return ef.base
+}
+
+// NonResidue returns r such that u^2 = r.
+func (ef ExtensionField) NonResidue() FieldElement {
+	return ef.nonResidue
+}
+
+// ExtensionElement is the element A + B.u of a quadratic extension.
+type ExtensionElement struct {
+	A FieldElement
+	B FieldElement
+}
+
+// NewElement returns a + b.u.
+func (ef ExtensionField) NewElement(a, b FieldElement) ExtensionElement {
+	return ExtensionElement{A: a, B: b}
+}
+
+// Embed returns the base field element a as a + 0.u.
+func (ef ExtensionField) Embed(a FieldElement) ExtensionElement {
+	return ExtensionElement{A: a, B: ef.base.Zero()}
+}
+
+// Zero returns the 0 on Fq2
+func (ef ExtensionField) Zero() ExtensionElement {
+	return ef.Embed(ef.base.Zero())
+}
+
+// One returns the 1 on Fq2
+func (ef ExtensionField) One() ExtensionElement {
+	return ef.Embed(ef.base.One())
+}
+
+// Add sums two extension elements.
+func (ef ExtensionField) Add(x, y ExtensionElement) ExtensionElement {
+	return ExtensionElement{A: ef.base.Add(x.A, y.A), B: ef.base.Add(x.B, y.B)}
+}
+
+// Sub subs two extension elements.
+func (ef ExtensionField) Sub(x, y ExtensionElement) ExtensionElement {
+	return ExtensionElement{A: ef.base.Sub(x.A, y.A), B: ef.base.Sub(x.B, y.B)}
+}
+
+// Mul multiplies two extension elements
+// (a + b.u)(c + d.u) = ac + r.bd + (ad + bc).u
+func (ef ExtensionField) Mul(x, y ExtensionElement) ExtensionElement {
+
+	f := ef.base
+	a := f.Add(f.Mul(x.A, y.A), f.Mul(ef.nonResidue, f.Mul(x.B, y.B)))
+	b := f.Add(f.Mul(x.A, y.B), f.Mul(x.B, y.A))
+	return ExtensionElement{A: a, B: b}
+}
+
+// MulBase multiplies an extension element by a base field element.
+func (ef ExtensionField) MulBase(x ExtensionElement, c FieldElement) ExtensionElement {
+	return ExtensionElement{A: ef.base.Mul(x.A, c), B: ef.base.Mul(x.B, c)}
+}
+
+// Inv returns the inverse of x trough its norm
+// (a + b.u)^-1 = (a - b.u)/(a^2 - r.b^2)
+// It panics if x is zero.
+func (ef ExtensionField) Inv(x ExtensionElement) ExtensionElement {
+
+	if x.IsZero() {
+		panic(fmt.Sprintf("inverse of zero in the extension of F%s", ef.base.Modulus()))
+	}
+	f := ef.base
+	norm := f.Sub(x.A.Square(), f.Mul(ef.nonResidue, x.B.Square()))
+	inv := norm.Inv()
+	return ExtensionElement{A: f.Mul(x.A, inv), B: f.Mul(x.B.Neg(), inv)}
+}
+
+// Div divides two extension elements.
+func (ef ExtensionField) Div(x, y ExtensionElement) ExtensionElement {
+	return ef.Mul(x, ef.Inv(y))
+}
+
+// IsZero returns true if both coordinates are zero.
+func (e ExtensionElement) IsZero() bool {
+	return e.A.IsZero() && e.B.IsZero()
+}
+
+// Equal checks for equality between extension elements.
+func (e ExtensionElement) Equal(other ExtensionElement) bool {
+	return e.A.Equal(other.A) && e.B.Equal(other.B)
+}
+
+// Bytes returns the fixed width big-endian encodings of A then B, each
+// ByteLen bytes wide so the encoding is unambiguous.
+func (e ExtensionElement) Bytes() []byte {
+	return append(e.A.Bytes(), e.B.Bytes()...)
+}
@@ -0,0 +1,29 @@
+package algebra
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtensionField(t *testing.T) {
+	_, err := NewExtensionField(testField, testField.NewFieldElementFromInt64(4))
+	assert.ErrorIs(t, err, errQuadraticResidue)
+
+	r := testField.NewFieldElementFromInt64(5)
+	ext, err := NewExtensionField(testField, r)
+	assert.NoError(t, err)
+
+	u := ext.NewElement(testField.Zero(), testField.One())
+	assert.True(t, ext.Mul(u, u).Equal(ext.Embed(r)))
+
+	x := ext.NewElement(testField.NewFieldElementFromInt64(3141592), testField.NewFieldElementFromInt64(2718281))
+	y := ext.NewElement(testField.NewFieldElementFromInt64(12345), testField.NewFieldElementFromInt64(0))
+	assert.True(t, ext.Mul(x, ext.Inv(x)).Equal(ext.One()))
+	assert.True(t, ext.Mul(ext.Div(x, y), y).Equal(x))
+	assert.True(t, ext.Sub(ext.Add(x, y), y).Equal(x))
+	assert.True(t, ext.MulBase(x, y.A).Equal(ext.Mul(x, y)))
+
+	assert.Len(t, x.Bytes(), 2*testField.ByteLen())
+	assert.Panics(t, func() { ext.Inv(ext.Zero()) })
+}
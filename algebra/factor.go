@@ -10,6 +10,8 @@ func PollardRho(n *Integer) *Integer {
 
 	for {
 		a = ModAdd(ModExp(a, FromInt64(2), nil), One, n)
+		// the hare moves twice as fast as the tortoise
+		b = ModAdd(ModExp(b, FromInt64(2), nil), One, n)
 		b = ModAdd(ModExp(b, FromInt64(2), nil), One, n)
 
 		d = GCD(Sub(a, b), n)
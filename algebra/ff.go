@@ -4,6 +4,7 @@ package algebra
 
 import (
 	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/big"
@@ -15,6 +16,8 @@ const (
 
 var (
 	errNonPrimeModulus = errors.New("finite fields are defined over prime modulus only")
+	errEncodingLength  = errors.New("encoded field element has the wrong length")
+	errNotInField      = errors.New("n not in the F")
 )
 
 // FiniteField represents a field over modulus q.
@@ -42,6 +45,17 @@ func (ff FiniteField) Modulus() *Integer {
 	return ff.q
 }
 
+// BitLen returns the bit length of the field modulus
+func (ff FiniteField) BitLen() int {
+	return ff.q.BitLen()
+}
+
+// ByteLen returns the number of bytes needed to encode any element of the
+// field, this is the canonical width used by FieldElement.Bytes.
+func (ff FiniteField) ByteLen() int {
+	return (ff.q.BitLen() + 7) / 8
+}
+
 // Char returns the characteristic of the finite field
 func (ff FiniteField) Char() *Integer {
 	return ff.q
@@ -66,22 +80,38 @@ func (ff FiniteField) Rand() (FieldElement, error) {
 
 // Add sums two FintieField elements
 func (ff FiniteField) Add(x, y FieldElement) FieldElement {
-	return ff.NewFieldElement(ModAdd(x.n, y.n, ff.q))
+	x.assertValid()
+	y.assertValid()
+	r := ff.NewFieldElement(ModAdd(x.n, y.n, ff.q))
+	r.assertValid()
+	return r
 }
 
 // Sub subs two FiniteField elements
 func (ff FiniteField) Sub(x, y FieldElement) FieldElement {
-	return ff.NewFieldElement(ModSub(x.n, y.n, ff.q))
+	x.assertValid()
+	y.assertValid()
+	r := ff.NewFieldElement(ModSub(x.n, y.n, ff.q))
+	r.assertValid()
+	return r
 }
 
 // Mul multiplies two FiniteField elements
 func (ff FiniteField) Mul(x, y FieldElement) FieldElement {
-	return ff.NewFieldElement(ModMul(x.n, y.n, ff.q))
+	x.assertValid()
+	y.assertValid()
+	r := ff.NewFieldElement(ModMul(x.n, y.n, ff.q))
+	r.assertValid()
+	return r
 }
 
 // Div divides two FiniteField elements
 func (ff FiniteField) Div(x, y FieldElement) FieldElement {
-	return ff.NewFieldElement(ModDiv(x.n, y.n, ff.q))
+	x.assertValid()
+	y.assertValid()
+	r := ff.NewFieldElement(ModDiv(x.n, y.n, ff.q))
+	r.assertValid()
+	return r
 }
 
 // FieldElement is defined over a finite field of order p
@@ -117,6 +147,33 @@ func (ff FiniteField) NewFieldElement(x *Integer) FieldElement {
 	return FieldElement{Mod(x, ff.q), ff}
 }
 
+// NewFieldElementUnchecked returns the field element x without reducing
+// it, x must already be in [0, q) e.g a value reduced by another library.
+// The element shares x so x must not be modified afterwards.
+// Debug builds (-tags starkdebug) panic if x is out of range.
+func (ff FiniteField) NewFieldElementUnchecked(x *Integer) FieldElement {
+
+	if debug && (x.Sign() < 0 || x.Cmp(ff.q) >= 0) {
+		panic(fmt.Sprintf("unchecked field element %s isn't reduced modulo %s", x, ff.q))
+	}
+	return FieldElement{x, ff}
+}
+
+// assertValid panics if fe isn't reduced i.e its representative isn't in
+// [0, q), it only checks in debug builds (-tags starkdebug) so it can be
+// called on every operation.
+func (fe FieldElement) assertValid() {
+	if !debug {
+		return
+	}
+	if fe.n == nil || fe.p.q == nil {
+		panic("field element isn't initialized")
+	}
+	if fe.n.Sign() < 0 || fe.n.Cmp(fe.p.q) >= 0 {
+		panic(fmt.Sprintf("corrupted field element %s isn't reduced modulo %s", fe.n, fe.p.q))
+	}
+}
+
 // NewFieldElementFromInt64 takes int64 params
 func (ff FiniteField) NewFieldElementFromInt64(x int64) FieldElement {
 	return ff.NewFieldElement(FromInt64(x))
@@ -135,6 +192,21 @@ func (fe FieldElement) Neg() FieldElement {
 	return FieldElement{r, fe.p}
 }
 
+// AddInt64 returns fe + n with n lifted into the field
+func (fe FieldElement) AddInt64(n int64) FieldElement {
+	return fe.p.Add(fe, fe.p.NewFieldElementFromInt64(n))
+}
+
+// SubInt64 returns fe - n with n lifted into the field
+func (fe FieldElement) SubInt64(n int64) FieldElement {
+	return fe.p.Sub(fe, fe.p.NewFieldElementFromInt64(n))
+}
+
+// MulInt64 returns n*fe with n lifted into the field
+func (fe FieldElement) MulInt64(n int64) FieldElement {
+	return fe.p.Mul(fe, fe.p.NewFieldElementFromInt64(n))
+}
+
 // Square returns fe^2
 func (fe FieldElement) Square() FieldElement {
 	return fe.p.Mul(fe, fe)
@@ -142,14 +214,46 @@ func (fe FieldElement) Square() FieldElement {
 
 // Exp computes fe^e
 func (fe FieldElement) Exp(e *Integer) FieldElement {
-	var r = ModExp(fe.n, e, fe.p.q)
-	return FieldElement{r, fe.p}
+	fe.assertValid()
+	var r = FieldElement{ModExp(fe.n, e, fe.p.q), fe.p}
+	r.assertValid()
+	return r
 }
 
 // Inv computes fe-1
 func (fe FieldElement) Inv() FieldElement {
-	var r = ModInv(fe.n, fe.p.q)
-	return FieldElement{r, fe.p}
+	fe.assertValid()
+	var r = FieldElement{ModInv(fe.n, fe.p.q), fe.p}
+	r.assertValid()
+	return r
+}
+
+// BatchInv inverts every element with a single field inversion using
+// Montgomery's trick, the ith result is elems[i]^-1.
+// It panics if an element is zero.
+func (ff FiniteField) BatchInv(elems []FieldElement) []FieldElement {
+
+	if len(elems) == 0 {
+		return nil
+	}
+	// prefix[i] is the product of elems[:i+1]
+	prefix := make([]FieldElement, len(elems))
+	acc := ff.One()
+	for i, elem := range elems {
+		if elem.IsZero() {
+			panic(fmt.Sprintf("can't invert zero at index %d", i))
+		}
+		acc = ff.Mul(acc, elem)
+		prefix[i] = acc
+	}
+	invs := make([]FieldElement, len(elems))
+	accInv := acc.Inv()
+	for i := len(elems) - 1; i > 0; i-- {
+		invs[i] = ff.Mul(accInv, prefix[i-1])
+		accInv = ff.Mul(accInv, elems[i])
+	}
+	invs[0] = accInv
+	return invs
 }
 
 // IsZero returns if the fieldelement is zero
@@ -167,6 +271,100 @@ func (fe FieldElement) Big() *Integer {
 	return new(big.Int).Set(fe.n)
 }
 
+// Bytes returns the fixed width big-endian encoding of the field element
+// the width is given by the field's ByteLen.
+func (fe FieldElement) Bytes() []byte {
+	return fe.p.BytesBE(fe)
+}
+
+// BytesBE returns the fixed width big-endian encoding of fe, this is the
+// default encoding returned by FieldElement.Bytes.
+func (ff FiniteField) BytesBE(fe FieldElement) []byte {
+	buf := make([]byte, ff.ByteLen())
+	return fe.n.FillBytes(buf)
+}
+
+// BytesLE returns the fixed width little-endian encoding of fe.
+func (ff FiniteField) BytesLE(fe FieldElement) []byte {
+	buf := ff.BytesBE(fe)
+	reverseBytes(buf)
+	return buf
+}
+
+// FromBytes decodes a fixed width big-endian encoded field element.
+func (ff FiniteField) FromBytes(b []byte) (FieldElement, error) {
+	if len(b) != ff.ByteLen() {
+		return FieldElement{}, errEncodingLength
+	}
+	n := new(Integer).SetBytes(b)
+	if n.Cmp(ff.q) >= 0 {
+		return FieldElement{}, errNotInField
+	}
+	return FieldElement{n, ff}, nil
+}
+
+// FromBytesLE decodes a fixed width little-endian encoded field element.
+func (ff FiniteField) FromBytesLE(b []byte) (FieldElement, error) {
+	buf := append([]byte{}, b...)
+	reverseBytes(buf)
+	return ff.FromBytes(buf)
+}
+
+// Key returns a canonical string of fe for use as a map key, derived from
+// Bytes so equal elements have equal keys however they were constructed.
+// Elements of different fields with the same representative of the same
+// width share a key, it isn't cryptographically significant.
+func (fe FieldElement) Key() string {
+	return string(fe.Bytes())
+}
+
+// Hex returns the hexadecimal encoding of Bytes i.e fixed width and zero
+// padded big-endian.
+func (fe FieldElement) Hex() string {
+	return hex.EncodeToString(fe.Bytes())
+}
+
+// FromHex decodes a field element encoded by Hex.
+func (ff FiniteField) FromHex(s string) (FieldElement, error) {
+	if len(s) != 2*ff.ByteLen() {
+		return FieldElement{}, errEncodingLength
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return FieldElement{}, err
+	}
+	return ff.FromBytes(b)
+}
+
+// Bits returns the little-endian bit decomposition of fe over the bit
+// length of the field modulus i.e bits[i] is the ith bit of fe.
+func (fe FieldElement) Bits() []bool {
+	bits := make([]bool, fe.p.BitLen())
+	for i := range bits {
+		bits[i] = fe.n.Bit(i) == 1
+	}
+	return bits
+}
+
+// FromBits returns the element of little-endian bit decomposition bits
+// reduced modulo the field modulus, bits may be longer than the modulus
+// bit length.
+func (ff FiniteField) FromBits(bits []bool) FieldElement {
+	n := new(Integer)
+	for i, b := range bits {
+		if b {
+			n.SetBit(n, i, 1)
+		}
+	}
+	return ff.NewFieldElement(n)
+}
+
+func reverseBytes(b []byte) {
+	for left, right := 0, len(b)-1; left < right; left, right = left+1, right-1 {
+		b[left], b[right] = b[right], b[left]
+	}
+}
+
 // Equal checks for equality between field elements
 func (fe FieldElement) Equal(other FieldElement) bool {
 
@@ -177,11 +375,14 @@ func (fe FieldElement) Equal(other FieldElement) bool {
 	return true
 }
 
-// Cmp compares field elements
+// Cmp compares field elements and returns -1, 0 or 1 following a total
+// order : elements are ordered by the modulus of their field first then by
+// their representative, so elements of different fields are never equal
+// but still compare consistently which allows sorting across fields.
 func (ff FiniteField) Cmp(x FieldElement, y FieldElement) int {
 
-	if x.p.q.Cmp(y.p.q) != 0 {
-		return -1
+	if c := x.p.q.Cmp(y.p.q); c != 0 {
+		return c
 	}
 	return x.n.Cmp(y.n)
 }
\ No newline at end of file
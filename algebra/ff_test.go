@@ -0,0 +1,158 @@
+package algebra
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testField is the prime field used across the stark package.
+var testField, _ = NewFiniteField(new(Integer).SetUint64(3221225473))
+
+func TestByteLen(t *testing.T) {
+	assert.Equal(t, 32, testField.BitLen())
+	assert.Equal(t, 4, testField.ByteLen())
+
+	for _, x := range []int64{0, 1, 255, 3141592, 3221225472} {
+		fe := testField.NewFieldElementFromInt64(x)
+		assert.Len(t, fe.Bytes(), testField.ByteLen())
+		assert.Equal(t, 0, fe.Big().Cmp(new(Integer).SetBytes(fe.Bytes())))
+	}
+}
+
+func TestEndianness(t *testing.T) {
+	fe := testField.NewFieldElementFromInt64(0x01020304)
+	assert.Equal(t, []byte{1, 2, 3, 4}, testField.BytesBE(fe))
+	assert.Equal(t, []byte{4, 3, 2, 1}, testField.BytesLE(fe))
+	assert.Equal(t, fe.Bytes(), testField.BytesBE(fe))
+
+	for _, x := range []int64{0, 1, 0x01020304, 3221225472} {
+		fe := testField.NewFieldElementFromInt64(x)
+
+		be, err := testField.FromBytes(testField.BytesBE(fe))
+		assert.NoError(t, err)
+		assert.True(t, be.Equal(fe))
+
+		le, err := testField.FromBytesLE(testField.BytesLE(fe))
+		assert.NoError(t, err)
+		assert.True(t, le.Equal(fe))
+	}
+
+	_, err := testField.FromBytes([]byte{1, 2, 3})
+	assert.Error(t, err)
+	_, err = testField.FromBytes([]byte{0xff, 0xff, 0xff, 0xff})
+	assert.Error(t, err)
+}
+
+func TestInt64Scalars(t *testing.T) {
+	fe := testField.NewFieldElementFromInt64(10)
+
+	assert.Equal(t, "15", fe.AddInt64(5).Big().String())
+	assert.Equal(t, "5", fe.AddInt64(-5).Big().String())
+	assert.Equal(t, "3221225470", fe.SubInt64(13).Big().String())
+	assert.Equal(t, "22", fe.SubInt64(-12).Big().String())
+	assert.Equal(t, "3221225463", fe.MulInt64(-1).Big().String())
+
+	// scalars larger than q are reduced
+	assert.Equal(t, "11", fe.AddInt64(3221225474).Big().String())
+	assert.Equal(t, "9", fe.SubInt64(3221225474).Big().String())
+	assert.Equal(t, "20", fe.MulInt64(3221225475).Big().String())
+}
+
+func TestHex(t *testing.T) {
+	fe := testField.NewFieldElementFromInt64(0xabc)
+	assert.Equal(t, "00000abc", fe.Hex())
+
+	for _, x := range []int64{0, 1, 0xabc, 3221225472} {
+		fe := testField.NewFieldElementFromInt64(x)
+		back, err := testField.FromHex(fe.Hex())
+		assert.NoError(t, err)
+		assert.True(t, back.Equal(fe))
+	}
+
+	for _, s := range []string{"abc", "0000000abc", "zzzzzzzz", "ffffffff"} {
+		_, err := testField.FromHex(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestCmpAcrossFields(t *testing.T) {
+	small, _ := NewFiniteField(new(Integer).SetUint64(17))
+
+	x := testField.NewFieldElementFromInt64(3)
+	y := small.NewFieldElementFromInt64(16)
+	// the modulus is compared before the representative
+	assert.Equal(t, 1, testField.Cmp(x, y))
+	assert.Equal(t, -1, testField.Cmp(y, x))
+	assert.Equal(t, 0, testField.Cmp(x, testField.NewFieldElementFromInt64(3)))
+
+	elems := []FieldElement{
+		testField.NewFieldElementFromInt64(5),
+		small.NewFieldElementFromInt64(9),
+		testField.NewFieldElementFromInt64(1),
+		small.NewFieldElementFromInt64(2),
+	}
+	sort.Slice(elems, func(i, j int) bool { return testField.Cmp(elems[i], elems[j]) < 0 })
+
+	expected := []FieldElement{
+		small.NewFieldElementFromInt64(2),
+		small.NewFieldElementFromInt64(9),
+		testField.NewFieldElementFromInt64(1),
+		testField.NewFieldElementFromInt64(5),
+	}
+	for i := range expected {
+		assert.True(t, elems[i].Equal(expected[i]), "index %d", i)
+	}
+}
+
+func TestBits(t *testing.T) {
+	for _, x := range []int64{0, 1, 6, 0x01020304, 3221225472} {
+		fe := testField.NewFieldElementFromInt64(x)
+		bits := fe.Bits()
+		assert.Len(t, bits, testField.BitLen())
+		assert.True(t, testField.FromBits(bits).Equal(fe), "x = %d", x)
+	}
+	assert.Equal(t, []bool{false, true, true}, testField.NewFieldElementFromInt64(6).Bits()[:3])
+
+	// q + 5 = 2^31 + 2^30 + 6 doesn't fit the field and is reduced
+	bits := testField.NewFieldElementFromInt64(6).Bits()
+	bits[30], bits[31] = true, true
+	assert.True(t, testField.FromBits(bits).Equal(testField.NewFieldElementFromInt64(5)))
+}
+
+func TestBatchInv(t *testing.T) {
+	elems := []FieldElement{
+		testField.NewFieldElementFromInt64(1),
+		testField.NewFieldElementFromInt64(-2),
+		testField.NewFieldElementFromInt64(31415),
+		testField.NewFieldElementFromInt64(7),
+	}
+	invs := testField.BatchInv(elems)
+	assert.Len(t, invs, len(elems))
+	for i, elem := range elems {
+		assert.True(t, invs[i].Equal(elem.Inv()), "element %d", i)
+	}
+
+	assert.Empty(t, testField.BatchInv(nil))
+	assert.Panics(t, func() { testField.BatchInv([]FieldElement{testField.One(), testField.Zero()}) })
+}
+
+func TestKey(t *testing.T) {
+	a := testField.NewFieldElementFromInt64(-1)
+	b := testField.NewFieldElement(new(Integer).Sub(testField.Modulus(), One))
+	c, err := testField.FromHex(a.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, a.Key(), b.Key())
+	assert.Equal(t, a.Key(), c.Key())
+	assert.NotEqual(t, a.Key(), testField.One().Key())
+
+	seen := map[string]bool{a.Key(): true}
+	assert.True(t, seen[testField.Add(testField.Zero(), b).Key()])
+}
+
+func TestNewFieldElementUnchecked(t *testing.T) {
+	x := new(Integer).SetUint64(2338775057)
+	assert.True(t, testField.NewFieldElementUnchecked(x).Equal(testField.NewFieldElement(x)))
+	assert.True(t, testField.NewFieldElementUnchecked(FromInt64(0)).IsZero())
+}
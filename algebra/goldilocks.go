@@ -0,0 +1,177 @@
+package algebra
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+// The Goldilocks prime p = 2^64 - 2^32 + 1 reduces without a division :
+// since 2^64 = 2^32 - 1 mod p and 2^96 = -1 mod p, a 128 bits product
+// hi.2^64 + lo with hi = hi_hi.2^32 + hi_lo is lo - hi_hi + hi_lo.(2^32 - 1)
+// mod p, i.e a subtraction, a 32x32 bits product and an addition on 64 bits
+// words. GoldilocksField is an alternative backend to the big.Int based
+// FiniteField for that prime.
+
+const (
+	// GoldilocksModulus is 2^64 - 2^32 + 1.
+	GoldilocksModulus = 0xffffffff00000001
+	// goldilocksEpsilon is 2^64 mod p i.e 2^32 - 1.
+	goldilocksEpsilon = 0xffffffff
+)
+
+// GoldilocksElement is an element of the Goldilocks field, it is always
+// reduced i.e lower than GoldilocksModulus.
+type GoldilocksElement uint64
+
+// GoldilocksField is the prime field of order GoldilocksModulus backed by
+// uint64 arithmetic.
+type GoldilocksField struct{}
+
+// Zero returns the 0 on Fp
+func (GoldilocksField) Zero() GoldilocksElement {
+	return 0
+}
+
+// One returns the 1 on Fp
+func (GoldilocksField) One() GoldilocksElement {
+	return 1
+}
+
+// Modulus returns the field modulus.
+func (GoldilocksField) Modulus() *Integer {
+	return new(Integer).SetUint64(GoldilocksModulus)
+}
+
+// Reference returns the big.Int based field of the same order.
+func (gf GoldilocksField) Reference() FiniteField {
+	return FiniteField{gf.Modulus()}
+}
+
+// FromUint64 returns x mod p.
+func (GoldilocksField) FromUint64(x uint64) GoldilocksElement {
+	if x >= GoldilocksModulus {
+		x -= GoldilocksModulus
+	}
+	return GoldilocksElement(x)
+}
+
+// NewElement returns x mod p.
+func (gf GoldilocksField) NewElement(x *Integer) GoldilocksElement {
+	return GoldilocksElement(Mod(x, gf.Modulus()).Uint64())
+}
+
+// Rand returns a uniformly random field element.
+func (gf GoldilocksField) Rand() (GoldilocksElement, error) {
+	var buf [8]byte
+	for {
+		if _, err := rand.Read(buf[:]); err != nil {
+			return 0, err
+		}
+		// rejection keeps the distribution uniform
+		if x := binary.BigEndian.Uint64(buf[:]); x < GoldilocksModulus {
+			return GoldilocksElement(x), nil
+		}
+	}
+}
+
+// Big returns the element as an Integer.
+func (x GoldilocksElement) Big() *Integer {
+	return new(Integer).SetUint64(uint64(x))
+}
+
+// IsZero returns true if x is zero.
+func (x GoldilocksElement) IsZero() bool {
+	return x == 0
+}
+
+// FromFieldElement converts an element of the reference field.
+// It panics if fe belongs to another field.
+func (gf GoldilocksField) FromFieldElement(fe FieldElement) GoldilocksElement {
+	if fe.p.q == nil || !fe.p.q.IsUint64() || fe.p.q.Uint64() != GoldilocksModulus {
+		panic(fmt.Sprintf("%s isn't an element of the Goldilocks field", fe.String()))
+	}
+	return GoldilocksElement(fe.n.Uint64())
+}
+
+// FieldElement converts x to an element of the reference field.
+func (x GoldilocksElement) FieldElement() FieldElement {
+	return GoldilocksField{}.Reference().NewFieldElement(new(Integer).SetUint64(uint64(x)))
+}
+
+// Add sums two field elements.
+func (GoldilocksField) Add(x, y GoldilocksElement) GoldilocksElement {
+	s, carry := bits.Add64(uint64(x), uint64(y), 0)
+	// x + y < 2p so a carry leaves s + 2^64 - p below p
+	if carry != 0 {
+		s += goldilocksEpsilon
+	}
+	if s >= GoldilocksModulus {
+		s -= GoldilocksModulus
+	}
+	return GoldilocksElement(s)
+}
+
+// Sub subs two field elements.
+func (GoldilocksField) Sub(x, y GoldilocksElement) GoldilocksElement {
+	d, borrow := bits.Sub64(uint64(x), uint64(y), 0)
+	// x - y + 2^64 wrapped, adding p is subtracting 2^32 - 1
+	if borrow != 0 {
+		d -= goldilocksEpsilon
+	}
+	return GoldilocksElement(d)
+}
+
+// reduce128 reduces hi.2^64 + lo modulo p.
+func reduce128(hi, lo uint64) GoldilocksElement {
+
+	hiHi, hiLo := hi>>32, hi&goldilocksEpsilon
+	// lo - hi_hi since 2^96 = -1
+	t, borrow := bits.Sub64(lo, hiHi, 0)
+	if borrow != 0 {
+		t -= goldilocksEpsilon
+	}
+	// + hi_lo.(2^32 - 1) since 2^64 = 2^32 - 1
+	r, carry := bits.Add64(t, hiLo*goldilocksEpsilon, 0)
+	if carry != 0 {
+		r += goldilocksEpsilon
+	}
+	if r >= GoldilocksModulus {
+		r -= GoldilocksModulus
+	}
+	return GoldilocksElement(r)
+}
+
+// Mul multiplies two field elements.
+func (GoldilocksField) Mul(x, y GoldilocksElement) GoldilocksElement {
+	hi, lo := bits.Mul64(uint64(x), uint64(y))
+	return reduce128(hi, lo)
+}
+
+// Exp computes x^e by square and multiply, e must be non negative.
+func (gf GoldilocksField) Exp(x GoldilocksElement, e *Integer) GoldilocksElement {
+	r := gf.One()
+	for i := 0; i < e.BitLen(); i++ {
+		if e.Bit(i) == 1 {
+			r = gf.Mul(r, x)
+		}
+		x = gf.Mul(x, x)
+	}
+	return r
+}
+
+// Inv computes x^-1 = x^(p-2).
+// It panics if x is zero.
+func (gf GoldilocksField) Inv(x GoldilocksElement) GoldilocksElement {
+	if x == 0 {
+		panic("inverse of zero in the Goldilocks field")
+	}
+	return gf.Exp(x, new(Integer).SetUint64(GoldilocksModulus-2))
+}
+
+// Div divides two field elements.
+// It panics if y is zero.
+func (gf GoldilocksField) Div(x, y GoldilocksElement) GoldilocksElement {
+	return gf.Mul(x, gf.Inv(y))
+}
@@ -0,0 +1,62 @@
+package algebra
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoldilocksField(t *testing.T) {
+	gf := GoldilocksField{}
+	ref := gf.Reference()
+	rng := rand.New(rand.NewSource(1))
+
+	operands := []uint64{0, 1, 2, goldilocksEpsilon, GoldilocksModulus - 2, GoldilocksModulus - 1}
+	for i := 0; i < 1000; i++ {
+		operands = append(operands, rng.Uint64()%GoldilocksModulus)
+	}
+	for i := 0; i+1 < len(operands); i++ {
+		x, y := gf.FromUint64(operands[i]), gf.FromUint64(operands[i+1])
+		rx, ry := x.FieldElement(), y.FieldElement()
+		assert.True(t, gf.Add(x, y).FieldElement().Equal(ref.Add(rx, ry)), "%d + %d", x, y)
+		assert.True(t, gf.Sub(x, y).FieldElement().Equal(ref.Sub(rx, ry)), "%d - %d", x, y)
+		assert.True(t, gf.Mul(x, y).FieldElement().Equal(ref.Mul(rx, ry)), "%d * %d", x, y)
+		if y != 0 {
+			assert.True(t, gf.Div(x, y).FieldElement().Equal(ref.Div(rx, ry)), "%d / %d", x, y)
+		}
+		assert.Equal(t, x, gf.FromFieldElement(rx))
+	}
+	x := gf.FromUint64(operands[10])
+	assert.True(t, gf.Exp(x, FromInt64(12345)).FieldElement().Equal(x.FieldElement().Exp(FromInt64(12345))))
+	assert.Equal(t, gf.One(), gf.Mul(x, gf.Inv(x)))
+	assert.Equal(t, GoldilocksElement(5), gf.FromUint64(GoldilocksModulus+5))
+	assert.Equal(t, GoldilocksElement(GoldilocksModulus-1), gf.NewElement(FromInt64(-1)))
+	r, err := gf.Rand()
+	assert.NoError(t, err)
+	assert.Less(t, uint64(r), uint64(GoldilocksModulus))
+
+	assert.Panics(t, func() { gf.Inv(gf.Zero()) })
+	assert.Panics(t, func() { gf.FromFieldElement(testField.One()) })
+}
+
+func BenchmarkGoldilocksMul(b *testing.B) {
+	gf := GoldilocksField{}
+	ref := gf.Reference()
+	x, y, _ := benchOperands(ref)
+	b.Run("uint64", func(b *testing.B) {
+		gx, gy := gf.FromFieldElement(x), gf.FromFieldElement(y)
+		var r GoldilocksElement
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r = gf.Mul(gf.Add(r, gx), gy)
+		}
+		benchSink = r.FieldElement()
+	})
+	b.Run("big", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchSink = ref.Mul(x, y)
+		}
+	})
+}
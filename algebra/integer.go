@@ -3,6 +3,8 @@ package algebra
 import (
 	"crypto/rand"
 	"math/big"
+	"runtime"
+	"sync"
 )
 
 // Integer type wraps bigint internally represents arbitrary precision
@@ -107,6 +109,73 @@ func ModExp(a, b, m *Integer) *Integer {
 	return new(big.Int).Exp(a, b, m)
 }
 
+// modExpManyThreshold is the batch size above which ModExpMany splits the
+// exponents across goroutines.
+const modExpManyThreshold = 1024
+
+// ModExpMany computes base^e mod m for each exponent e. The squarings
+// base^(2^i) are computed once and shared by every exponent which then
+// costs one multiplication per bit set, large batches are split across
+// runtime.NumCPU() goroutines. Negative exponents are computed by ModExp.
+func ModExpMany(base *Integer, exps []*Integer, m *Integer) []*Integer {
+
+	bits := 0
+	for _, e := range exps {
+		if e.BitLen() > bits {
+			bits = e.BitLen()
+		}
+	}
+	squares := make([]*Integer, bits)
+	if bits > 0 {
+		squares[0] = new(big.Int).Mod(base, m)
+	}
+	for i := 1; i < bits; i++ {
+		squares[i] = ModMul(squares[i-1], squares[i-1], m)
+	}
+
+	res := make([]*Integer, len(exps))
+	exp := func(start, end int) {
+		// the quotient buffer is reused by every reduction
+		var q big.Int
+		for i := start; i < end; i++ {
+			e := exps[i]
+			if e.Sign() < 0 {
+				res[i] = ModExp(base, e, m)
+				continue
+			}
+			r := new(big.Int).Mod(One, m)
+			for b := 0; b < e.BitLen(); b++ {
+				if e.Bit(b) == 1 {
+					r.Mul(r, squares[b])
+					q.QuoRem(r, m, r)
+				}
+			}
+			res[i] = r
+		}
+	}
+
+	workers := runtime.NumCPU()
+	if len(exps) < modExpManyThreshold || workers < 2 {
+		exp(0, len(exps))
+		return res
+	}
+	var wg sync.WaitGroup
+	chunk := (len(exps) + workers - 1) / workers
+	for start := 0; start < len(exps); start += chunk {
+		end := start + chunk
+		if end > len(exps) {
+			end = len(exps)
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			exp(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+	return res
+}
+
 // Jacobi returns the Jacobi symbol
 // a useful tool for keeping track whether an integer is a quadratic residue
 // modulo n :
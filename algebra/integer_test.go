@@ -0,0 +1,58 @@
+package algebra
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModExpMany(t *testing.T) {
+	m := testField.Modulus()
+	base := FromInt64(5)
+
+	for _, n := range []int{0, 1, 17, 2*modExpManyThreshold + 3} {
+		exps := make([]*Integer, n)
+		for i := range exps {
+			exps[i] = FromInt64(int64(i*i*7919 + i))
+		}
+		if n > 1 {
+			exps[1] = FromInt64(-3)
+		}
+		res := ModExpMany(base, exps, m)
+		assert.Len(t, res, n)
+		for i, e := range exps {
+			assert.Equal(t, 0, res[i].Cmp(ModExp(base, e, m)), "n = %d exponent %s", n, e)
+		}
+	}
+
+	// the base doesn't need to be reduced
+	res := ModExpMany(FromInt64(-2), []*Integer{FromInt64(0), FromInt64(3)}, FromInt64(17))
+	assert.Equal(t, "1", res[0].String())
+	assert.Equal(t, "9", res[1].String())
+}
+
+func BenchmarkModExpMany(b *testing.B) {
+	// the field modulus and a 255 bits prime
+	p25519, _ := new(Integer).SetString("57896044618658097711785492504343953926634992332820282019728792003956564819949", 10)
+
+	for _, m := range []*Integer{testField.Modulus(), p25519} {
+		base := FromInt64(5)
+		exps := make([]*Integer, 1<<12)
+		for i := range exps {
+			exps[i] = ModMul(FromInt64(int64(i+1)), FromInt64(2718281828459), m)
+		}
+		b.Run(fmt.Sprintf("loop-%d-bits", m.BitLen()), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, e := range exps {
+					ModExp(base, e, m)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("batch-%d-bits", m.BitLen()), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ModExpMany(base, exps, m)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !starkdebug
+
+package algebra
+
+// debug enables the assertions checking that field elements are reduced
+// (see assertValid and NewFieldElementUnchecked), build with -tags
+// starkdebug to turn them on.
+const debug = false
@@ -0,0 +1,187 @@
+package algebra
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// The multiplicative group of Fq is cyclic of order q-1, it contains a
+// subgroup of order n for every n dividing q-1. That subgroup is generated
+// by any primitive nth root of unity w i.e w^n = 1 and w^k != 1 for k < n.
+// The subgroups of power of two order are the evaluation domains of the NTT
+// and of FRI.
+
+var (
+	errNoRootOfUnity  = errors.New("n doesn't divide the multiplicative group order q-1")
+	errFactorization  = errors.New("failed to factor the multiplicative group order q-1")
+	errGroupFactors   = errors.New("factors don't cover the multiplicative group order q-1")
+	groupOrderFactors sync.Map // modulus string -> distinct prime factors of q-1
+)
+
+// trialDivisionBound bounds the primes removed by trial division before
+// falling back to Pollard's rho.
+const trialDivisionBound = 1 << 16
+
+// FactorizeGroupOrder returns the distinct prime factors of q-1 in
+// increasing order, the order of the multiplicative group. The
+// factorization is computed once per modulus and cached, small factors are
+// found by trial division and the remaining cofactor is split with
+// PollardRho. For a large prime whose q-1 is hard to factor this may fail
+// or take very long, callers should supply the factors with
+// SetGroupOrderFactors instead.
+func (ff FiniteField) FactorizeGroupOrder() ([]*Integer, error) {
+
+	key := ff.q.String()
+	if factors, ok := groupOrderFactors.Load(key); ok {
+		return copyIntegers(factors.([]*Integer)), nil
+	}
+	factors, err := factorize(new(Integer).Sub(ff.q, One))
+	if err != nil {
+		return nil, err
+	}
+	groupOrderFactors.Store(key, factors)
+	return copyIntegers(factors), nil
+}
+
+// SetGroupOrderFactors caches the distinct prime factors of q-1 for the
+// field's modulus, the factors must be prime and q-1 must have no other
+// prime factor.
+func (ff FiniteField) SetGroupOrderFactors(factors []*Integer) error {
+
+	rest := new(Integer).Sub(ff.q, One)
+	for _, p := range factors {
+		if p == nil || !IsPrime(p) || new(Integer).Mod(rest, p).Sign() != 0 {
+			return errGroupFactors
+		}
+		for new(Integer).Mod(rest, p).Sign() == 0 {
+			rest.Div(rest, p)
+		}
+	}
+	if rest.Cmp(One) != 0 {
+		return errGroupFactors
+	}
+	sorted := copyIntegers(factors)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	groupOrderFactors.Store(ff.q.String(), sorted)
+	return nil
+}
+
+// factorize returns the distinct prime factors of n > 0 in increasing order.
+func factorize(n *Integer) ([]*Integer, error) {
+
+	var factors []*Integer
+	rest := new(Integer).Set(n)
+	for p := int64(2); p < trialDivisionBound && rest.Cmp(One) > 0; p++ {
+		d := FromInt64(p)
+		if new(Integer).Mod(rest, d).Sign() != 0 {
+			continue
+		}
+		factors = append(factors, d)
+		for new(Integer).Mod(rest, d).Sign() == 0 {
+			rest.Div(rest, d)
+		}
+	}
+
+	// the cofactor has no factor below the bound, split it until every
+	// part is prime
+	parts := []*Integer{rest}
+	seen := make(map[string]bool)
+	for len(parts) > 0 {
+		m := parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+		switch {
+		case m.Cmp(One) == 0:
+		case IsPrime(m):
+			if !seen[m.String()] {
+				seen[m.String()] = true
+				factors = append(factors, m)
+			}
+		default:
+			d := PollardRho(m)
+			if d.Sign() == 0 {
+				return nil, errFactorization
+			}
+			parts = append(parts, d, new(Integer).Div(m, d))
+		}
+	}
+	sort.Slice(factors, func(i, j int) bool { return factors[i].Cmp(factors[j]) < 0 })
+	return factors, nil
+}
+
+// copyIntegers returns a deep copy of xs.
+func copyIntegers(xs []*Integer) []*Integer {
+	c := make([]*Integer, len(xs))
+	for i, x := range xs {
+		c[i] = new(Integer).Set(x)
+	}
+	return c
+}
+
+// PowerTable returns the n first powers of fe i.e [fe^0, fe^1, ..., fe^(n-1)]
+// computed by repeated multiplication.
+func (fe FieldElement) PowerTable(n int) []FieldElement {
+	table := make([]FieldElement, n)
+	acc := fe.p.One()
+	for i := 0; i < n; i++ {
+		table[i] = acc
+		acc = fe.p.Mul(acc, fe)
+	}
+	return table
+}
+
+// RootOfUnity returns a primitive nth root of unity of the field.
+func (ff FiniteField) RootOfUnity(n uint64) (FieldElement, error) {
+
+	order := new(Integer).Sub(ff.q, One)
+	N := new(Integer).SetUint64(n)
+	if n == 0 || new(Integer).Mod(order, N).Sign() != 0 {
+		return FieldElement{}, errNoRootOfUnity
+	}
+	cofactor := Div(order, N)
+	factors := smallPrimeFactors(n)
+
+	// c^((q-1)/n) has order dividing n, it is primitive when none of the
+	// w^(n/p) for p a prime factor of n are one.
+	for c := int64(2); ; c++ {
+		w := ff.NewFieldElementFromInt64(c).Exp(cofactor)
+		primitive := true
+		for _, p := range factors {
+			if w.Exp(new(Integer).SetUint64(n / p)).Equal(ff.One()) {
+				primitive = false
+				break
+			}
+		}
+		if primitive {
+			return w, nil
+		}
+	}
+}
+
+// AllRootsOfUnity returns the n nth roots of unity [w^0, ..., w^(n-1)] for
+// a primitive nth root of unity w.
+func (ff FiniteField) AllRootsOfUnity(n uint64) ([]FieldElement, error) {
+	w, err := ff.RootOfUnity(n)
+	if err != nil {
+		return nil, err
+	}
+	return w.PowerTable(int(n)), nil
+}
+
+// smallPrimeFactors returns the distinct prime factors of n using trial
+// division.
+func smallPrimeFactors(n uint64) []uint64 {
+	var factors []uint64
+	for p := uint64(2); p*p <= n; p++ {
+		if n%p == 0 {
+			factors = append(factors, p)
+			for n%p == 0 {
+				n /= p
+			}
+		}
+	}
+	if n > 1 {
+		factors = append(factors, n)
+	}
+	return factors
+}
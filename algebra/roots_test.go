@@ -0,0 +1,66 @@
+package algebra
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllRootsOfUnity(t *testing.T) {
+	for _, n := range []uint64{1, 2, 8, 24, 1024} {
+		roots, err := testField.AllRootsOfUnity(n)
+		assert.NoError(t, err)
+		assert.Len(t, roots, int(n))
+
+		seen := make(map[string]bool)
+		for _, x := range roots {
+			assert.True(t, x.Exp(new(Integer).SetUint64(n)).Equal(testField.One()))
+			seen[x.Big().String()] = true
+		}
+		assert.Len(t, seen, int(n))
+	}
+
+	_, err := testField.AllRootsOfUnity(7)
+	assert.Error(t, err)
+	_, err = testField.AllRootsOfUnity(0)
+	assert.Error(t, err)
+}
+
+func TestFactorizeGroupOrder(t *testing.T) {
+	ints := func(xs ...int64) []*Integer {
+		res := make([]*Integer, len(xs))
+		for i, x := range xs {
+			res[i] = FromInt64(x)
+		}
+		return res
+	}
+
+	// q - 1 = 2^2.3.5.7.11
+	small, _ := NewFiniteField(FromInt64(4621))
+	factors, err := small.FactorizeGroupOrder()
+	assert.NoError(t, err)
+	assert.Equal(t, ints(2, 3, 5, 7, 11), factors)
+
+	// the cached factors aren't shared with the caller
+	factors[0].SetInt64(4)
+	factors, _ = small.FactorizeGroupOrder()
+	assert.Equal(t, ints(2, 3, 5, 7, 11), factors)
+
+	factors, err = testField.FactorizeGroupOrder()
+	assert.NoError(t, err)
+	assert.Equal(t, ints(2, 3), factors)
+
+	// q - 1 = 2.70003.70121 needs Pollard's rho
+	large, _ := NewFiniteField(FromInt64(9817360727))
+	factors, err = large.FactorizeGroupOrder()
+	assert.NoError(t, err)
+	assert.Equal(t, ints(2, 70003, 70121), factors)
+
+	supplied, _ := NewFiniteField(FromInt64(1000000007))
+	assert.Equal(t, errGroupFactors, supplied.SetGroupOrderFactors(ints(2)))
+	assert.Equal(t, errGroupFactors, supplied.SetGroupOrderFactors(ints(2, 500000003, 5)))
+	assert.NoError(t, supplied.SetGroupOrderFactors(ints(500000003, 2)))
+	factors, err = supplied.FactorizeGroupOrder()
+	assert.NoError(t, err)
+	assert.Equal(t, ints(2, 500000003), factors)
+}
@@ -0,0 +1,80 @@
+package algebra
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Folding and composition loops combine two vectors of field elements
+// point by point, AddSlices and MulSlices do it without the per element
+// allocations of Add and Mul : the results are backed by a single slice
+// of integers and the operands, being reduced, are combined without a
+// generic modular reduction. When the modulus fits in 32 bits (e.g the
+// stark field) the products fit in a uint64 and the inner loop runs on
+// machine words.
+
+// checkSliceLengths panics if the operands have different lengths.
+func checkSliceLengths(a, b []FieldElement) {
+	if len(a) != len(b) {
+		panic(fmt.Sprintf("element-wise operation on slices of lengths %d and %d", len(a), len(b)))
+	}
+}
+
+// wordModulus returns the modulus as a word and true if it fits in 32
+// bits.
+func (ff FiniteField) wordModulus() (uint64, bool) {
+	if ff.q.BitLen() > 32 {
+		return 0, false
+	}
+	return ff.q.Uint64(), true
+}
+
+// AddSlices returns the element-wise sums a[i] + b[i].
+// It panics if the slices have different lengths.
+func (ff FiniteField) AddSlices(a, b []FieldElement) []FieldElement {
+
+	checkSliceLengths(a, b)
+	ints := make([]big.Int, len(a))
+	r := make([]FieldElement, len(a))
+	if q, ok := ff.wordModulus(); ok {
+		for i := range r {
+			s := a[i].n.Uint64() + b[i].n.Uint64()
+			if s >= q {
+				s -= q
+			}
+			r[i] = FieldElement{ints[i].SetUint64(s), ff}
+		}
+		return r
+	}
+	for i := range r {
+		z := ints[i].Add(a[i].n, b[i].n)
+		if z.Cmp(ff.q) >= 0 {
+			z.Sub(z, ff.q)
+		}
+		r[i] = FieldElement{z, ff}
+	}
+	return r
+}
+
+// MulSlices returns the element-wise products a[i].b[i].
+// It panics if the slices have different lengths.
+func (ff FiniteField) MulSlices(a, b []FieldElement) []FieldElement {
+
+	checkSliceLengths(a, b)
+	ints := make([]big.Int, len(a))
+	r := make([]FieldElement, len(a))
+	if q, ok := ff.wordModulus(); ok {
+		for i := range r {
+			p := a[i].n.Uint64() * b[i].n.Uint64() % q
+			r[i] = FieldElement{ints[i].SetUint64(p), ff}
+		}
+		return r
+	}
+	var quo big.Int
+	for i := range r {
+		z := ints[i].Mul(a[i].n, b[i].n)
+		quo.QuoRem(z, ff.q, z)
+		r[i] = FieldElement{z, ff}
+	}
+	return r
+}
@@ -0,0 +1,36 @@
+package algebra
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sliceOperands returns n pairs of operands of the field covering zero and
+// the elements close to the modulus.
+func sliceOperands(ff FiniteField, n int) ([]FieldElement, []FieldElement) {
+	a, b := make([]FieldElement, n), make([]FieldElement, n)
+	x, y, _ := benchOperands(ff)
+	for i := range a {
+		a[i] = ff.Add(x, ff.NewFieldElementFromInt64(int64(i)))
+		b[i] = ff.Sub(y, ff.NewFieldElementFromInt64(int64(3*i)))
+	}
+	a[0], b[1] = ff.Zero(), ff.NewFieldElementFromInt64(-1)
+	return a, b
+}
+
+func TestSlices(t *testing.T) {
+	goldilocks, _ := NewFiniteField(new(Integer).SetUint64(18446744069414584321))
+	for _, ff := range []FiniteField{testField, goldilocks} {
+		a, b := sliceOperands(ff, 64)
+		sums, prods := ff.AddSlices(a, b), ff.MulSlices(a, b)
+		assert.Len(t, sums, len(a))
+		assert.Len(t, prods, len(a))
+		for i := range a {
+			assert.True(t, sums[i].Equal(ff.Add(a[i], b[i])), "sum %d over F%s", i, ff.Modulus())
+			assert.True(t, prods[i].Equal(ff.Mul(a[i], b[i])), "product %d over F%s", i, ff.Modulus())
+		}
+		assert.Empty(t, ff.AddSlices(nil, nil))
+		assert.Panics(t, func() { ff.MulSlices(a, b[1:]) })
+	}
+}
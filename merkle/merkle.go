@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"math"
+	"sync"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -50,6 +51,24 @@ func Proof(items [][]byte, i int) ([]AuditHash, error) {
 	return res, nil
 }
 
+// LeafHash returns the hash of a leaf i.e SHA3-256(0x00 || item).
+func LeafHash(item []byte) []byte {
+	h := sha3.New256()
+	h.Write(leafPrefix)
+	h.Write(item)
+	return h.Sum(nil)
+}
+
+// NodeHash returns the hash of an interior node given the hashes of its
+// children i.e SHA3-256(0x01 || left || right).
+func NodeHash(left, right []byte) []byte {
+	h := sha3.New256()
+	h.Write(interiorPrefix)
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
 // Root creates a merkle tree from a slice of byte slices
 // and returns the root hash of the tree.
 func Root(items [][]byte) []byte {
@@ -58,26 +77,40 @@ func Root(items [][]byte) []byte {
 		return emptyStringHash[:]
 
 	case 1:
-		h := sha3.New256()
-
-		h.Write(leafPrefix)
-		h.Write(items[0])
-		root := h.Sum(nil)
-		return root
+		return LeafHash(items[0])
 
 	default:
 		k := prevPowerOfTwo(len(items))
 		left := Root(items[:k])
 		right := Root(items[k:])
 
-		h := sha3.New256()
-		h.Write(interiorPrefix)
-		h.Write(left[:])
-		h.Write(right[:])
+		return NodeHash(left, right)
+	}
+}
+
+// parallelThreshold is the number of items under which a subtree is hashed
+// by a single goroutine, smaller subtrees aren't worth the overhead.
+const parallelThreshold = 1 << 10
 
-		root := h.Sum(nil)
-		return root
+// RootParallel returns the same root as Root, the two subtrees of a node
+// are independent so they're hashed concurrently by up to workers goroutines.
+func RootParallel(items [][]byte, workers int) []byte {
+	if workers < 2 || len(items) < parallelThreshold {
+		return Root(items)
 	}
+	k := prevPowerOfTwo(len(items))
+
+	var left []byte
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		left = RootParallel(items[:k], workers/2)
+	}()
+	right := RootParallel(items[k:], workers-workers/2)
+	wg.Wait()
+
+	return NodeHash(left, right)
 }
 
 // prevPowerOfTwo returns the largest power of two that is smaller than a given number.
@@ -160,4 +193,108 @@ func Verify(items [][]byte, index int, auditpath []AuditHash) bool {
 	}
 
 	return bytes.Equal(Root(items), h[:])
-}
\ No newline at end of file
+}
+// The binary tree above generalizes to trees of any arity a : for n > 1 items
+// the items are split in chunks of k items where k is the largest power of a
+// strictly lower than n, each chunk is the child subtree of the node.
+// For a = 2 this is the split used by Root so both roots match.
+// A higher arity reduces the depth of the tree and the length of audit
+// paths, at the cost of more sibling hashes per level.
+
+// AuditSiblings stores the hashes of the siblings of a node at one level
+// of a tree, the parent hash is H(Left || node || Right).
+type AuditSiblings struct {
+	Left  [][]byte
+	Right [][]byte
+}
+
+// prevPowerOf returns the largest power of arity strictly lower than n.
+func prevPowerOf(n, arity int) int {
+	k := 1
+	for k*arity < n {
+		k *= arity
+	}
+	return k
+}
+
+// RootArity returns the root hash of the tree of given arity over items.
+func RootArity(items [][]byte, arity int) []byte {
+	switch len(items) {
+	case 0:
+		return emptyStringHash[:]
+
+	case 1:
+		return Root(items)
+
+	default:
+		k := prevPowerOf(len(items), arity)
+
+		h := sha3.New256()
+		h.Write(interiorPrefix)
+		for start := 0; start < len(items); start += k {
+			end := start + k
+			if end > len(items) {
+				end = len(items)
+			}
+			h.Write(RootArity(items[start:end], arity))
+		}
+		return h.Sum(nil)
+	}
+}
+
+// ProofArity returns the audit path of the item at index i in the tree of
+// given arity, from the leaf level up to the root.
+func ProofArity(items [][]byte, i, arity int) ([]AuditSiblings, error) {
+	if i < 0 || i >= len(items) {
+		return nil, errors.New("index is out of bounds")
+	}
+	if arity < 2 {
+		return nil, errors.New("arity must be at least 2")
+	}
+	if len(items) == 1 {
+		return []AuditSiblings{}, nil
+	}
+
+	k := prevPowerOf(len(items), arity)
+	var level AuditSiblings
+	var child [][]byte
+	for start := 0; start < len(items); start += k {
+		end := start + k
+		if end > len(items) {
+			end = len(items)
+		}
+		switch {
+		case i >= end:
+			level.Left = append(level.Left, RootArity(items[start:end], arity))
+		case i < start:
+			level.Right = append(level.Right, RootArity(items[start:end], arity))
+		default:
+			child = items[start:end]
+		}
+	}
+	res, err := ProofArity(child, i%k, arity)
+	if err != nil {
+		return nil, err
+	}
+	return append(res, level), nil
+}
+
+// VerifyArity checks that the item hashes up to root trough the audit path.
+func VerifyArity(root []byte, item []byte, auditpath []AuditSiblings) bool {
+
+	h := hash(concat(leafPrefix, item))
+	node := h[:]
+	for _, level := range auditpath {
+		buf := append([]byte{}, interiorPrefix...)
+		for _, sibling := range level.Left {
+			buf = append(buf, sibling...)
+		}
+		buf = append(buf, node...)
+		for _, sibling := range level.Right {
+			buf = append(buf, sibling...)
+		}
+		h = hash(buf)
+		node = h[:]
+	}
+	return bytes.Equal(root, node)
+}
@@ -0,0 +1,134 @@
+package poly
+
+import (
+	"fmt"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+)
+
+// The Polynomial operations take the modulus as a separate argument, this
+// is error prone since passing the wrong modulus (or nil) silently corrupts
+// the result. FieldPolynomial carries its field so the arithmetic doesn't
+// need a modulus and operands from different fields are caught.
+
+// FieldPolynomial is a polynomial with coefficients in a finite field.
+type FieldPolynomial struct {
+	field algebra.FiniteField
+	p     Polynomial
+}
+
+// NewFieldPolynomial creates a polynomial over field given its coefficients
+// from the lowest to the highest degree.
+func NewFieldPolynomial(field algebra.FiniteField, coeffs ...algebra.FieldElement) FieldPolynomial {
+	p := make(Polynomial, len(coeffs))
+	for i, c := range coeffs {
+		if c.Field().Modulus().Cmp(field.Modulus()) != 0 {
+			panic("coefficient doesn't belong to the polynomial field")
+		}
+		p[i] = c.Big()
+	}
+	if len(p) == 0 {
+		p = NewPolynomialInts(0)
+	}
+	p.trim()
+	return FieldPolynomial{field, p}
+}
+
+// InField lifts P into field by reducing its coefficients.
+func (p Polynomial) InField(field algebra.FiniteField) FieldPolynomial {
+	q := p.Clone(0)
+	if len(q) == 0 {
+		q = NewPolynomialInts(0)
+	}
+	q.reduce(field.Modulus())
+	return FieldPolynomial{field, q}
+}
+
+// LowCoeffs returns the coefficients of degree 0 to n-1 as elements of
+// field, the coefficients beyond the degree are zero. It allows checking
+// the constant term or a small prefix of a large polynomial without
+// printing all of it. The low level polynomial doesn't hold its field so
+// it is given as for InField.
+func (p Polynomial) LowCoeffs(n int, field algebra.FiniteField) []algebra.FieldElement {
+	coeffs := make([]algebra.FieldElement, n)
+	for i := range coeffs {
+		if i < len(p) {
+			coeffs[i] = field.NewFieldElement(p[i])
+		} else {
+			coeffs[i] = field.Zero()
+		}
+	}
+	return coeffs
+}
+
+// Field returns the field of the coefficients.
+func (fp FieldPolynomial) Field() algebra.FiniteField {
+	return fp.field
+}
+
+// Polynomial returns a copy of the underlying low level polynomial.
+func (fp FieldPolynomial) Polynomial() Polynomial {
+	return fp.p.Clone(0)
+}
+
+// Degree returns the degree of the polynomial.
+func (fp FieldPolynomial) Degree() int {
+	return fp.p.Degree()
+}
+
+// Coeffs returns the coefficients from the lowest to the highest degree.
+func (fp FieldPolynomial) Coeffs() []algebra.FieldElement {
+	return fp.LowCoeffs(len(fp.p))
+}
+
+// LowCoeffs returns the coefficients of degree 0 to n-1, see
+// Polynomial.LowCoeffs.
+func (fp FieldPolynomial) LowCoeffs(n int) []algebra.FieldElement {
+	return fp.p.LowCoeffs(n, fp.field)
+}
+
+// String implements the printing interface
+func (fp FieldPolynomial) String() string {
+	return fmt.Sprintf("%s(F/%d)", fp.p.String(), fp.field.Modulus())
+}
+
+// checkField panics when the operands are defined over different fields.
+func (fp FieldPolynomial) checkField(other FieldPolynomial) {
+	if fp.field.Modulus().Cmp(other.field.Modulus()) != 0 {
+		panic(fmt.Sprintf("polynomials over different fields F/%d and F/%d", fp.field.Modulus(), other.field.Modulus()))
+	}
+}
+
+// Add returns P + Q.
+func (fp FieldPolynomial) Add(q FieldPolynomial) FieldPolynomial {
+	fp.checkField(q)
+	return FieldPolynomial{fp.field, fp.p.Add(q.p, fp.field.Modulus())}
+}
+
+// Sub returns P - Q.
+func (fp FieldPolynomial) Sub(q FieldPolynomial) FieldPolynomial {
+	fp.checkField(q)
+	return FieldPolynomial{fp.field, fp.p.Sub(q.p, fp.field.Modulus())}
+}
+
+// Mul returns P * Q.
+func (fp FieldPolynomial) Mul(q FieldPolynomial) FieldPolynomial {
+	fp.checkField(q)
+	return FieldPolynomial{fp.field, fp.p.Clone(0).Mul(q.p.Clone(0), fp.field.Modulus())}
+}
+
+// Eval returns P(x).
+func (fp FieldPolynomial) Eval(x algebra.FieldElement) algebra.FieldElement {
+	if x.Field().Modulus().Cmp(fp.field.Modulus()) != 0 {
+		panic("evaluation point doesn't belong to the polynomial field")
+	}
+	return fp.field.NewFieldElement(fp.p.Eval(x.Big(), fp.field.Modulus()))
+}
+
+// Equal checks whether P and Q are the same polynomial over the same field.
+func (fp FieldPolynomial) Equal(q FieldPolynomial) bool {
+	if fp.field.Modulus().Cmp(q.field.Modulus()) != 0 {
+		return false
+	}
+	return fp.p.Compare(&q.p) == 0
+}
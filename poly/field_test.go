@@ -0,0 +1,50 @@
+package poly
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldPolynomial(t *testing.T) {
+	m := testField.Modulus()
+	p := NewPolynomialInts(1, 2, 3)
+	q := NewPolynomialInts(-5, 0, 0, 7)
+
+	fp, fq := p.InField(testField), q.InField(testField)
+	assert.True(t, fp.Add(fq).Equal(p.Add(q, m).InField(testField)))
+	assert.True(t, fp.Sub(fq).Equal(p.Sub(q, m).InField(testField)))
+	assert.True(t, fp.Mul(fq).Equal(p.Mul(q, m).InField(testField)))
+
+	x := testField.NewFieldElementFromInt64(31415)
+	assert.Equal(t, 0, fq.Eval(x).Big().Cmp(q.Eval(x.Big(), m)))
+
+	other, _ := algebra.NewFiniteField(algebra.FromInt64(17))
+	fo := p.InField(other)
+	assert.Panics(t, func() { fp.Add(fo) })
+	assert.Panics(t, func() { fp.Mul(fo) })
+	assert.Panics(t, func() { fp.Eval(other.One()) })
+	assert.False(t, fp.Equal(fo))
+}
+
+func TestLowCoeffs(t *testing.T) {
+	p := NewPolynomialInts(-1, 4, 0, 9)
+
+	low := p.LowCoeffs(3, testField)
+	assert.Len(t, low, 3)
+	for i, c := range []int64{-1, 4, 0} {
+		assert.True(t, low[i].Equal(testField.NewFieldElementFromInt64(c)), "coefficient %d", i)
+	}
+
+	padded := NewPolynomialInts(7).LowCoeffs(3, testField)
+	assert.True(t, padded[0].Equal(testField.NewFieldElementFromInt64(7)))
+	assert.True(t, padded[1].IsZero())
+	assert.True(t, padded[2].IsZero())
+	assert.Empty(t, p.LowCoeffs(0, testField))
+
+	// the field polynomial forwards to the low level one
+	fp := p.InField(testField)
+	assert.Equal(t, low, fp.LowCoeffs(3))
+	assert.Len(t, fp.Coeffs(), 4)
+}
@@ -0,0 +1,168 @@
+package poly
+
+import (
+	"errors"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+)
+
+// The number theoretic transform (NTT) is the discrete fourier transform over
+// a finite field. Given the coefficients of a polynomial of degree < n and a
+// primitive nth root of unity w it computes the evaluations p(w^i) for
+// 0 <= i < n in O(n log n) instead of O(n^2) for the naive evaluation.
+// The inverse transform interpolates the coefficients back from the
+// evaluations.
+// Both transforms only depend on the domain trough the bit-reversal
+// permutation and the powers of w (the twiddle factors), an NTTPlan
+// computes them once so they can be reused across transforms.
+
+var (
+	errNTTSize      = errors.New("ntt size must be a power of two")
+	errNTTRoot      = errors.New("root is not a primitive nth root of unity")
+	errNTTInputSize = errors.New("ntt input is larger than the plan size")
+	errNTTModulus   = errors.New("modulus doesn't match the field of the root")
+)
+
+// NTTPlan holds the precomputed data of a radix-2 NTT of size n.
+type NTTPlan struct {
+	n        uint64
+	field    algebra.FiniteField
+	rev      []uint64
+	twiddles []algebra.FieldElement
+	inverses []algebra.FieldElement
+	nInv     algebra.FieldElement
+}
+
+// NewNTTPlan creates a plan for transforms of size n over the subgroup
+// generated by root, root must be a primitive nth root of unity.
+func NewNTTPlan(root algebra.FieldElement, n uint64) (*NTTPlan, error) {
+
+	if n == 0 || n&(n-1) != 0 {
+		return nil, errNTTSize
+	}
+	field := root.Field()
+	if !root.Exp(new(algebra.Integer).SetUint64(n)).Equal(field.One()) {
+		return nil, errNTTRoot
+	}
+	if n > 1 && root.Exp(new(algebra.Integer).SetUint64(n/2)).Equal(field.One()) {
+		return nil, errNTTRoot
+	}
+
+	logN := 0
+	for (uint64(1) << logN) < n {
+		logN++
+	}
+	rev := make([]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		rev[i] = reverseBits(i, logN)
+	}
+
+	rootInv := root.Inv()
+	twiddles := make([]algebra.FieldElement, n/2)
+	inverses := make([]algebra.FieldElement, n/2)
+	w, wInv := field.One(), field.One()
+	for i := range twiddles {
+		twiddles[i] = w
+		inverses[i] = wInv
+		w = field.Mul(w, root)
+		wInv = field.Mul(wInv, rootInv)
+	}
+
+	return &NTTPlan{
+		n:        n,
+		field:    field,
+		rev:      rev,
+		twiddles: twiddles,
+		inverses: inverses,
+		nInv:     field.NewFieldElement(new(algebra.Integer).SetUint64(n)).Inv(),
+	}, nil
+}
+
+// Size returns the size of the transforms computed by the plan.
+func (plan *NTTPlan) Size() uint64 {
+	return plan.n
+}
+
+// Forward evaluates the polynomial with the given coefficients over the
+// subgroup, coefficients are zero padded to the plan size.
+func (plan *NTTPlan) Forward(coeffs []algebra.FieldElement) ([]algebra.FieldElement, error) {
+	return plan.transform(coeffs, plan.twiddles)
+}
+
+// Inverse interpolates the coefficients of the polynomial given its
+// evaluations over the subgroup.
+func (plan *NTTPlan) Inverse(evals []algebra.FieldElement) ([]algebra.FieldElement, error) {
+	coeffs, err := plan.transform(evals, plan.inverses)
+	if err != nil {
+		return nil, err
+	}
+	for i := range coeffs {
+		coeffs[i] = plan.field.Mul(coeffs[i], plan.nInv)
+	}
+	return coeffs, nil
+}
+
+// transform runs the iterative Cooley-Tukey butterfly over the bit-reversed
+// input using the given twiddle factors.
+func (plan *NTTPlan) transform(input []algebra.FieldElement, twiddles []algebra.FieldElement) ([]algebra.FieldElement, error) {
+
+	if uint64(len(input)) > plan.n {
+		return nil, errNTTInputSize
+	}
+	field := plan.field
+	a := make([]algebra.FieldElement, plan.n)
+	for i := uint64(0); i < plan.n; i++ {
+		if plan.rev[i] < uint64(len(input)) {
+			a[i] = input[plan.rev[i]]
+		} else {
+			a[i] = field.Zero()
+		}
+	}
+
+	for size := uint64(2); size <= plan.n; size <<= 1 {
+		half := size / 2
+		stride := plan.n / size
+		for start := uint64(0); start < plan.n; start += size {
+			for j := uint64(0); j < half; j++ {
+				t := field.Mul(twiddles[j*stride], a[start+j+half])
+				u := a[start+j]
+				a[start+j] = field.Add(u, t)
+				a[start+j+half] = field.Sub(u, t)
+			}
+		}
+	}
+	return a, nil
+}
+
+// EvalCosetNTT evaluates P over the coset offset.<root> of size n i.e it
+// returns p(offset.root^i) for 0 <= i < n, the low degree extension of the
+// prover. Since p(offset.x) = Sum(c_i.offset^i.x^i) the coefficients are
+// scaled by the powers of offset before a regular NTT, P must have degree
+// less than n.
+func (p Polynomial) EvalCosetNTT(offset, root algebra.FieldElement, n uint64, modulus *algebra.Integer) ([]algebra.FieldElement, error) {
+
+	field := root.Field()
+	if modulus == nil || field.Modulus().Cmp(modulus) != 0 || offset.Field().Modulus().Cmp(modulus) != 0 {
+		return nil, errNTTModulus
+	}
+	plan, err := NewNTTPlan(root, n)
+	if err != nil {
+		return nil, err
+	}
+	scaled := p.Scale(offset.Big(), modulus)
+	coeffs := make([]algebra.FieldElement, len(scaled))
+	for i, c := range scaled {
+		coeffs[i] = field.NewFieldElement(c)
+	}
+	return plan.Forward(coeffs)
+}
+
+// reverseBits reverses the lowest bits of x.
+func reverseBits(x uint64, bits int) uint64 {
+	var r uint64
+	for i := 0; i < bits; i++ {
+		r = (r << 1) | (x & 1)
+		x >>= 1
+	}
+	return r
+}
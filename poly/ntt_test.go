@@ -0,0 +1,110 @@
+package poly
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/stretchr/testify/assert"
+)
+
+var testField, _ = algebra.NewFiniteField(new(algebra.Integer).SetUint64(3221225473))
+
+// rootOfUnity returns a primitive nth root of unity of the test field
+// using its generator 5.
+func rootOfUnity(n uint64) algebra.FieldElement {
+	q := new(algebra.Integer).Sub(testField.Modulus(), algebra.One)
+	return testField.NewFieldElementFromInt64(5).Exp(q.Div(q, new(algebra.Integer).SetUint64(n)))
+}
+
+func TestNTTPlan(t *testing.T) {
+	const n = 16
+	root := rootOfUnity(n)
+	plan, err := NewNTTPlan(root, n)
+	assert.NoError(t, err)
+
+	coeffs := make([]algebra.FieldElement, 11)
+	for i := range coeffs {
+		coeffs[i] = testField.NewFieldElementFromInt64(int64(i*i + 3))
+	}
+	p := NewPolynomial(coeffs)
+
+	evals, err := plan.Forward(coeffs)
+	assert.NoError(t, err)
+	for i, eval := range evals {
+		x := root.Exp(algebra.FromInt64(int64(i)))
+		assert.Equal(t, 0, eval.Big().Cmp(p.Eval(x.Big(), testField.Modulus())))
+	}
+
+	back, err := plan.Inverse(evals)
+	assert.NoError(t, err)
+	for i := range back {
+		if i < len(coeffs) {
+			assert.True(t, back[i].Equal(coeffs[i]))
+		} else {
+			assert.True(t, back[i].IsZero())
+		}
+	}
+
+	_, err = NewNTTPlan(root, 12)
+	assert.Error(t, err)
+	_, err = NewNTTPlan(root.Square(), n)
+	assert.Error(t, err)
+	_, err = plan.Forward(make([]algebra.FieldElement, n+1))
+	assert.Error(t, err)
+}
+
+func TestEvalCosetNTT(t *testing.T) {
+	const n = 32
+	m := testField.Modulus()
+	root := rootOfUnity(n)
+	offset := testField.NewFieldElementFromInt64(5)
+	p := NewPolynomialInts(3, -1, 4, 1, -5, 9, 2, 6, 5, 3, 5, 8, 9, 7)
+
+	evals, err := p.EvalCosetNTT(offset, root, n, m)
+	assert.NoError(t, err)
+	assert.Len(t, evals, n)
+	for i, eval := range evals {
+		x := testField.Mul(offset, root.Exp(algebra.FromInt64(int64(i))))
+		assert.True(t, eval.Equal(p.EvalAt(x)), "i = %d", i)
+	}
+
+	_, err = p.EvalCosetNTT(offset, root, n, algebra.FromInt64(17))
+	assert.Equal(t, errNTTModulus, err)
+	_, err = p.EvalCosetNTT(offset, root.Square(), 8, m)
+	assert.Error(t, err)
+}
+
+func benchmarkInput(n uint64) []algebra.FieldElement {
+	coeffs := make([]algebra.FieldElement, n)
+	for i := range coeffs {
+		coeffs[i] = testField.NewFieldElementFromInt64(int64(i + 1))
+	}
+	return coeffs
+}
+
+func BenchmarkNTTCachedPlan(b *testing.B) {
+	const n = 1 << 10
+	coeffs := benchmarkInput(n)
+	plan, err := NewNTTPlan(rootOfUnity(n), n)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		plan.Forward(coeffs)
+	}
+}
+
+func BenchmarkNTTFreshPlan(b *testing.B) {
+	const n = 1 << 10
+	coeffs := benchmarkInput(n)
+	root := rootOfUnity(n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		plan, err := NewNTTPlan(root, n)
+		if err != nil {
+			b.Fatal(err)
+		}
+		plan.Forward(coeffs)
+	}
+}
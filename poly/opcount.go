@@ -0,0 +1,17 @@
+package poly
+
+import "sync/atomic"
+
+// mulCount counts the coefficient multiplications of the polynomial
+// arithmetic and evaluations, each call adds its whole count at once so
+// the hot loops aren't slowed down.
+var mulCount atomic.Uint64
+
+// MulCount returns the number of modular multiplications of coefficients
+// performed by Mul, Div, Scale and the evaluations since the program
+// started, the difference between two calls measures the multiplications
+// of an operation e.g to calibrate cost estimates. Concurrent operations
+// are counted as well.
+func MulCount() uint64 {
+	return mulCount.Load()
+}
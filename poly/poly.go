@@ -4,6 +4,7 @@
 package poly
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"math/rand"
@@ -14,6 +15,8 @@ import (
 
 // Polynomial implements the polynomial type
 // using a vector of integers ordered by decreasing order i.e (lowest degree -> highest degree)
+// This is the low level representation, its arithmetic takes the modulus as
+// an explicit argument, see FieldPolynomial for field aware arithmetic.
 type Polynomial []*algebra.Integer
 
 // NewPolynomialInts Helper function for generating a polynomial with given integers
@@ -63,6 +66,9 @@ func RandomPolynomial(degree, bits int64) (p Polynomial) {
 
 // trim slices the underlying vector to remove zero coefficients of higher degree
 func (p *Polynomial) trim() {
+	if len(*p) == 0 {
+		return
+	}
 	var last int = 0
 	for i := p.Degree(); i > 0; i-- { // why i > 0, not i >=0? do not remove the constant
 		if (*p)[i].Sign() != 0 {
@@ -73,12 +79,38 @@ func (p *Polynomial) trim() {
 	*p = (*p)[:(last + 1)]
 }
 
-// isZero() checks if P is the zero polynomial
-func (p *Polynomial) isZero() bool {
-	if p.Degree() == 0 && (*p)[0].Cmp(big.NewInt(0)) == 0 {
+// Trim returns P without its zero coefficients of higher degree backed by
+// a slice of exactly Degree()+1 elements, so that the spare capacity left
+// by arithmetic operations can be released.
+func (p Polynomial) Trim() Polynomial {
+	if len(p) == 0 {
+		return Polynomial{}
+	}
+	q := p[:len(p):len(p)]
+	q.trim()
+	r := make(Polynomial, len(q))
+	copy(r, q)
+	return r
+}
+
+// IsZero checks if P is the zero polynomial i.e all its coefficients are
+// zero, the empty polynomial is also zero.
+func (p Polynomial) IsZero() bool {
+	for _, c := range p {
+		if c.Sign() != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsConstant checks if P is a constant polynomial i.e all the coefficients
+// of degree >= 1 are zero, the zero polynomial is constant.
+func (p Polynomial) IsConstant() bool {
+	if len(p) <= 1 {
 		return true
 	}
-	return false
+	return p[1:].IsZero()
 }
 
 // Degree returns the degree of the polynomial
@@ -169,6 +201,41 @@ func (p Polynomial) Add(q Polynomial, m *algebra.Integer) Polynomial {
 	return r
 }
 
+// AddInPlace computes P = P + Q, it mutates P : the coefficients of P are
+// updated in place and its slice only grows when Q has a higher degree, so
+// accumulating many polynomials e.g in the composition loop doesn't
+// allocate a new polynomial per term as Add does. The coefficients of P
+// mustn't be shared with another polynomial, those of Q are left unchanged.
+func (p *Polynomial) AddInPlace(q Polynomial, m *algebra.Integer) {
+	n := len(*p)
+	if len(q) > n {
+		if len(q) > cap(*p) {
+			grown := make(Polynomial, n, len(q))
+			copy(grown, *p)
+			*p = grown
+		}
+		*p = (*p)[:len(q)]
+		for i := n; i < len(q); i++ {
+			(*p)[i] = new(big.Int)
+		}
+	}
+	for i := range q {
+		(*p)[i].Add((*p)[i], q[i])
+	}
+	if m != nil {
+		for _, c := range *p {
+			// reduced operands sum below 2m, a subtraction spares the division
+			if c.Cmp(m) >= 0 {
+				c.Sub(c, m)
+			}
+			if c.Sign() < 0 || c.Cmp(m) >= 0 {
+				c.Mod(c, m)
+			}
+		}
+	}
+	p.trim()
+}
+
 // Neg returns a polynomial Q = -P
 func (p Polynomial) Neg() Polynomial {
 	var q Polynomial = make([]*algebra.Integer, len(p))
@@ -199,6 +266,94 @@ func (p Polynomial) Clone(adjust int) Polynomial {
 	return q
 }
 
+// MulXPow returns P * x^k by shifting the coefficients up, it panics if
+// k is negative.
+func (p Polynomial) MulXPow(k int) Polynomial {
+	if k < 0 {
+		panic("negative power of x")
+	}
+	return p.Clone(k)
+}
+
+var (
+	errNegativeXPow = errors.New("negative power of x")
+	errNotDivisible = errors.New("polynomial isn't divisible by the power of x")
+)
+
+// DivXPow returns P / x^k by shifting the coefficients down, the k lowest
+// coefficients must be zero.
+func (p Polynomial) DivXPow(k int) (Polynomial, error) {
+	if k < 0 {
+		return nil, errNegativeXPow
+	}
+	for i := 0; i < k && i < len(p); i++ {
+		if p[i].Sign() != 0 {
+			return nil, errNotDivisible
+		}
+	}
+	if k >= len(p) {
+		return NewPolynomialInts(0), nil
+	}
+	q := make(Polynomial, len(p)-k)
+	for i := range q {
+		q[i] = new(big.Int).Set(p[i+k])
+	}
+	return q, nil
+}
+
+// ModXk returns P mod x^k i.e the k lowest coefficients of P, it panics
+// if k is negative. Power series arithmetic e.g the Newton iteration of
+// Reverse works modulo x^k, see MulModXk.
+func (p Polynomial) ModXk(k int) Polynomial {
+	if k < 0 {
+		panic("negative power of x")
+	}
+	if k > len(p) {
+		k = len(p)
+	}
+	if k == 0 {
+		return NewPolynomialInts(0)
+	}
+	q := make(Polynomial, k)
+	for i := range q {
+		q[i] = new(big.Int).Set(p[i])
+	}
+	q.trim()
+	return q
+}
+
+// MulModXk computes P * Q mod x^k without computing the products of
+// degree k or higher, it panics if k is negative.
+func (p Polynomial) MulModXk(q Polynomial, k int, m *algebra.Integer) Polynomial {
+	if k < 0 {
+		panic("negative power of x")
+	}
+	n := len(p) + len(q) - 1
+	if n > k {
+		n = k
+	}
+	if n <= 0 {
+		return NewPolynomialInts(0)
+	}
+	var r Polynomial = make([]*algebra.Integer, n)
+	for i := range r {
+		r[i] = big.NewInt(0)
+	}
+	var count uint64
+	for i := 0; i < len(p) && i < n; i++ {
+		for j := 0; j < len(q) && i+j < n; j++ {
+			r[i+j].Add(r[i+j], new(big.Int).Mul(p[i], q[j]))
+			if m != nil {
+				r[i+j].Mod(r[i+j], m)
+			}
+			count++
+		}
+	}
+	mulCount.Add(count)
+	r.trim()
+	return r
+}
+
 // reduce does modular arithmetic over modulus m
 func (p *Polynomial) reduce(m *algebra.Integer) {
 	if m == nil {
@@ -226,6 +381,7 @@ func (p Polynomial) Mul(q Polynomial, m *algebra.Integer) Polynomial {
 	for i := 0; i < len(r); i++ {
 		r[i] = big.NewInt(0)
 	}
+	mulCount.Add(uint64(len(p) * len(q)))
 	for i := 0; i < len(p); i++ {
 		for j := 0; j < len(q); j++ {
 			a := new(big.Int)
@@ -247,7 +403,7 @@ func (p Polynomial) Div(q Polynomial, m *algebra.Integer) (quo, rem Polynomial)
 		p.reduce(m)
 		q.reduce(m)
 	}
-	if p.Degree() < q.Degree() || q.isZero() {
+	if p.Degree() < q.Degree() || q.IsZero() {
 		quo = NewPolynomialInts(0)
 		rem = p.Clone(0)
 		return
@@ -262,7 +418,7 @@ func (p Polynomial) Div(q Polynomial, m *algebra.Integer) (quo, rem Polynomial)
 	for {
 		td := t.Degree()
 		rd := td - qd
-		if rd < 0 || t.isZero() {
+		if rd < 0 || t.IsZero() {
 			rem = t
 			break
 		}
@@ -282,6 +438,7 @@ func (p Polynomial) Div(q Polynomial, m *algebra.Integer) (quo, rem Polynomial)
 			return
 		}
 		u := q.Clone(rd)
+		mulCount.Add(uint64(len(u) - rd))
 		for i := rd; i < len(u); i++ {
 			u[i].Mul(u[i], r)
 			if m != nil {
@@ -302,7 +459,7 @@ func (p Polynomial) GCD(q Polynomial, m *algebra.Integer) Polynomial {
 	if p.Compare(&q) < 0 {
 		return q.GCD(p, m)
 	}
-	if q.isZero() {
+	if q.IsZero() {
 		return p
 	}
 	_, rem := p.Div(q, m)
@@ -324,14 +481,19 @@ func (p Polynomial) Quo(q Polynomial, m *algebra.Integer) Polynomial {
 	return quo
 }
 
-// Eval returns p(v) where v is the given big integer
+// Eval returns p(v) where v is the given big integer, nil coefficients
+// (e.g left by a faulty decoder) are treated as zero see EvalChecked to
+// report them instead.
 func (p Polynomial) Eval(x *algebra.Integer, m *algebra.Integer) (y *algebra.Integer) {
 	y = big.NewInt(0)
 	accx := big.NewInt(1)
 	xd := new(big.Int)
+	mulCount.Add(uint64(2 * len(p)))
 	for i := 0; i <= p.Degree(); i++ {
-		xd.Mul(accx, p[i])
-		y.Add(y, xd)
+		if p[i] != nil {
+			xd.Mul(accx, p[i])
+			y.Add(y, xd)
+		}
 		accx.Mul(accx, x)
 		if m != nil {
 			y.Mod(y, m)
@@ -341,6 +503,56 @@ func (p Polynomial) Eval(x *algebra.Integer, m *algebra.Integer) (y *algebra.Int
 	return y
 }
 
+// EvalChecked returns p(v) like Eval but reports the index of the first
+// nil coefficient as an error.
+func (p Polynomial) EvalChecked(x *algebra.Integer, m *algebra.Integer) (*algebra.Integer, error) {
+	for i, c := range p {
+		if c == nil {
+			return nil, fmt.Errorf("polynomial coefficient %d is nil", i)
+		}
+	}
+	return p.Eval(x, m), nil
+}
+
+// EvalAt returns p(x) in the field of x, the coefficients are reduced
+// modulo the field modulus.
+func (p Polynomial) EvalAt(x algebra.FieldElement) algebra.FieldElement {
+	field := x.Field()
+	m, v := field.Modulus(), x.Big()
+	// Horner's rule with a single accumulator
+	y := new(big.Int)
+	mulCount.Add(uint64(len(p)))
+	for i := p.Degree(); i >= 0; i-- {
+		y.Mul(y, v)
+		y.Add(y, p[i])
+		y.Mod(y, m)
+	}
+	return field.NewFieldElement(y)
+}
+
+// EvalAtArena returns p(x) like EvalAt but the intermediate values are
+// allocated from the arena, the result is backed by the arena as well.
+func (p Polynomial) EvalAtArena(x algebra.FieldElement, a *algebra.FieldArena) algebra.FieldElement {
+	y := a.Field().Zero()
+	mulCount.Add(uint64(len(p)))
+	for i := p.Degree(); i >= 0; i-- {
+		y = a.MulAdd(y, x, p[i])
+	}
+	return y
+}
+
+// VanishesOn checks that p is zero at every point modulo m, otherwise it
+// returns false and the index of the first point where p doesn't vanish.
+// The index is -1 when p vanishes on all the points.
+func (p Polynomial) VanishesOn(points []algebra.FieldElement, m *algebra.Integer) (bool, int) {
+	for i, x := range points {
+		if p.Eval(x.Big(), m).Sign() != 0 {
+			return false, i
+		}
+	}
+	return true, -1
+}
+
 // Compose returns p(q(x))
 func (p Polynomial) Compose(q Polynomial, m *algebra.Integer) Polynomial {
 
@@ -354,9 +566,36 @@ func (p Polynomial) Compose(q Polynomial, m *algebra.Integer) Polynomial {
 	return r
 }
 
-// Reverse the order of the polynomial coefficients
+// Scale returns p(c.x) by multiplying the coefficient of degree i by c^i
+// this is cheaper than composing p with the monomial c.x.
+func (p Polynomial) Scale(c *algebra.Integer, m *algebra.Integer) Polynomial {
+	r := make(Polynomial, len(p))
+	acc := big.NewInt(1)
+	mulCount.Add(uint64(2 * len(p)))
+	for i := 0; i < len(p); i++ {
+		r[i] = new(big.Int).Mul(p[i], acc)
+		acc.Mul(acc, c)
+		if m != nil {
+			r[i].Mod(r[i], m)
+			acc.Mod(acc, m)
+		}
+	}
+	r.trim()
+	return r
+}
+
+// Reverse returns the reciprocal polynomial x^d.p(1/x) where d is the
+// degree of p i.e the coefficients of the trimmed p in reverse order, the
+// zero coefficients of higher degree are dropped first so they don't turn
+// into a factor x^k. p.Reverse().Reverse() is the trimmed p when p(0) != 0,
+// otherwise the lowest power of x dividing p is lost.
+// Reciprocal polynomials turn divisions into power series inversions : for
+// a = b.q + r with deg(a) = n and deg(b) = m, Reverse(a) = Reverse(b).Reverse(q)
+// mod x^(n-m+1) so q is recovered from the inverse of Reverse(b) modulo
+// x^(n-m+1), computed by Newton iteration g <- g.(2 - Reverse(b).g) which
+// doubles the precision of g at each step starting from g = 1/b_m.
 func (p Polynomial) Reverse() Polynomial {
-	a := p.Clone(0)
+	a := p.Trim()
 	for left, right := 0, len(a)-1; left < right; left, right = left+1, right-1 {
 		a[left], a[right] = a[right], a[left]
 	}
@@ -0,0 +1,280 @@
+package poly
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsZeroIsConstant(t *testing.T) {
+	zero := NewPolynomialInts(0)
+	assert.True(t, zero.IsZero())
+	assert.True(t, zero.IsConstant())
+	assert.True(t, Polynomial{}.IsZero())
+
+	constant := NewPolynomialInts(42)
+	assert.False(t, constant.IsZero())
+	assert.True(t, constant.IsConstant())
+
+	linear := NewPolynomialInts(1, 2)
+	assert.False(t, linear.IsZero())
+	assert.False(t, linear.IsConstant())
+}
+
+func TestScale(t *testing.T) {
+	m := testField.Modulus()
+	p := NewPolynomialInts(3, 0, 7, 1)
+	c := algebra.FromInt64(12345)
+	composed := p.Compose(NewPolynomialBigInt(algebra.FromInt64(0), c), m)
+	scaled := p.Scale(c, m)
+	assert.Equal(t, 0, scaled.Compare(&composed))
+}
+
+func TestTrim(t *testing.T) {
+	p := make(Polynomial, 3, 16)
+	p[0], p[1], p[2] = algebra.FromInt64(1), algebra.FromInt64(2), algebra.FromInt64(0)
+
+	trimmed := p.Trim()
+	assert.Equal(t, 1, trimmed.Degree())
+	assert.Equal(t, len(trimmed), cap(trimmed))
+	assert.Equal(t, "[2x + 1]", trimmed.String())
+	assert.Equal(t, 0, trimmed.Eval(algebra.FromInt64(7), nil).Cmp(p.Eval(algebra.FromInt64(7), nil)))
+	assert.Len(t, p, 3)
+
+	assert.True(t, Polynomial{}.Trim().IsZero())
+}
+
+func TestAddInPlace(t *testing.T) {
+	m := testField.Modulus()
+	q := NewPolynomialInts(3221225470, 5, 0, 9)
+	r := NewPolynomialInts(1, 2)
+
+	p := NewPolynomialInts(4)
+	p.AddInPlace(q, m)
+	expected := NewPolynomialInts(4).Add(q, m)
+	assert.Equal(t, 0, p.Compare(&expected))
+	assert.Equal(t, "[9x^3 + 5x + 1]", p.String())
+
+	// Q is left unchanged and doesn't share its coefficients with P
+	p.AddInPlace(r, m)
+	expected = expected.Add(r, m)
+	assert.Equal(t, 0, p.Compare(&expected))
+	assert.Equal(t, "[9x^3 + 7x + 2]", p.String())
+	assert.Equal(t, "[9x^3 + 5x + 3221225470]", q.String())
+
+	// the leading terms cancel
+	p.AddInPlace(NewPolynomialInts(0, 0, 0, -9), nil)
+	assert.Equal(t, 1, p.Degree())
+}
+
+func TestEvalAt(t *testing.T) {
+	m := testField.Modulus()
+	p := NewPolynomialInts(-4, 17, 0, 3221225480, 9)
+
+	for _, v := range []int64{0, 1, 2718, 3221225472} {
+		x := testField.NewFieldElementFromInt64(v)
+		y := p.EvalAt(x)
+		assert.Equal(t, 0, y.Big().Cmp(p.Eval(x.Big(), m)), "x = %d", v)
+	}
+	assert.True(t, Polynomial{}.EvalAt(testField.One()).IsZero())
+}
+
+func TestEvalAtArena(t *testing.T) {
+	arena := algebra.NewFieldArena(testField)
+	p := NewPolynomialInts(-4, 17, 0, 3221225480, 9)
+
+	for _, v := range []int64{0, 1, 2718, 3221225472} {
+		x := testField.NewFieldElementFromInt64(v)
+		assert.True(t, p.EvalAtArena(x, arena).Equal(p.EvalAt(x)), "x = %d", v)
+		arena.Reset()
+	}
+}
+
+func BenchmarkEvalAt(b *testing.B) {
+	coeffs := make([]int, 1024)
+	for i := range coeffs {
+		coeffs[i] = i*i + 3
+	}
+	p := NewPolynomialInts(coeffs...)
+	x := testField.NewFieldElementFromInt64(31415)
+
+	b.Run("field", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p.EvalAt(x)
+		}
+	})
+	b.Run("arena", func(b *testing.B) {
+		b.ReportAllocs()
+		arena := algebra.NewFieldArena(testField)
+		for i := 0; i < b.N; i++ {
+			p.EvalAtArena(x, arena)
+			arena.Reset()
+		}
+	})
+}
+
+// BenchmarkAddInPlace accumulates 64 polynomials of degree 1023 as the
+// composition loop does.
+func BenchmarkAddInPlace(b *testing.B) {
+	m := testField.Modulus()
+	terms := make([]Polynomial, 64)
+	for i := range terms {
+		terms[i] = RandomPolynomial(1023, 31)
+	}
+
+	b.Run("Add", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			acc := NewPolynomialInts(0)
+			for _, t := range terms {
+				acc = acc.Add(t, m)
+			}
+		}
+	})
+	b.Run("AddInPlace", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			acc := NewPolynomialInts(0)
+			for _, t := range terms {
+				acc.AddInPlace(t, m)
+			}
+		}
+	})
+}
+
+func TestVanishesOn(t *testing.T) {
+	const n, skip = 8, 5
+	m := testField.Modulus()
+	w := rootOfUnity(n)
+
+	subgroup := make([]algebra.FieldElement, n)
+	p := NewPolynomialInts(1)
+	for i := range subgroup {
+		subgroup[i] = w.Exp(algebra.FromInt64(int64(i)))
+		if i != skip {
+			p = p.Mul(NewPolynomialBigInt(new(algebra.Integer).Neg(subgroup[i].Big()), algebra.FromInt64(1)), m)
+		}
+	}
+
+	ok, index := p.VanishesOn(subgroup, m)
+	assert.False(t, ok)
+	assert.Equal(t, skip, index)
+
+	ok, index = p.VanishesOn(append(subgroup[:skip:skip], subgroup[skip+1:]...), m)
+	assert.True(t, ok)
+	assert.Equal(t, -1, index)
+}
+
+func TestMulDivXPow(t *testing.T) {
+	m := testField.Modulus()
+	p := NewPolynomialInts(3, 0, 7, 1)
+
+	shifted := p.MulXPow(3)
+	monomial := NewPolynomialInts(0, 0, 0, 1)
+	expected := p.Mul(monomial, m)
+	assert.Equal(t, 0, shifted.Compare(&expected))
+	assert.Equal(t, 6, shifted.Degree())
+	assert.Panics(t, func() { p.MulXPow(-1) })
+
+	back, err := shifted.DivXPow(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, back.Compare(&p))
+
+	_, err = shifted.DivXPow(4)
+	assert.Equal(t, errNotDivisible, err)
+	_, err = p.DivXPow(-1)
+	assert.Equal(t, errNegativeXPow, err)
+
+	zero, err := NewPolynomialInts(0, 0).DivXPow(5)
+	assert.NoError(t, err)
+	assert.True(t, zero.IsZero())
+}
+
+func TestReverse(t *testing.T) {
+	p := NewPolynomialInts(3, 1, 4, 0, 0)
+	trimmed := p.Trim()
+
+	reversed := p.Reverse()
+	assert.Equal(t, 2, reversed.Degree())
+	expected := NewPolynomialInts(4, 1, 3)
+	assert.Equal(t, 0, reversed.Compare(&expected))
+
+	twice := p.Reverse().Reverse()
+	assert.Equal(t, 0, twice.Compare(&trimmed))
+	assert.Len(t, twice, len(trimmed))
+
+	// x^d.p(1/x) evaluated at x = 2 equals 2^2.p(1/2)
+	m := testField.Modulus()
+	two := testField.NewFieldElementFromInt64(2)
+	half := two.Inv()
+	assert.Equal(t, 0, reversed.Eval(two.Big(), m).Cmp(testField.Mul(two.Square(), testField.NewFieldElement(p.Eval(half.Big(), m))).Big()))
+
+	// the factor x of a polynomial vanishing at 0 is lost
+	q := NewPolynomialInts(0, 5, 2)
+	back := q.Reverse().Reverse()
+	assert.Equal(t, 1, back.Degree())
+}
+
+func TestEvalNilCoefficient(t *testing.T) {
+	m := testField.Modulus()
+	p := NewPolynomialInts(3, 1, 4)
+	p[1] = nil
+	x := algebra.FromInt64(10)
+
+	var y *algebra.Integer
+	assert.NotPanics(t, func() { y = p.Eval(x, m) })
+	assert.Equal(t, int64(403), y.Int64())
+
+	_, err := p.EvalChecked(x, m)
+	assert.EqualError(t, err, "polynomial coefficient 1 is nil")
+
+	y, err = NewPolynomialInts(3, 1, 4).EvalChecked(x, m)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(413), y.Int64())
+}
+
+func TestMulCount(t *testing.T) {
+	m := testField.Modulus()
+	p, q := NewPolynomialInts(1, 2, 3), NewPolynomialInts(4, 5)
+
+	before := MulCount()
+	p.Mul(q, m)
+	assert.Equal(t, uint64(6), MulCount()-before)
+
+	before = MulCount()
+	p.EvalAt(testField.NewFieldElementFromInt64(7))
+	assert.Equal(t, uint64(3), MulCount()-before)
+}
+
+func TestModXk(t *testing.T) {
+	m := testField.Modulus()
+	p := NewPolynomialInts(3, 1, 4, 1, 5, 9, 2, 6)
+
+	low := p.ModXk(4)
+	assert.Equal(t, NewPolynomialInts(3, 1, 4, 1), low)
+	assert.Less(t, low.Degree(), 4)
+	// the high part is x^k times what's left
+	high, err := p.Sub(low, m).DivXPow(4)
+	assert.NoError(t, err)
+	assert.Equal(t, p, low.Add(high.MulXPow(4), m))
+	// extending the truncation with zeros keeps the low coefficients
+	extended := low.Clone(0)
+	for len(extended) < len(p) {
+		extended = append(extended, algebra.FromInt64(0))
+	}
+	assert.Len(t, extended, len(p))
+	assert.Equal(t, p[:4], extended[:4])
+	assert.Equal(t, low, extended.ModXk(4))
+
+	assert.Equal(t, p, p.ModXk(100))
+	assert.True(t, p.ModXk(0).IsZero())
+	assert.Equal(t, NewPolynomialInts(3), NewPolynomialInts(3, 0, 0, 7).ModXk(3))
+	assert.Panics(t, func() { p.ModXk(-1) })
+
+	q := NewPolynomialInts(2, 7, 1, 8)
+	for _, k := range []int{1, 3, 6, 20} {
+		assert.Equal(t, p.Clone(0).Mul(q.Clone(0), m).ModXk(k), p.MulModXk(q, k, m), "k = %d", k)
+	}
+}
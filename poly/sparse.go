@@ -0,0 +1,149 @@
+package poly
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"sort"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+)
+
+// Zerofiers like x^n - 1 have a handful of non zero coefficients for a
+// degree in the thousands, a SparsePolynomial only stores those so it is
+// evaluated with an exponentiation per term i.e O(log n) multiplications
+// instead of the n multiplications of Horner's rule.
+
+// SparsePolynomial maps the exponents of a polynomial to their non zero
+// coefficients, exponents must be non negative.
+type SparsePolynomial map[int]*algebra.Integer
+
+// SparseFromDense returns the non zero coefficients of p.
+func SparseFromDense(p Polynomial) SparsePolynomial {
+	s := make(SparsePolynomial)
+	for i, c := range p {
+		if c != nil && c.Sign() != 0 {
+			s[i] = new(big.Int).Set(c)
+		}
+	}
+	return s
+}
+
+// exponents returns the exponents of the non zero terms in increasing
+// order.
+// It panics if an exponent is negative.
+func (s SparsePolynomial) exponents() []int {
+	exps := make([]int, 0, len(s))
+	for e, c := range s {
+		if e < 0 {
+			panic(fmt.Sprintf("sparse polynomial has a negative exponent %d", e))
+		}
+		if c != nil && c.Sign() != 0 {
+			exps = append(exps, e)
+		}
+	}
+	sort.Ints(exps)
+	return exps
+}
+
+// Degree returns the highest exponent of a non zero term, -1 for the zero
+// polynomial.
+func (s SparsePolynomial) Degree() int {
+	exps := s.exponents()
+	if len(exps) == 0 {
+		return -1
+	}
+	return exps[len(exps)-1]
+}
+
+// Dense returns the coefficient vector of the polynomial.
+func (s SparsePolynomial) Dense() Polynomial {
+	p := make(Polynomial, s.Degree()+1)
+	for i := range p {
+		p[i] = big.NewInt(0)
+	}
+	for _, e := range s.exponents() {
+		p[e] = new(big.Int).Set(s[e])
+	}
+	if len(p) == 0 {
+		return NewPolynomialInts(0)
+	}
+	return p
+}
+
+// Eval returns s(x) mod m, the powers of x are raised from one term to
+// the next by exponentiating with the gap between their exponents.
+func (s SparsePolynomial) Eval(x *algebra.Integer, m *algebra.Integer) *algebra.Integer {
+
+	y := big.NewInt(0)
+	acc, prev := big.NewInt(1), 0
+	term := new(big.Int)
+	for _, e := range s.exponents() {
+		gap := big.NewInt(int64(e - prev))
+		mulCount.Add(uint64(2*bits.Len(uint(e-prev)) + 1))
+		acc.Mul(acc, new(big.Int).Exp(x, gap, m))
+		term.Mul(acc, s[e])
+		y.Add(y, term)
+		if m != nil {
+			acc.Mod(acc, m)
+			y.Mod(y, m)
+		}
+		prev = e
+	}
+	return y
+}
+
+// Mul computes the product of two sparse polynomials.
+func (s SparsePolynomial) Mul(q SparsePolynomial, m *algebra.Integer) SparsePolynomial {
+
+	r := make(SparsePolynomial)
+	sExps, qExps := s.exponents(), q.exponents()
+	mulCount.Add(uint64(len(sExps) * len(qExps)))
+	for _, i := range sExps {
+		for _, j := range qExps {
+			c, ok := r[i+j]
+			if !ok {
+				c = new(big.Int)
+				r[i+j] = c
+			}
+			c.Add(c, new(big.Int).Mul(s[i], q[j]))
+			if m != nil {
+				c.Mod(c, m)
+			}
+		}
+	}
+	for e, c := range r {
+		if c.Sign() == 0 {
+			delete(r, e)
+		}
+	}
+	return r
+}
+
+// MulDense computes the product of the sparse polynomial with the dense
+// polynomial p, it costs a multiplication per term and coefficient of p.
+func (s SparsePolynomial) MulDense(p Polynomial, m *algebra.Integer) Polynomial {
+
+	exps := s.exponents()
+	if len(exps) == 0 || len(p) == 0 {
+		return NewPolynomialInts(0)
+	}
+	r := make(Polynomial, exps[len(exps)-1]+len(p))
+	for i := range r {
+		r[i] = big.NewInt(0)
+	}
+	mulCount.Add(uint64(len(exps) * len(p)))
+	for _, e := range exps {
+		for j, c := range p {
+			if c == nil {
+				continue
+			}
+			r[e+j].Add(r[e+j], new(big.Int).Mul(s[e], c))
+			if m != nil {
+				r[e+j].Mod(r[e+j], m)
+			}
+		}
+	}
+	r.trim()
+	return r
+}
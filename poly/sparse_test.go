@@ -0,0 +1,51 @@
+package poly
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparsePolynomial(t *testing.T) {
+	m := testField.Modulus()
+	const n = 1024
+
+	// x^n - 1
+	zerofier := SparsePolynomial{n: big.NewInt(1), 0: new(big.Int).Sub(m, big.NewInt(1))}
+	dense := zerofier.Dense()
+	assert.Equal(t, n, dense.Degree())
+	assert.Equal(t, n, zerofier.Degree())
+	assert.Equal(t, zerofier, SparseFromDense(dense))
+
+	for _, x := range []int64{0, 1, 2, 3141592, 3221225472} {
+		v := algebra.FromInt64(x)
+		before := MulCount()
+		y := zerofier.Eval(v, m)
+		assert.Less(t, MulCount()-before, uint64(32))
+		assert.Equal(t, 0, dense.Eval(v, m).Cmp(y), "x = %d", x)
+	}
+
+	p := NewPolynomialInts(3, 0, 7, 0, 0, 1)
+	sp := SparseFromDense(p)
+	assert.Len(t, sp, 3)
+	assert.Equal(t, p, sp.Dense())
+
+	expected := dense.Mul(p.Clone(0), m)
+	assert.Equal(t, expected, zerofier.MulDense(p, m))
+	assert.Equal(t, SparseFromDense(expected), zerofier.Mul(sp, m))
+	for _, x := range []int64{5, 12345} {
+		v := algebra.FromInt64(x)
+		assert.Equal(t, 0, expected.Eval(v, m).Cmp(zerofier.Mul(sp, m).Eval(v, m)))
+	}
+
+	// (x - 1)(x + 1) cancels the middle term
+	square := SparsePolynomial{1: big.NewInt(1), 0: new(big.Int).Sub(m, big.NewInt(1))}.Mul(SparsePolynomial{1: big.NewInt(1), 0: big.NewInt(1)}, m)
+	assert.Len(t, square, 2)
+	assert.Equal(t, 2, square.Degree())
+
+	assert.Equal(t, -1, SparsePolynomial{}.Degree())
+	assert.True(t, SparsePolynomial{}.Dense().IsZero())
+	assert.Panics(t, func() { SparsePolynomial{-1: big.NewInt(1)}.Degree() })
+}
@@ -0,0 +1,209 @@
+package stark
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/merkle"
+	"github.com/ayushn2/go-stark.git/poly"
+)
+
+// Several FRI instances over the same evaluation domain are aggregated
+// into a single one : the verifier draws a random weight c_i for each
+// committed polynomial p_i and a single FRI runs on Sum(c_i.p_i), which is
+// of low degree only if every p_i is, except with negligible probability.
+// A FRIProof only holds openings at the query points so the instances are
+// aggregated from their committed polynomials and evaluations.
+// The aggregated proof opens every input at the query and sibling indices
+// so the verifier checks the openings against the input roots and that
+// they combine into the first layer openings.
+
+// FRIInstance holds a polynomial committed for a FRI i.e its evaluations
+// over the evaluation domain and their merkle root.
+type FRIInstance struct {
+	Polynomial  poly.Polynomial
+	Evaluations []algebra.FieldElement
+	Root        []byte
+}
+
+var (
+	errNoFRIInstances  = errors.New("no FRI instances to aggregate")
+	errInstanceDomain  = errors.New("FRI instance evaluations don't match the evaluation domain")
+	errInstanceRoot    = errors.New("FRI instance root doesn't commit to its evaluations")
+	errInputOpenings   = errors.New("query openings count doesn't match the aggregated inputs")
+	errNoDomainElement = errors.New("domain generator and offset must be field elements")
+)
+
+// AggregateFRI runs a single FRI on a random linear combination of the
+// instances over domain, the instance roots are sent trough the channel
+// before the weights are drawn. The returned proof's Roots start with the
+// root of the combined evaluations and each query's Trace holds, for the
+// ith instance, its openings at the query index and at the sibling index
+// as Trace[2i] and Trace[2i+1].
+func AggregateFRI(instances []FRIInstance, domain []algebra.FieldElement, ch *Channel) (FRIProof, error) {
+
+	if len(instances) == 0 {
+		return FRIProof{}, errNoFRIInstances
+	}
+	for _, instance := range instances {
+		if len(instance.Evaluations) != len(domain) {
+			return FRIProof{}, errInstanceDomain
+		}
+		if !bytes.Equal(DomainHash(instance.Evaluations), instance.Root) {
+			return FRIProof{}, errInstanceRoot
+		}
+	}
+
+	polys := make([]poly.Polynomial, len(instances))
+	for i, instance := range instances {
+		ch.Send(instance.Root)
+		polys[i] = instance.Polynomial
+	}
+	weights := aggregationWeights(ch, len(instances))
+	combinedPoly := combine(polys, weights)
+
+	combinedEvals := make([]algebra.FieldElement, len(domain))
+	for j := range domain {
+		acc := PrimeField.Zero()
+		for i, instance := range instances {
+			acc = PrimeField.Add(acc, PrimeField.Mul(weights[i], instance.Evaluations[j]))
+		}
+		combinedEvals[j] = acc
+	}
+	combinedRoot := DomainHash(combinedEvals)
+	ch.Send(combinedRoot)
+
+	_, _, layers, roots, err := GenerateFRICommitment(combinedPoly, domain, combinedEvals, combinedRoot, ch)
+	if err != nil {
+		return FRIProof{}, err
+	}
+
+	inputBytes := make([][][]byte, len(instances))
+	for i, instance := range instances {
+		inputBytes[i] = DomainBytes(instance.Evaluations)
+	}
+	n := len(domain)
+	indices := drawQueryIndices(ch, n)
+	queries := make([]FRIQuery, 0, len(indices))
+	for _, index := range indices {
+		inputs := make([]FRILayerOpening, 0, 2*len(instances))
+		for i, instance := range instances {
+			for _, idx := range []int{index, (index + n/2) % n} {
+				ap, err := merkle.Proof(inputBytes[i], idx)
+				if err != nil {
+					return FRIProof{}, err
+				}
+				ch.Send(inputBytes[i][idx])
+				ch.Send(serializeAuditPath(ap))
+				inputs = append(inputs, FRILayerOpening{idx, instance.Evaluations[idx], auditPathHashes(ap)})
+			}
+		}
+		query, err := DecommitFRILayers(index, ch, layers)
+		if err != nil {
+			return FRIProof{}, err
+		}
+		query.Trace = inputs
+		queries = append(queries, query)
+	}
+
+	return FRIProof{
+		Roots:     roots,
+		LastLayer: layers[len(layers)-1][0],
+		Queries:   queries,
+	}, nil
+}
+
+// aggregationWeights draws the weights of count aggregated instances.
+func aggregationWeights(ch *Channel, count int) []algebra.FieldElement {
+
+	weights := make([]algebra.FieldElement, count)
+	for i, w := range ch.RandFEBatch(PrimeField.Modulus(), count) {
+		weights[i] = PrimeField.NewFieldElement(w)
+	}
+	return weights
+}
+
+// VerifyAggregateFRI verifies a proof generated by AggregateFRI against the
+// input roots, the evaluation domain is the coset offset.<generator> of
+// size n. The transcript is replayed from a fresh channel, for each query
+// it checks :
+// - The input openings against their roots
+// - The input openings combine into the first layer openings
+// - The FRI layer openings and their folding down to the last layer
+// A malformed proof is reported as an error, a proof that doesn't verify
+// returns false.
+func VerifyAggregateFRI(modulus *algebra.Integer, inputRoots [][]byte, offset, generator algebra.FieldElement, n int, proof FRIProof) (bool, error) {
+
+	field := generator.Field()
+	if field.Modulus() == nil || offset.Field().Modulus() == nil {
+		return false, errNoDomainElement
+	}
+	if modulus == nil || field.Modulus().Cmp(modulus) != 0 {
+		return false, errModulusMismatch
+	}
+	if len(inputRoots) == 0 {
+		return false, errNoFRIInstances
+	}
+	roots := proof.Roots
+	if len(roots) == 0 {
+		return false, errNoFRIRoots
+	}
+
+	ch := NewChannel()
+	for _, root := range inputRoots {
+		ch.Send(root)
+	}
+	weights := aggregationWeights(ch, len(inputRoots))
+	ch.Send(roots[0])
+
+	betas := make([]algebra.FieldElement, 0, len(roots)-1)
+	for _, root := range roots[1:] {
+		betas = append(betas, field.NewFieldElement(ch.RandFE(modulus)))
+		ch.Send(root)
+	}
+	ch.Send(proof.LastLayer.Big().Bytes())
+
+	indices := drawQueryIndices(ch, n)
+	if len(proof.Queries) != len(indices) {
+		return false, errQueriesCount
+	}
+
+	for q, query := range proof.Queries {
+		if query.Index != indices[q] {
+			return false, nil
+		}
+		if len(query.Trace) != 2*len(inputRoots) {
+			return false, errInputOpenings
+		}
+		if len(query.Layers) != len(betas) || len(query.Siblings) != len(betas) {
+			return false, errQueryOpenings
+		}
+
+		combined := []algebra.FieldElement{field.Zero(), field.Zero()}
+		for i, root := range inputRoots {
+			for k, idx := range []int{query.Index, (query.Index + n/2) % n} {
+				opening := query.Trace[2*i+k]
+				if opening.Index != idx || !VerifyLayerOpening(root, opening.Value, opening.Index, opening.Path) {
+					return false, nil
+				}
+				ch.Send(opening.Value.Big().Bytes())
+				ch.Send(serializeLayerPath(opening.Index, opening.Path))
+				combined[k] = field.Add(combined[k], field.Mul(weights[i], opening.Value))
+			}
+		}
+		first := []algebra.FieldElement{proof.LastLayer, proof.LastLayer}
+		if len(query.Layers) > 0 {
+			first = []algebra.FieldElement{query.Layers[0].Value, query.Siblings[0].Value}
+		}
+		if !combined[0].Equal(first[0]) || !combined[1].Equal(first[1]) {
+			return false, nil
+		}
+
+		x := EvalDomainPoint(offset, generator, query.Index)
+		if _, _, ok := verifyFRILayers(ch, query, roots, betas, proof.LastLayer, x, n); !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
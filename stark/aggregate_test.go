@@ -0,0 +1,55 @@
+package stark
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateFRI(t *testing.T) {
+	small := newSmallFRI(t)
+	m := PrimeField.Modulus()
+	w := PrimeFieldGen.Exp(algebra.FromInt64(3221225472 / 128))
+
+	instance := func(p poly.Polynomial) FRIInstance {
+		evals := evalComposition(p, small.domain)
+		return FRIInstance{p, evals, DomainHash(evals)}
+	}
+	first := instance(small.poly)
+	second := instance(poly.NewPolynomialInts(2, 7, 1, 8, 2, 8, 1, 8, 2, 8))
+
+	// each instance is a valid FRI on its own
+	for _, in := range []FRIInstance{first, second} {
+		proof, err := AggregateFRI([]FRIInstance{in}, small.domain, NewChannel())
+		assert.NoError(t, err)
+		ok, err := VerifyAggregateFRI(m, [][]byte{in.Root}, PrimeFieldGen, w, len(small.domain), proof)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	proof, err := AggregateFRI([]FRIInstance{first, second}, small.domain, NewChannel())
+	assert.NoError(t, err)
+	assert.Len(t, proof.Queries[0].Trace, 4)
+	inputRoots := [][]byte{first.Root, second.Root}
+	ok, err := VerifyAggregateFRI(m, inputRoots, PrimeFieldGen, w, len(small.domain), proof)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// the aggregated proof is bound to each input root
+	ok, err = VerifyAggregateFRI(m, [][]byte{first.Root, first.Root}, PrimeFieldGen, w, len(small.domain), proof)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	proof.Queries[1].Trace[3].Value = proof.Queries[1].Trace[3].Value.Double()
+	ok, err = VerifyAggregateFRI(m, inputRoots, PrimeFieldGen, w, len(small.domain), proof)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	second.Root = first.Root
+	_, err = AggregateFRI([]FRIInstance{first, second}, small.domain, NewChannel())
+	assert.Equal(t, errInstanceRoot, err)
+	_, err = AggregateFRI(nil, small.domain, NewChannel())
+	assert.Equal(t, errNoFRIInstances, err)
+}
@@ -0,0 +1,231 @@
+package stark
+
+import (
+	"errors"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+)
+
+// An AIR (Algebraic Intermediate Representation) describes the constraints
+// of a program over its trace polynomial f.
+// Each constraint reads the trace at a fixed set of row offsets i.e for an
+// offset k the constraint reads f(g^k.x) where g generates the trace domain.
+// A constraint is a numerator that must vanish on a set of rows of the trace
+// domain, equivalently the numerator is divisible by the zerofier
+// Prod(x - g^i) for i in those rows.
+// The prover encodes the constraints as polynomial quotients (see constraint.go)
+// the verifier only needs to evaluate those quotients at the queried points
+// given the opened trace values.
+
+// AIR represents the algebraic description of a program.
+type AIR interface {
+	// Offsets returns the row offsets read by the constraints.
+	Offsets() []int
+	// NumConstraints returns the number of constraints of the program.
+	NumConstraints() int
+	// ConstraintRows returns the rows of the trace domain on which the ith
+	// constraint must hold.
+	ConstraintRows(i int) []int
+	// ConstraintDegree returns the degree of the ith numerator as a
+	// polynomial in the trace values e.g 2 for f(g.x)^2.
+	ConstraintDegree(i int) int
+	// EvalNumerators evaluates the constraint numerators at x given
+	// the generator g of the trace domain and the trace values
+	// f(g^k.x) for each k in Offsets.
+	EvalNumerators(x, g algebra.FieldElement, values []algebra.FieldElement) ([]algebra.FieldElement, error)
+}
+
+// A randomized AIR (RAP, randomized AIR with preprocessing) extends the
+// trace with columns computed from a challenge drawn after the trace is
+// committed e.g the running product of a permutation argument. Like the
+// trace, each extension column is interpolated over the trace domain and
+// committed over the evaluation domain in a second round before the
+// composition polynomial is built, the queries open it at the offsets of
+// the AIR and its constraints read both the trace and the extension values.
+// The boundary constraints (see BoundaryOutputs) only read the trace, they
+// are evaluated by EvalNumerators.
+
+// RandomizedAIR is an AIR with extension columns.
+type RandomizedAIR interface {
+	AIR
+	// NumExtensionColumns returns the number of extension columns.
+	NumExtensionColumns() int
+	// ExtensionColumns returns the extension columns over the trace rows
+	// given the challenge drawn from the channel, a column shorter than
+	// the trace domain is padded with its last value.
+	ExtensionColumns(challenge algebra.FieldElement) [][]algebra.FieldElement
+	// EvalExtendedNumerators evaluates the constraint numerators as
+	// EvalNumerators does, extension[c][i] holds the value of the cth
+	// extension column at g^k.x for the ith offset k.
+	EvalExtendedNumerators(x, g, challenge algebra.FieldElement, values []algebra.FieldElement, extension [][]algebra.FieldElement) ([]algebra.FieldElement, error)
+}
+
+var (
+	errTraceValuesCount     = errors.New("trace values count doesn't match AIR offsets")
+	errExtensionValuesCount = errors.New("extension values count doesn't match AIR extension columns and offsets")
+	errZeroDenominator      = errors.New("constraint denominator vanishes at x")
+)
+
+// EvalZerofier evaluates Prod(x - g^i) for i in rows.
+func EvalZerofier(x, g algebra.FieldElement, rows []int) algebra.FieldElement {
+	field := x.Field()
+	z := field.One()
+	for _, i := range rows {
+		z = field.Mul(z, field.Sub(x, g.Exp(algebra.FromInt64(int64(i)))))
+	}
+	return z
+}
+
+// EvalConstraints evaluates the constraint quotients of the AIR at x i.e
+// each numerator divided by the zerofier of its rows, x must not be a
+// row of the trace domain on which a constraint holds.
+func EvalConstraints(air AIR, x, g algebra.FieldElement, values []algebra.FieldElement) ([]algebra.FieldElement, error) {
+
+	if len(values) != len(air.Offsets()) {
+		return nil, errTraceValuesCount
+	}
+	nums, err := air.EvalNumerators(x, g, values)
+	if err != nil {
+		return nil, err
+	}
+	return divideZerofiers(air, x, g, nums)
+}
+
+// EvalExtendedConstraints evaluates the constraint quotients of the
+// RandomizedAIR at x as EvalConstraints does given the extension values
+// (see EvalExtendedNumerators).
+func EvalExtendedConstraints(air RandomizedAIR, x, g, challenge algebra.FieldElement, values []algebra.FieldElement, extension [][]algebra.FieldElement) ([]algebra.FieldElement, error) {
+
+	if len(values) != len(air.Offsets()) {
+		return nil, errTraceValuesCount
+	}
+	if len(extension) != air.NumExtensionColumns() {
+		return nil, errExtensionValuesCount
+	}
+	for _, column := range extension {
+		if len(column) != len(values) {
+			return nil, errExtensionValuesCount
+		}
+	}
+	nums, err := air.EvalExtendedNumerators(x, g, challenge, values, extension)
+	if err != nil {
+		return nil, err
+	}
+	return divideZerofiers(air, x, g, nums)
+}
+
+// divideZerofiers divides each numerator by the zerofier of its rows at x.
+func divideZerofiers(air AIR, x, g algebra.FieldElement, nums []algebra.FieldElement) ([]algebra.FieldElement, error) {
+
+	quotients := make([]algebra.FieldElement, len(nums))
+	for i, num := range nums {
+		den := EvalZerofier(x, g, air.ConstraintRows(i))
+		if den.IsZero() {
+			return nil, errZeroDenominator
+		}
+		quotients[i] = x.Field().Div(num, den)
+	}
+	return quotients, nil
+}
+
+// CompositionDegreeBound returns the degree of the composition polynomial
+// of the AIR for a trace of traceLen rows i.e the maximum degree of the
+// constraint quotients, the trace polynomial has degree traceLen - 1 so the
+// ith quotient has degree ConstraintDegree(i).(traceLen - 1) minus the
+// number of rows on which it holds. A constraint of degree 0 has a zero
+// quotient and doesn't raise the bound.
+// FRI tests the degree of the composition polynomial so the evaluation
+// domain must be larger than the bound (see BlowupFor).
+func CompositionDegreeBound(air AIR, traceLen int) int {
+
+	bound := 0
+	for i := 0; i < air.NumConstraints(); i++ {
+		deg := air.ConstraintDegree(i)*(traceLen-1) - len(air.ConstraintRows(i))
+		if deg > bound {
+			bound = deg
+		}
+	}
+	return bound
+}
+
+// BlowupFor returns the smallest power of two blowup such that the
+// evaluation domain holds at least twice as many points as the
+// coefficients of the composition polynomial, to be passed to
+// GenerateDomainParameters.
+// It panics if no evaluation domain of the prime field is large enough
+// (see DomainSize).
+func BlowupFor(air AIR, traceLen, traceDomainSize int) int {
+
+	bound := uint64(2 * (CompositionDegreeBound(air, traceLen) + 1))
+	for blowup := 1; ; blowup *= 2 {
+		size, err := DomainSize(traceDomainSize, blowup)
+		if err != nil {
+			panic(err)
+		}
+		if size >= bound {
+			return blowup
+		}
+	}
+}
+
+// FibonacciAIR is the AIR of the FibSeq program (see constraint.go).
+// FibSeq(0) = 1
+// FibSeq(1022) = 2338775057
+// For all i, FibSeq(i+2) = FibSeq(i+1)^2 + FibSeq(i)^2
+type FibonacciAIR struct{}
+
+// Offsets returns the rows i, i+1 and i+2 read by the transition constraint.
+func (FibonacciAIR) Offsets() []int {
+	return []int{0, 1, 2}
+}
+
+// NumConstraints returns the two boundary and the transition constraints.
+func (FibonacciAIR) NumConstraints() int {
+	return 3
+}
+
+// ConstraintRows returns the first row, the row 1022 and the rows 0 to 1020
+// for the transition constraint.
+func (FibonacciAIR) ConstraintRows(i int) []int {
+	switch i {
+	case 0:
+		return []int{0}
+	case 1:
+		return []int{1022}
+	default:
+		rows := make([]int, 1021)
+		for j := range rows {
+			rows[j] = j
+		}
+		return rows
+	}
+}
+
+// ConstraintDegree returns 1 for the boundary constraints and 2 for the
+// transition constraint.
+func (FibonacciAIR) ConstraintDegree(i int) int {
+	if i < 2 {
+		return 1
+	}
+	return 2
+}
+
+// EvalNumerators evaluates the three numerators of GenerateProgramConstraints
+// at x, values holds f(x), f(g.x) and f(g^2.x).
+func (FibonacciAIR) EvalNumerators(x, g algebra.FieldElement, values []algebra.FieldElement) ([]algebra.FieldElement, error) {
+
+	if len(values) != 3 {
+		return nil, errTraceValuesCount
+	}
+	field := x.Field()
+	fx, fgx, fg2x := values[0], values[1], values[2]
+
+	return []algebra.FieldElement{
+		// f(x) - 1
+		fx.SubInt64(1),
+		// f(x) - 2338775057
+		fx.SubInt64(2338775057),
+		// f(g^2.x) - f(g.x)^2 - f(x)^2
+		field.Sub(field.Sub(fg2x, fgx.Square()), fx.Square()),
+	}, nil
+}
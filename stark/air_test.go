@@ -0,0 +1,232 @@
+package stark
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+	"github.com/stretchr/testify/assert"
+)
+
+// skipAIR relates rows i and i+3 trough a_{i+3} = 2.a_i over a trace of
+// length 8, the transition holds for the rows 0 to 4.
+type skipAIR struct{}
+
+func (skipAIR) Offsets() []int {
+	return []int{0, 3}
+}
+
+func (skipAIR) NumConstraints() int {
+	return 1
+}
+
+func (skipAIR) ConstraintDegree(i int) int {
+	return 1
+}
+
+func (skipAIR) ConstraintRows(i int) []int {
+	return []int{0, 1, 2, 3, 4}
+}
+
+func (skipAIR) EvalNumerators(x, g algebra.FieldElement, values []algebra.FieldElement) ([]algebra.FieldElement, error) {
+	if len(values) != 2 {
+		return nil, errTraceValuesCount
+	}
+	return []algebra.FieldElement{PrimeField.Sub(values[1], values[0].Double())}, nil
+}
+
+// skipTrace returns the trace of skipAIR and the generator of its domain.
+func skipTrace() ([]algebra.FieldElement, algebra.FieldElement) {
+	g := PrimeFieldGen.Exp(algebra.FromInt64(3221225472 / 8))
+	trace := []algebra.FieldElement{
+		PrimeField.NewFieldElementFromInt64(3),
+		PrimeField.NewFieldElementFromInt64(1),
+		PrimeField.NewFieldElementFromInt64(4),
+	}
+	for i := 3; i < 8; i++ {
+		trace = append(trace, trace[i-3].Double())
+	}
+	return trace, g
+}
+
+func TestShiftedTracePolynomials(t *testing.T) {
+	trace, g := skipTrace()
+	f := poly.Lagrange(generatePoints(GenElems(g, 8), trace), PrimeField.Modulus())
+
+	shifted := ShiftedTracePolynomials(f, g, skipAIR{}.Offsets())
+	assert.Len(t, shifted, 2)
+
+	num := shifted[1].Sub(shifted[0].Mul(poly.NewPolynomialInts(2), PrimeField.Modulus()), PrimeField.Modulus())
+	for i, x := range GenElems(g, 8) {
+		eval := num.Eval(x.Big(), PrimeField.Modulus())
+		if i < 5 {
+			assert.Equal(t, 0, eval.Sign(), "row %d", i)
+		} else {
+			assert.NotEqual(t, 0, eval.Sign(), "row %d", i)
+		}
+	}
+
+	// the quotient evaluated by the AIR matches the polynomial quotient
+	zerofier := poly.NewPolynomialInts(1)
+	for _, x := range GenElems(g, 5) {
+		zerofier = zerofier.Mul(poly.NewPolynomialBigInt(new(algebra.Integer).Neg(x.Big()), algebra.FromInt64(1)), PrimeField.Modulus())
+	}
+	quo, rem := num.Div(zerofier, PrimeField.Modulus())
+	assert.True(t, rem.IsZero())
+
+	x := PrimeField.NewFieldElementFromInt64(31415)
+	values := []algebra.FieldElement{
+		PrimeField.NewFieldElement(f.Eval(x.Big(), PrimeField.Modulus())),
+		PrimeField.NewFieldElement(f.Eval(PrimeField.Mul(x, g.Exp(algebra.FromInt64(3))).Big(), PrimeField.Modulus())),
+	}
+	evals, err := EvalConstraints(skipAIR{}, x, g, values)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, evals[0].Big().Cmp(quo.Eval(x.Big(), PrimeField.Modulus())))
+}
+
+// brokenSkipAIR wrongly asserts a_{i+3} = 3.a_i
+type brokenSkipAIR struct {
+	skipAIR
+}
+
+func (brokenSkipAIR) EvalNumerators(x, g algebra.FieldElement, values []algebra.FieldElement) ([]algebra.FieldElement, error) {
+	return []algebra.FieldElement{PrimeField.Sub(values[1], values[0].MulInt64(3))}, nil
+}
+
+func TestCheckConstraintsOnTraceDomain(t *testing.T) {
+	trace, g := skipTrace()
+	params := &DomainParameters{Trace: trace, GeneratorG: g, SubgroupG: GenElems(g, 8)}
+
+	assert.NoError(t, params.CheckConstraintsOnTraceDomain(skipAIR{}))
+	assert.EqualError(t, params.CheckConstraintsOnTraceDomain(brokenSkipAIR{}), "constraint 0 doesn't hold at row 0")
+
+	params.Trace = append([]algebra.FieldElement{}, trace...)
+	params.Trace[6] = params.Trace[6].AddInt64(1)
+	assert.EqualError(t, params.CheckConstraintsOnTraceDomain(skipAIR{}), "constraint 0 doesn't hold at row 3")
+
+	fib, _ := loadFibonacci(t)
+	assert.NoError(t, fib.CheckConstraintsOnTraceDomain(FibonacciAIR{}))
+}
+
+func TestCompositionDegreeBound(t *testing.T) {
+	// f has degree 1022, the transition quotient has degree 2.1022 - 1021
+	assert.Equal(t, 1023, CompositionDegreeBound(FibonacciAIR{}, 1023))
+
+	_, constraints := loadFibonacci(t)
+	maxDegree := 0
+	for _, c := range constraints {
+		if c.Degree() > maxDegree {
+			maxDegree = c.Degree()
+		}
+	}
+	assert.Equal(t, maxDegree, CompositionDegreeBound(FibonacciAIR{}, 1023))
+
+	ch := NewChannel()
+	ch.Send([]byte("composition degree"))
+	cp, _, _ := fibComposition(t, ch)
+	assert.Equal(t, cp.Degree(), CompositionDegreeBound(FibonacciAIR{}, 1023))
+
+	assert.Equal(t, 2, BlowupFor(FibonacciAIR{}, 1023, 1024))
+	assert.Equal(t, 1, BlowupFor(skipAIR{}, 8, 8))
+}
+
+// trivialAIR is the Fibonacci AIR with a constant zero constraint of
+// degree 0 on the first rows.
+type trivialAIR struct {
+	FibonacciAIR
+}
+
+func (trivialAIR) NumConstraints() int {
+	return 4
+}
+
+func (a trivialAIR) ConstraintRows(i int) []int {
+	if i == 3 {
+		return []int{0, 1, 2, 3}
+	}
+	return a.FibonacciAIR.ConstraintRows(i)
+}
+
+func (a trivialAIR) ConstraintDegree(i int) int {
+	if i == 3 {
+		return 0
+	}
+	return a.FibonacciAIR.ConstraintDegree(i)
+}
+
+func (a trivialAIR) EvalNumerators(x, g algebra.FieldElement, values []algebra.FieldElement) ([]algebra.FieldElement, error) {
+	nums, err := a.FibonacciAIR.EvalNumerators(x, g, values)
+	if err != nil {
+		return nil, err
+	}
+	return append(nums, x.Field().Zero()), nil
+}
+
+// constantAIR only holds the constant constraint of trivialAIR.
+type constantAIR struct {
+	trivialAIR
+}
+
+func (constantAIR) NumConstraints() int {
+	return 1
+}
+
+func (a constantAIR) ConstraintRows(i int) []int {
+	return a.trivialAIR.ConstraintRows(3)
+}
+
+func (constantAIR) ConstraintDegree(i int) int {
+	return 0
+}
+
+func (constantAIR) EvalNumerators(x, g algebra.FieldElement, values []algebra.FieldElement) ([]algebra.FieldElement, error) {
+	return []algebra.FieldElement{x.Field().Zero()}, nil
+}
+
+// proveAIR proves the trace of the domain parameters under the AIR with
+// the given constraint quotients, nil derives them from the AIR.
+func proveAIR(params *DomainParameters, air AIR, constraints []poly.Polynomial) (StarkProof, error) {
+
+	state, err := ProveAIR(params, air, constraints, ProverConfig{})
+	if err != nil {
+		return StarkProof{}, err
+	}
+	return state.Proof()
+}
+
+func TestDegreeZeroConstraint(t *testing.T) {
+	params, fibProof := loadFibonacciProof(t)
+	m := PrimeField.Modulus()
+	assert.Equal(t, CompositionDegreeBound(FibonacciAIR{}, 1023), CompositionDegreeBound(trivialAIR{}, 1023))
+	assert.Equal(t, 0, CompositionDegreeBound(constantAIR{}, 1023))
+
+	// the constant constraint contributes nothing to the composition
+	proof, err := proveAIR(params, trivialAIR{}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, proof.FRI.Roots, len(fibProof.FRI.Roots))
+	pub := params.PublicInputs()
+	pub.AIR = trivialAIR{}
+	result, err := Verify(m, params.EvaluationRoot, pub, proof, ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, result.OK)
+
+	// a constant composition isn't folded, the queries check it against
+	// the last layer
+	proof, err = proveAIR(params, constantAIR{}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, proof.FRI.Roots, 1)
+	assert.True(t, proof.FRI.LastLayer.IsZero())
+	pub.AIR = constantAIR{}
+	result, err = Verify(m, params.EvaluationRoot, pub, proof, ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, result.OK)
+
+	// a prover committing to another constant is caught
+	proof, err = proveAIR(params, constantAIR{}, []poly.Polynomial{poly.NewPolynomialInts(1)})
+	assert.NoError(t, err)
+	assert.Len(t, proof.FRI.Roots, 1)
+	result, err = Verify(m, params.EvaluationRoot, pub, proof, ProverConfig{})
+	assert.NoError(t, err)
+	assert.False(t, result.OK)
+	assert.Equal(t, CheckComposition, result.Failure.Check)
+}
@@ -0,0 +1,139 @@
+package stark
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/merkle"
+	"github.com/ayushn2/go-stark.git/poly"
+)
+
+// Constraints of degree d > 1 yield a composition polynomial of degree
+// about d.n for a trace of n rows (see CompositionDegreeBound), rather than
+// growing the evaluation domain the composition is split into columns of
+// degree less than n.
+// The jth column holds the coefficients of degree j.n to (j+1).n - 1 so
+// that cp(x) = col_0(x) + x^n.col_1(x) + x^2n.col_2(x) + ...
+// and every column is opened at the query point itself.
+// The columns are committed jointly, the ith leaf packs the values of all
+// the columns at the ith point of the evaluation domain (see
+// PackFieldElements) so a single authentication path opens every column.
+// The prover splits the composition once its degree reaches the trace
+// domain size, FRI then runs on a random combination of the columns drawn
+// after their commitment, which bounds the degree of every column, and the
+// verifier recombines cp(x) from the opened columns to check it against
+// the constraints.
+
+// CompositionColumnsOpening holds the values of the composition columns at
+// Index and the authentication path of their joint leaf.
+type CompositionColumnsOpening struct {
+	Index  int
+	Values []algebra.FieldElement
+	Path   [][]byte
+}
+
+// SplitComposition splits cp into ceil((deg(cp)+1)/n) columns of n
+// coefficients, a single column is returned when cp has degree less than n.
+// It panics if n < 1.
+func SplitComposition(cp poly.Polynomial, n int) []poly.Polynomial {
+
+	if n < 1 {
+		panic(fmt.Sprintf("invalid composition column size %d", n))
+	}
+	count := (len(cp) + n - 1) / n
+	if count == 0 {
+		return []poly.Polynomial{poly.NewPolynomialInts(0)}
+	}
+	columns := make([]poly.Polynomial, count)
+	for j := range columns {
+		end := (j + 1) * n
+		if end > len(cp) {
+			end = len(cp)
+		}
+		columns[j] = poly.Polynomial(cp[j*n : end]).Clone(0)
+	}
+	return columns
+}
+
+// compositionRows returns the packed leaves of the columns evaluations.
+func compositionRows(evals [][]algebra.FieldElement) [][]byte {
+
+	rows := make([][]byte, len(evals[0]))
+	row := make([]algebra.FieldElement, len(evals))
+	for i := range rows {
+		for j := range evals {
+			row[j] = evals[j][i]
+		}
+		rows[i] = PackFieldElements(row)
+	}
+	return rows
+}
+
+// CommitCompositionColumns evaluates the columns over the domain and
+// returns the evaluations of each column and the root of their joint
+// commitment.
+func CommitCompositionColumns(columns []poly.Polynomial, domain []algebra.FieldElement) ([][]algebra.FieldElement, []byte) {
+
+	evals := make([][]algebra.FieldElement, len(columns))
+	for j, column := range columns {
+		evals[j] = evalComposition(column, domain)
+	}
+	return evals, merkle.Root(compositionRows(evals))
+}
+
+// OpenCompositionColumns opens the columns evaluations at index.
+func OpenCompositionColumns(evals [][]algebra.FieldElement, index int) (CompositionColumnsOpening, error) {
+
+	ap, err := merkle.Proof(compositionRows(evals), index)
+	if err != nil {
+		return CompositionColumnsOpening{}, err
+	}
+	values := make([]algebra.FieldElement, len(evals))
+	for j := range evals {
+		values[j] = evals[j][index]
+	}
+	return CompositionColumnsOpening{index, values, auditPathHashes(ap)}, nil
+}
+
+// RecombineComposition checks the opening against the columns commitment
+// root and returns cp(x) = Sum(x^(j.n).col_j(x)) where x is the domain
+// point at the opening index and n the column size.
+func RecombineComposition(root []byte, opening CompositionColumnsOpening, x algebra.FieldElement, n int) (algebra.FieldElement, bool) {
+
+	if len(opening.Values) == 0 {
+		return algebra.FieldElement{}, false
+	}
+	if !verifyLeaf(root, PackFieldElements(opening.Values), opening.Index, opening.Path) {
+		return algebra.FieldElement{}, false
+	}
+	field := x.Field()
+	xn := x.Exp(algebra.FromInt64(int64(n)))
+	// Horner's rule in x^n from the last column
+	cp := field.Zero()
+	for j := len(opening.Values) - 1; j >= 0; j-- {
+		cp = field.Add(field.Mul(cp, xn), opening.Values[j])
+	}
+	return cp, true
+}
+
+var errCompositionColumns = errors.New("composition columns count exceeds the composition degree bound")
+
+// MaxCompositionColumns returns the number of columns of n coefficients
+// holding a composition polynomial of the AIR for a trace of n rows (see
+// CompositionDegreeBound).
+func MaxCompositionColumns(air AIR, n int) int {
+	return CompositionDegreeBound(air, n)/n + 1
+}
+
+// mixColumns returns the combination Sum(weights[j].values[j]) of the
+// columns values.
+func mixColumns(weights, values []algebra.FieldElement) algebra.FieldElement {
+
+	field := weights[0].Field()
+	mix := field.Zero()
+	for j, value := range values {
+		mix = field.Add(mix, field.Mul(weights[j], value))
+	}
+	return mix
+}
@@ -0,0 +1,179 @@
+package stark
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+	"github.com/stretchr/testify/assert"
+)
+
+// cubeAIR enforces a_{i+1} = a_i^3 on a trace of length 8, its quotient
+// has degree 3.7 - 7 = 14 so the composition needs two columns of 8
+// coefficients.
+type cubeAIR struct{}
+
+func (cubeAIR) Offsets() []int {
+	return []int{0, 1}
+}
+
+func (cubeAIR) NumConstraints() int {
+	return 1
+}
+
+func (cubeAIR) ConstraintDegree(i int) int {
+	return 3
+}
+
+func (cubeAIR) ConstraintRows(i int) []int {
+	return []int{0, 1, 2, 3, 4, 5, 6}
+}
+
+func (cubeAIR) EvalNumerators(x, g algebra.FieldElement, values []algebra.FieldElement) ([]algebra.FieldElement, error) {
+	if len(values) != 2 {
+		return nil, errTraceValuesCount
+	}
+	cube := PrimeField.Mul(values[0].Square(), values[0])
+	return []algebra.FieldElement{PrimeField.Sub(values[1], cube)}, nil
+}
+
+// cubicFibonacciAIR multiplies the Fibonacci transition constraint by f(x),
+// its quotient has degree 3.1022 - 1021 = 2045 so the composition needs two
+// columns of 1024 coefficients.
+type cubicFibonacciAIR struct {
+	FibonacciAIR
+}
+
+func (a cubicFibonacciAIR) ConstraintDegree(i int) int {
+	if i < 2 {
+		return a.FibonacciAIR.ConstraintDegree(i)
+	}
+	return 3
+}
+
+func (a cubicFibonacciAIR) EvalNumerators(x, g algebra.FieldElement, values []algebra.FieldElement) ([]algebra.FieldElement, error) {
+	nums, err := a.FibonacciAIR.EvalNumerators(x, g, values)
+	if err != nil {
+		return nil, err
+	}
+	nums[2] = PrimeField.Mul(nums[2], values[0])
+	return nums, nil
+}
+
+func TestCompositionColumns(t *testing.T) {
+	m := PrimeField.Modulus()
+	g := PrimeFieldGen.Exp(algebra.FromInt64(3221225472 / 8))
+	trace := []algebra.FieldElement{PrimeField.NewFieldElementFromInt64(2)}
+	for i := 1; i < 8; i++ {
+		trace = append(trace, PrimeField.Mul(trace[i-1].Square(), trace[i-1]))
+	}
+	f := poly.Lagrange(generatePoints(GenElems(g, 8), trace), m)
+
+	// the quotient (f(g.x) - f(x)^3).(x - g^7) / (x^8 - 1)
+	shifted := ShiftedTracePolynomials(f, g, cubeAIR{}.Offsets())
+	num := shifted[1].Sub(shifted[0].Mul(shifted[0], m).Mul(shifted[0], m), m)
+	last := poly.NewPolynomialBigInt(g.Exp(algebra.FromInt64(7)).Neg().Big(), algebra.FromInt64(1))
+	quotient, err := DivideByVanishing(num.Mul(last, m), 8, m)
+	assert.NoError(t, err)
+	assert.Equal(t, CompositionDegreeBound(cubeAIR{}, 8), quotient.Degree())
+
+	weight := PrimeField.NewFieldElementFromInt64(7)
+	cp := combine([]poly.Polynomial{quotient}, []algebra.FieldElement{weight})
+	columns := SplitComposition(cp, 8)
+	assert.Len(t, columns, 2)
+	for _, column := range columns {
+		assert.Less(t, column.Degree(), 8)
+	}
+
+	w := PrimeFieldGen.Exp(algebra.FromInt64(3221225472 / 64))
+	domain := GenElems(w, 64)
+	for i := range domain {
+		domain[i] = PrimeField.Mul(PrimeFieldGen, domain[i])
+	}
+	evals, root := CommitCompositionColumns(columns, domain)
+
+	for _, index := range []int{0, 13, 63} {
+		x := domain[index]
+		opening, err := OpenCompositionColumns(evals, index)
+		assert.NoError(t, err)
+
+		value, ok := RecombineComposition(root, opening, x, 8)
+		assert.True(t, ok)
+		assert.True(t, value.Equal(cp.EvalAt(x)))
+
+		ok, err = CheckCompositionAtQueries([]ColumnOpening{{
+			Index:       index,
+			X:           x,
+			Trace:       []algebra.FieldElement{f.EvalAt(x), f.EvalAt(PrimeField.Mul(g, x))},
+			Composition: value,
+		}}, []algebra.FieldElement{weight}, cubeAIR{}, g)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		opening.Values[1] = opening.Values[1].Double()
+		_, ok = RecombineComposition(root, opening, x, 8)
+		assert.False(t, ok)
+	}
+
+	assert.Len(t, SplitComposition(poly.NewPolynomialInts(1, 2), 8), 1)
+}
+
+func TestProveCompositionColumns(t *testing.T) {
+	params, fibProof := loadFibonacciProof(t)
+	m := PrimeField.Modulus()
+	air := cubicFibonacciAIR{}
+	assert.Equal(t, 3, MaxCompositionColumns(air, 1024))
+	assert.Empty(t, fibProof.ColumnsRoot)
+
+	proof, err := proveAIR(params, air, nil)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, proof.ColumnsRoot)
+	// FRI runs on the combination of the columns of degree 1023
+	assert.Len(t, proof.FRI.Roots, len(fibProof.FRI.Roots))
+	pub := params.PublicInputs()
+	pub.AIR = air
+	result, err := Verify(m, params.EvaluationRoot, pub, proof, ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, result.OK)
+	for _, query := range proof.FRI.Queries {
+		assert.Len(t, query.Columns.Values, 2)
+	}
+
+	b, err := json.Marshal(proof)
+	assert.NoError(t, err)
+	var decoded StarkProof
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	result, err = Verify(m, params.EvaluationRoot, pub, decoded, ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, result.OK)
+	ok, err := VerifyStream(m, params.EvaluationRoot, pub, bytes.NewReader(b), ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// a tampered column doesn't match the columns commitment
+	tampered := decoded
+	tampered.FRI.Queries = append([]FRIQuery{}, decoded.FRI.Queries...)
+	tampered.FRI.Queries[1].Columns.Values = append([]algebra.FieldElement{}, decoded.FRI.Queries[1].Columns.Values...)
+	tampered.FRI.Queries[1].Columns.Values[1] = tampered.FRI.Queries[1].Columns.Values[1].Double()
+	result, err = Verify(m, params.EvaluationRoot, pub, tampered, ProverConfig{})
+	assert.NoError(t, err)
+	assert.False(t, result.OK)
+	assert.Equal(t, CheckMerkle, result.Failure.Check)
+	assert.Equal(t, 1, result.Failure.Query)
+
+	// more columns than the composition degree bound allows
+	for i := range tampered.FRI.Queries {
+		values := tampered.FRI.Queries[i].Columns.Values
+		tampered.FRI.Queries[i].Columns.Values = append(append([]algebra.FieldElement{}, values...), values[0], values[0])
+	}
+	_, err = Verify(m, params.EvaluationRoot, pub, tampered, ProverConfig{})
+	assert.ErrorIs(t, err, errCompositionColumns)
+
+	// the Fibonacci AIR bounds the composition degree below 2048
+	pub.AIR = FibonacciAIR{}
+	result, err = Verify(m, params.EvaluationRoot, pub, proof, ProverConfig{})
+	assert.NoError(t, err)
+	assert.False(t, result.OK)
+}
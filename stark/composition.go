@@ -0,0 +1,142 @@
+package stark
+
+import (
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+)
+
+// To generate succinct proofs the constraint quotients are combined into
+// a single composition polynomial, a random linear combination whose
+// weights are drawn from the Fiat-Shamir channel. If any constraint
+// quotient isn't a polynomial of low degree the combination isn't either
+// except with negligible probability.
+// How the weights are derived is left to a Combiner.
+
+// Combiner combines the constraint quotients into the composition polynomial.
+// Coefficients draws the weights from the channel exactly as Combine does
+// so the verifier derives the same weights.
+type Combiner interface {
+	Combine(constraints []poly.Polynomial, ch *Channel) poly.Polynomial
+	Coefficients(count int, ch *Channel) []algebra.FieldElement
+}
+
+// combine returns Sum(w_i.c_i).
+func combine(constraints []poly.Polynomial, weights []algebra.FieldElement) poly.Polynomial {
+
+	compositionPoly := poly.NewPolynomialInts(0)
+	for i, c := range constraints {
+		comb := c.Mul(poly.NewPolynomialBigInt(weights[i].Big()), PrimeField.Modulus())
+		compositionPoly.AddInPlace(comb, PrimeField.Modulus())
+	}
+	return compositionPoly
+}
+
+// IndependentRandomCombiner draws an independent random weight from the
+// channel for each constraint.
+type IndependentRandomCombiner struct{}
+
+// Coefficients draws count independent random weights r_i (see
+// CompositionCoefficients).
+func (IndependentRandomCombiner) Coefficients(count int, ch *Channel) []algebra.FieldElement {
+	return CompositionCoefficients(ch, count, PrimeField.Modulus())
+}
+
+// CompositionCoefficients draws the weights of numConstraints constraints
+// in the field of the given modulus, one element per constraint in order.
+// The prover combines the constraints with them and the verifier re-derives
+// them by calling it at the same point of the transcript.
+func CompositionCoefficients(ch *Channel, numConstraints int, modulus *algebra.Integer) []algebra.FieldElement {
+
+	field, _ := algebra.NewFiniteField(modulus)
+	weights := make([]algebra.FieldElement, numConstraints)
+	for i := range weights {
+		weights[i] = field.NewFieldElement(ch.RandFE(modulus))
+	}
+	return weights
+}
+
+// Combine returns Sum(r_i.c_i) where each r_i is drawn from the channel.
+func (c IndependentRandomCombiner) Combine(constraints []poly.Polynomial, ch *Channel) poly.Polynomial {
+	return combine(constraints, c.Coefficients(len(constraints), ch))
+}
+
+// AlphaPowersCombiner draws a single challenge alpha from the channel and
+// weights the constraints by its powers.
+type AlphaPowersCombiner struct{}
+
+// Coefficients draws alpha and returns 1, alpha, ..., alpha^(count-1).
+func (AlphaPowersCombiner) Coefficients(count int, ch *Channel) []algebra.FieldElement {
+
+	alpha := PrimeField.NewFieldElement(ch.RandFE(PrimeField.Modulus()))
+	return alpha.PowerTable(count)
+}
+
+// Combine returns Sum(alpha^i.c_i).
+func (c AlphaPowersCombiner) Combine(constraints []poly.Polynomial, ch *Channel) poly.Polynomial {
+	return combine(constraints, c.Coefficients(len(constraints), ch))
+}
+
+// ProverConfig holds the settings of the prover.
+type ProverConfig struct {
+	// Combiner builds the composition polynomial, defaults to
+	// IndependentRandomCombiner when nil.
+	Combiner Combiner
+	// UseArena evaluates the composition polynomial over the evaluation
+	// domain and folds the FRI layers with an algebra.FieldArena to avoid
+	// allocating the intermediate values, the proof is the same.
+	UseArena bool
+	// ProofOfWorkBits is the difficulty of the proof of work ground before
+	// the queries are drawn, 0 disables it. The verifier must use the same
+	// difficulty.
+	ProofOfWorkBits uint
+	// InvertPerFold inverts 2x at each FRI fold instead of precomputing
+	// the inverse table of the evaluation domain, the proof is the same.
+	InvertPerFold bool
+
+	// keepFRICapacity doesn't trim the folded FRI polynomials, it lets the
+	// benchmarks measure the memory released by trimming.
+	keepFRICapacity bool
+}
+
+// combiner returns the configured combiner or the default one.
+func (cfg ProverConfig) combiner() Combiner {
+	if cfg.Combiner == nil {
+		return IndependentRandomCombiner{}
+	}
+	return cfg.Combiner
+}
+
+// arena returns a new arena when enabled, nil otherwise.
+func (cfg ProverConfig) arena() *algebra.FieldArena {
+	if !cfg.UseArena {
+		return nil
+	}
+	return algebra.NewFieldArena(PrimeField)
+}
+
+// CompositionPolynomial combines the constraint quotients using the
+// combiner selected by the prover config.
+func CompositionPolynomial(constraints []poly.Polynomial, ch *Channel, cfg ProverConfig) poly.Polynomial {
+	return cfg.combiner().Combine(constraints, ch)
+}
+
+// evalComposition evaluates the composition polynomial over the domain.
+func evalComposition(cp poly.Polynomial, domain []algebra.FieldElement) []algebra.FieldElement {
+	return evalDomain(cp, domain, nil)
+}
+
+// evalDomain evaluates p over the domain, the intermediate values are
+// allocated from the arena when one is given and released after each point.
+func evalDomain(p poly.Polynomial, domain []algebra.FieldElement, arena *algebra.FieldArena) []algebra.FieldElement {
+
+	evals := make([]algebra.FieldElement, len(domain))
+	for idx, elem := range domain {
+		if arena == nil {
+			evals[idx] = p.EvalAt(elem)
+			continue
+		}
+		evals[idx] = arena.Detach(p.EvalAtArena(elem, arena))
+		arena.Reset()
+	}
+	return evals
+}
@@ -0,0 +1,53 @@
+package stark
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombiners(t *testing.T) {
+	_, constraints := loadFibonacci(t)
+
+	maxDegree := 0
+	for _, c := range constraints {
+		if c.Degree() > maxDegree {
+			maxDegree = c.Degree()
+		}
+	}
+
+	for _, combiner := range []Combiner{IndependentRandomCombiner{}, AlphaPowersCombiner{}} {
+		ch := NewChannel()
+		ch.Send([]byte("combiner"))
+		cp := CompositionPolynomial(constraints, ch, ProverConfig{Combiner: combiner})
+		assert.Equal(t, maxDegree, cp.Degree())
+
+		draws := len(ch.Transcript()) - 1
+		switch combiner.(type) {
+		case IndependentRandomCombiner:
+			assert.Equal(t, len(constraints), draws)
+		case AlphaPowersCombiner:
+			assert.Equal(t, 1, draws)
+		}
+	}
+}
+
+func TestCompositionCoefficients(t *testing.T) {
+	_, constraints := loadFibonacci(t)
+	seed := []byte("composition coefficients")
+
+	prover := NewChannel()
+	prover.Send(seed)
+	cp := CompositionPolynomial(constraints, prover, ProverConfig{})
+
+	verifier := NewChannel()
+	verifier.Send(seed)
+	coeffs := CompositionCoefficients(verifier, len(constraints), PrimeField.Modulus())
+	assert.Len(t, coeffs, len(constraints))
+	assert.Equal(t, prover.State, verifier.State)
+	assert.Equal(t, combine(constraints, coeffs), cp)
+
+	replay := NewChannel()
+	replay.Send(seed)
+	assert.Equal(t, coeffs, ProverConfig{}.combiner().Coefficients(len(constraints), replay))
+}
@@ -1,6 +1,9 @@
 package stark
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/ayushn2/go-stark.git/algebra"
 	"github.com/ayushn2/go-stark.git/poly"
 )
@@ -26,9 +29,165 @@ import (
 // FibSeq(1022) = 2338775057 => r(x) = f(x) - 2338775057 = 0 for x = g^1022
 // FibSeq(i+2) = FibSeq(i+1)^2 + FibSeq(i)^2 => f(g(x)^2) - f(g(x))^2 - f(x)^2.
 
-// GenerateProgramConstraints generates the polynomial constraints for the proof.
+// ShiftedTracePolynomials returns the trace polynomial shifted by each row
+// offset k i.e f(g^k.x) where g generates the trace domain, so that a
+// transition constraint can relate row i to any row i+k.
+func ShiftedTracePolynomials(f poly.Polynomial, g algebra.FieldElement, offsets []int) []poly.Polynomial {
+
+	shifted := make([]poly.Polynomial, len(offsets))
+
+	for i, k := range offsets {
+		shift := g.Exp(algebra.FromInt64(int64(k)))
+		shifted[i] = f.Scale(shift.Big(), g.Field().Modulus())
+	}
+	return shifted
+}
+
+// VanishingPolynomial returns x^n - 1 which vanishes on the subgroup of
+// order n.
+func VanishingPolynomial(n int) poly.Polynomial {
+	return poly.NewPolynomialInts(0, 1).Clone(n-1).Sub(poly.NewPolynomialInts(1), nil)
+}
+
+var errNotVanishing = errors.New("polynomial doesn't vanish on the subgroup")
+
+// DivideByVanishing returns p(x) / (x^n - 1), a constraint that holds on
+// every row of a trace domain of size n yields a polynomial quotient while
+// a non zero remainder means the trace doesn't satisfy the constraint.
+// The transition quotient of the Fibonacci program is computed with it so
+// the composition fed to FRI is built from the quotient (see
+// ProgramConstraints).
+func DivideByVanishing(p poly.Polynomial, n int, m *algebra.Integer) (poly.Polynomial, error) {
+
+	quo, rem := p.Div(VanishingPolynomial(n), m)
+	if !rem.IsZero() {
+		return nil, errNotVanishing
+	}
+	return quo, nil
+}
+
+// ConstraintQuotients builds the constraint quotients of any AIR over the
+// domain parameters without writing the numerators as polynomials : the
+// quotients are evaluated pointwise over the evaluation domain (see
+// EvalConstraints) which is disjoint from the trace domain, then
+// interpolated back over the coset. A constraint that doesn't hold on its
+// rows yields a rational function whose interpolant exceeds the degree
+// bound of the AIR (see CompositionDegreeBound), which is reported.
+func ConstraintQuotients(air AIR, params *DomainParameters) ([]poly.Polynomial, error) {
+	return constraintQuotients(air, params, func(x algebra.FieldElement, values []algebra.FieldElement, _ func(k int) int) ([]algebra.FieldElement, error) {
+		return EvalConstraints(air, x, params.GeneratorG, values)
+	})
+}
+
+// ExtendedConstraintQuotients builds the constraint quotients of the
+// RandomizedAIR as ConstraintQuotients does given the challenge and the
+// evaluations of the extension columns over the evaluation domain.
+func ExtendedConstraintQuotients(air RandomizedAIR, params *DomainParameters, challenge algebra.FieldElement, extensionEvals [][]algebra.FieldElement) ([]poly.Polynomial, error) {
+
+	n := len(params.EvaluationDomain)
+	for _, column := range extensionEvals {
+		if len(column) != n {
+			return nil, errEvaluationsCount
+		}
+	}
+	extension := make([][]algebra.FieldElement, len(extensionEvals))
+	for c := range extension {
+		extension[c] = make([]algebra.FieldElement, len(air.Offsets()))
+	}
+	return constraintQuotients(air, params, func(x algebra.FieldElement, values []algebra.FieldElement, index func(k int) int) ([]algebra.FieldElement, error) {
+		for c, column := range extensionEvals {
+			for i, k := range air.Offsets() {
+				extension[c][i] = column[index(k)]
+			}
+		}
+		return EvalExtendedConstraints(air, x, params.GeneratorG, challenge, values, extension)
+	})
+}
+
+// constraintQuotients interpolates the quotients evaluated by eval at each
+// point x of the evaluation domain given the trace values at the offsets
+// of the AIR, index maps an offset to the domain index of its row.
+func constraintQuotients(air AIR, params *DomainParameters, eval func(x algebra.FieldElement, values []algebra.FieldElement, index func(k int) int) ([]algebra.FieldElement, error)) ([]poly.Polynomial, error) {
+
+	n := len(params.EvaluationDomain)
+	if n == 0 || len(params.SubgroupG) == 0 || len(params.PolynomialEvaluations) != n {
+		return nil, errEvaluationsCount
+	}
+	field := params.GeneratorH.Field()
+	blowup := n / len(params.SubgroupG)
+	offsets := air.Offsets()
+
+	evals := make([][]algebra.FieldElement, air.NumConstraints())
+	for i := range evals {
+		evals[i] = make([]algebra.FieldElement, n)
+	}
+	values := make([]algebra.FieldElement, len(offsets))
+	for j, x := range params.EvaluationDomain {
+		index := func(k int) int {
+			return ((j+k*blowup)%n + n) % n
+		}
+		for i, k := range offsets {
+			values[i] = field.NewFieldElement(params.PolynomialEvaluations[index(k)])
+		}
+		quotients, err := eval(x, values, index)
+		if err != nil {
+			return nil, err
+		}
+		for i, q := range quotients {
+			evals[i][j] = q
+		}
+	}
+
+	plan, err := poly.NewNTTPlan(params.GeneratorH, uint64(n))
+	if err != nil {
+		return nil, err
+	}
+	// the interpolant over the subgroup is q(offset.x), scaling by the
+	// inverse of the offset recovers q
+	offsetInv := params.EvaluationDomain[0].Inv()
+	bound := CompositionDegreeBound(air, len(params.SubgroupG))
+	constraints := make([]poly.Polynomial, len(evals))
+	for i := range evals {
+		coeffs, err := plan.Inverse(evals[i])
+		if err != nil {
+			return nil, err
+		}
+		constraints[i] = poly.NewPolynomial(coeffs).Scale(offsetInv.Big(), field.Modulus())
+		if constraints[i].Degree() > bound {
+			return nil, fmt.Errorf("constraint %d isn't divisible by the zerofier of its rows", i)
+		}
+	}
+	return constraints, nil
+}
+
+// GenerateProgramConstraints generates the polynomial constraints for the proof,
+// the remainders of the divisions are ignored, see ProgramConstraints.
 func GenerateProgramConstraints(f poly.Polynomial, g algebra.FieldElement) (poly.Polynomial, poly.Polynomial, poly.Polynomial) {
 
+	quoPolyConstraint1, quoPolyConstraint2, num := programConstraints(f, g)
+	quoPolyConstraint3, _ := num.Div(VanishingPolynomial(1024), PrimeField.Modulus())
+	return quoPolyConstraint1, quoPolyConstraint2, quoPolyConstraint3
+}
+
+// ProgramConstraints generates the constraint quotients of the Fibonacci
+// program, the transition quotient is divided by the vanishing polynomial
+// of the trace domain with DivideByVanishing. A trace that doesn't satisfy
+// the transition leaves a non zero remainder reported as errNotVanishing.
+func ProgramConstraints(f poly.Polynomial, g algebra.FieldElement) ([]poly.Polynomial, error) {
+
+	quoPolyConstraint1, quoPolyConstraint2, num := programConstraints(f, g)
+	quoPolyConstraint3, err := DivideByVanishing(num, 1024, PrimeField.Modulus())
+	if err != nil {
+		return nil, err
+	}
+	return []poly.Polynomial{quoPolyConstraint1, quoPolyConstraint2, quoPolyConstraint3}, nil
+}
+
+// programConstraints returns the quotients of the boundary constraints and
+// the numerator of the transition constraint multiplied by the zerofier of
+// the rows the transition skips, which holds on the whole trace domain.
+func programConstraints(f poly.Polynomial, g algebra.FieldElement) (poly.Polynomial, poly.Polynomial, poly.Polynomial) {
+
 	// Each constraint (see /constraint.go) is represented by a polynomial u(x)
 	// that evaluates to 0 for a certain group element x in G
 	// When a polynomial evaluates to zero for a group element
@@ -48,15 +207,18 @@ func GenerateProgramConstraints(f poly.Polynomial, g algebra.FieldElement) (poly
 	dem1 := poly.NewPolynomialInts(0, 1).Sub(poly.NewPolynomial([]algebra.FieldElement{g.Exp(algebra.FromInt64(1022))}), PrimeField.Modulus())
 
 	quoPolyConstraint2, _ := num1.Div(dem1, PrimeField.Modulus())
-	// The third constraint requires polynomial composition
+	// The third constraint requires the trace polynomial shifted to the
+	// rows read by the transition i.e f(g^k.x) for each AIR offset k
 	// f(g^2.x) - f(g.x^2) - f(x)^2 / (X - g^k)
-	fcompGSquared := f.Compose(poly.NewPolynomialBigInt(algebra.FromInt64(0), g.Exp(algebra.FromInt64(2)).Big()), PrimeField.Modulus())
-	fcompG := f.Compose(poly.NewPolynomialBigInt(algebra.FromInt64(0), g.Big()), PrimeField.Modulus()).Pow(algebra.FromInt64(2), PrimeField.Modulus())
+	shifted := ShiftedTracePolynomials(f, g, FibonacciAIR{}.Offsets())
+	fcompGSquared := shifted[2]
+	fcompG := shifted[1].Pow(algebra.FromInt64(2), PrimeField.Modulus())
 	fSquared := f.Pow(algebra.FromInt64(2), PrimeField.Modulus())
 
 	num2 := fcompGSquared.Sub(fcompG, PrimeField.Modulus()).Sub(fSquared, PrimeField.Modulus())
-	dem2num := poly.NewPolynomialInts(0, 1).Clone(1023).Sub(poly.NewPolynomialInts(1), nil)
 
+	// the transition doesn't hold on the last three rows, their zerofier
+	// cancels them from the vanishing polynomial
 	coeffs := []algebra.FieldElement{
 		g.Exp(algebra.FromInt64(1021)),
 		g.Exp(algebra.FromInt64(1022)),
@@ -76,10 +238,6 @@ func GenerateProgramConstraints(f poly.Polynomial, g algebra.FieldElement) (poly
 		dem2dem = dem2dem.Mul(term, PrimeField.Modulus())
 	}
 
-	dem2, _ := dem2num.Div(dem2dem, PrimeField.Modulus())
-
-	quoPolyConstraint3, _ := num2.Div(dem2, PrimeField.Modulus())
-
-	return quoPolyConstraint1, quoPolyConstraint2, quoPolyConstraint3
+	return quoPolyConstraint1, quoPolyConstraint2, num2.Mul(dem2dem, PrimeField.Modulus())
 
 }
\ No newline at end of file
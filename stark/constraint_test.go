@@ -0,0 +1,55 @@
+package stark
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/poly"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDivideByVanishing(t *testing.T) {
+	_, g := skipTrace()
+	m := PrimeField.Modulus()
+
+	// a selector column is boolean on every row i.e s.(s - 1) vanishes
+	// on the whole trace domain
+	boolean := func(column PeriodicColumn) poly.Polynomial {
+		s, err := column.Polynomial(g, 8)
+		assert.NoError(t, err)
+		return s.Mul(s.Sub(poly.NewPolynomialInts(1), m), m)
+	}
+
+	num := boolean(NewPeriodicColumn(PrimeField.One(), PrimeField.Zero()))
+	quo, err := DivideByVanishing(num, 8, m)
+	assert.NoError(t, err)
+	product := quo.Mul(VanishingPolynomial(8), m)
+	assert.Equal(t, 0, product.Compare(&num))
+
+	num = boolean(NewPeriodicColumn(PrimeField.One(), PrimeField.NewFieldElementFromInt64(2)))
+	_, err = DivideByVanishing(num, 8, m)
+	assert.ErrorIs(t, err, errNotVanishing)
+}
+
+func TestProgramConstraints(t *testing.T) {
+	params, constraints := loadFibonacci(t)
+	m := PrimeField.Modulus()
+
+	// the transition quotient of a valid trace divides exactly
+	quotients, err := ProgramConstraints(params.Polynomial.Clone(0), params.GeneratorG)
+	assert.NoError(t, err)
+	assert.Len(t, quotients, 3)
+	for i := range quotients {
+		assert.Equal(t, 0, quotients[i].Compare(&constraints[i]), "constraint %d", i)
+	}
+	_, _, num := programConstraints(params.Polynomial.Clone(0), params.GeneratorG)
+	product := quotients[2].Mul(VanishingPolynomial(1024), m)
+	assert.Equal(t, 0, product.Compare(&num))
+
+	// a tampered trace leaves a remainder and can't be proven
+	tampered := *params
+	tampered.Polynomial = params.Polynomial.Add(poly.NewPolynomialInts(1), m)
+	_, err = ProgramConstraints(tampered.Polynomial.Clone(0), tampered.GeneratorG)
+	assert.ErrorIs(t, err, errNotVanishing)
+	_, err = Prove(&tampered, ProverConfig{})
+	assert.ErrorIs(t, err, errNotVanishing)
+}
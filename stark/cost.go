@@ -0,0 +1,99 @@
+package stark
+
+import (
+	"math/bits"
+	"time"
+)
+
+// The cost of a proof is dominated by the field multiplications of the
+// polynomial arithmetic and by the hashes of the merkle commitments, both
+// follow from the trace length n, the blowup and the number of constraints
+// k for an evaluation domain of N = n.blowup points :
+// - Trace commitment : an NTT interpolation and low degree extension (see
+// TraceBuilder) and a merkle tree over N leaves
+// - Constraints : each quotient multiplies and divides polynomials of degree
+// about n with the schoolbook arithmetic of the poly package, about 3n^2
+// multiplications for a quadratic constraint
+// - Composition : the combination of the k quotients and its evaluation
+// over the N points, the composition has degree about n for quadratic
+// constraints
+// - FRI : each layer folds pairs of the previous one and is committed
+// - Queries : merkle.Proof rebuilds the sibling subtrees of every opening
+// so each opening hashes about twice the size of its layer
+
+// ProvingCost is an estimate of the work of a proof.
+type ProvingCost struct {
+	// FieldMuls is the number of field multiplications.
+	FieldMuls uint64
+	// Hashes is the number of hash invocations of the merkle trees
+	// including the authentication paths of the queries.
+	Hashes uint64
+	// MerkleNodes is the number of nodes of the committed merkle trees.
+	MerkleNodes uint64
+	// Time is the estimated proving time on this machine.
+	Time time.Duration
+}
+
+// costCalibration is the number of operations timed to calibrate the
+// estimate.
+const costCalibration = 1 << 12
+
+// EstimateProvingCost estimates the cost of proving a trace of traceLen
+// rows over an evaluation domain blowup times larger with numConstraints
+// quadratic constraints, without running the proof. The time is
+// calibrated by timing the field's Mul and the leaf hash on this machine
+// so it only gives an order of magnitude, allocations and the channel
+// aren't accounted for.
+func EstimateProvingCost(traceLen, blowup, numConstraints int) ProvingCost {
+
+	if traceLen < 1 || blowup < 1 || numConstraints < 0 {
+		return ProvingCost{}
+	}
+	n, k := uint64(traceLen), uint64(numConstraints)
+	N := n * uint64(blowup)
+	logN := uint64(bits.Len64(N - 1))
+	logn := uint64(bits.Len64(n - 1))
+
+	// NTT interpolation over the trace domain and evaluation over the coset
+	muls := n*logn/2 + N*logN/2 + N
+	// constraint quotients, their combination and the composition evaluation
+	muls += 3*k*n*n + k*n + N*n
+	// the trace and composition trees
+	nodes := 2 * (2*N - 1)
+	layers := uint64(ExpectedFRILayers(N, 2, blowup))
+	opened := 3 * 2 * N
+	for i, size := uint64(1), N/2; i < layers; i, size = i+1, size/2 {
+		// the next layer folds the pairs of the previous one
+		muls += 3*size + n>>i
+		nodes += 2*size - 1
+		// the layer and sibling openings of the previous layer
+		opened += 2 * 2 * (2 * size)
+	}
+	hashes := nodes + numQueries*opened
+
+	mulCost, hashCost := calibrateCost()
+	return ProvingCost{
+		FieldMuls:   muls,
+		Hashes:      hashes,
+		MerkleNodes: nodes,
+		Time:        time.Duration(muls)*mulCost + time.Duration(hashes)*hashCost,
+	}
+}
+
+// calibrateCost times a field multiplication and a leaf hash.
+func calibrateCost() (mul, hash time.Duration) {
+
+	x := PrimeFieldGen
+	start := time.Now()
+	for i := 0; i < costCalibration; i++ {
+		x = PrimeField.Mul(x, PrimeFieldGen)
+	}
+	mul = time.Since(start) / costCalibration
+
+	start = time.Now()
+	for i := 0; i < costCalibration; i++ {
+		LeafHash(x)
+	}
+	hash = time.Since(start) / costCalibration
+	return mul, hash
+}
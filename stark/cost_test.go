@@ -0,0 +1,33 @@
+package stark
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/poly"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateProvingCost(t *testing.T) {
+	params, _ := loadFibonacci(t)
+	traceLen := len(params.SubgroupG)
+	blowup := len(params.EvaluationDomain) / traceLen
+	cost := EstimateProvingCost(traceLen, blowup, FibonacciAIR{}.NumConstraints())
+
+	// the polynomial arithmetic of the stages, constraints included
+	before := poly.MulCount()
+	_, err := Prove(params, ProverConfig{})
+	assert.NoError(t, err)
+	muls := poly.MulCount() - before
+	assert.LessOrEqual(t, cost.FieldMuls, 2*muls)
+	assert.LessOrEqual(t, muls, 2*cost.FieldMuls)
+
+	n := uint64(len(params.EvaluationDomain))
+	assert.Greater(t, cost.MerkleNodes, 4*n)
+	assert.Less(t, cost.MerkleNodes, 6*n)
+	assert.Greater(t, cost.Hashes, cost.MerkleNodes)
+	assert.Positive(t, cost.Time)
+
+	larger := EstimateProvingCost(2*traceLen, blowup, 3)
+	assert.Greater(t, larger.FieldMuls, 3*cost.FieldMuls)
+	assert.Equal(t, ProvingCost{}, EstimateProvingCost(0, blowup, 3))
+}
@@ -0,0 +1,82 @@
+package stark
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+)
+
+// Field elements are packed back to back in a big-endian bit stream using
+// exactly ceil(log2(q)) bits each, the last byte is zero padded.
+// Compared to the fixed width encoding of ByteLen() bytes per element this
+// saves the unused high bits of every element, i.e nothing when the bit
+// length of q - 1 is a multiple of 8 : the elements of PrimeField
+// (q = 3221225473) need 32 bits and pack to 4 bytes as ByteLen() does.
+
+var (
+	errPackedLength = errors.New("packed field elements have the wrong length")
+	errPackedRange  = errors.New("packed field element is not reduced")
+)
+
+// elementBits returns the number of bits used to pack an element of ff.
+func elementBits(ff algebra.FiniteField) int {
+	return new(big.Int).Sub(ff.Modulus(), big.NewInt(1)).BitLen()
+}
+
+// PackFieldElements bit-packs the field elements, all the elements must
+// belong to the same field.
+func PackFieldElements(xs []algebra.FieldElement) []byte {
+
+	if len(xs) == 0 {
+		return []byte{}
+	}
+	bits := elementBits(xs[0].Field())
+	out := make([]byte, (len(xs)*bits+7)/8)
+
+	pos := 0
+	for _, x := range xs {
+		n := x.Big()
+		for i := bits - 1; i >= 0; i-- {
+			if n.Bit(i) == 1 {
+				out[pos/8] |= 0x80 >> (pos % 8)
+			}
+			pos++
+		}
+	}
+	return out
+}
+
+// UnpackFieldElements decodes count field elements of ff packed by
+// PackFieldElements.
+func UnpackFieldElements(b []byte, count int, ff algebra.FiniteField) ([]algebra.FieldElement, error) {
+
+	bits := elementBits(ff)
+	if count < 0 || len(b) != (count*bits+7)/8 {
+		return nil, errPackedLength
+	}
+	xs := make([]algebra.FieldElement, count)
+
+	pos := 0
+	for i := range xs {
+		n := new(big.Int)
+		for j := 0; j < bits; j++ {
+			n.Lsh(n, 1)
+			if b[pos/8]&(0x80>>(pos%8)) != 0 {
+				n.SetBit(n, 0, 1)
+			}
+			pos++
+		}
+		if n.Cmp(ff.Modulus()) >= 0 {
+			return nil, errPackedRange
+		}
+		xs[i] = ff.NewFieldElement(n)
+	}
+	// the padding bits must be zero for the encoding to be canonical
+	for ; pos < len(b)*8; pos++ {
+		if b[pos/8]&(0x80>>(pos%8)) != 0 {
+			return nil, errPackedLength
+		}
+	}
+	return xs, nil
+}
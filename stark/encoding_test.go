@@ -0,0 +1,53 @@
+package stark
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackFieldElements(t *testing.T) {
+	layer := GenElems(PrimeFieldGen, 1000)
+
+	packed := PackFieldElements(layer)
+	unpacked, err := UnpackFieldElements(packed, len(layer), PrimeField)
+	assert.NoError(t, err)
+	assert.Len(t, unpacked, len(layer))
+	for i := range layer {
+		assert.True(t, layer[i].Equal(unpacked[i]))
+	}
+
+	// the elements of PrimeField need 32 bits, packing saves nothing over
+	// the fixed width encoding
+	assert.Len(t, packed, len(layer)*PrimeField.ByteLen())
+
+	// the elements of F_65537 need 17 bits instead of 3 bytes
+	small, _ := algebra.NewFiniteField(big.NewInt(65537))
+	smallLayer := make([]algebra.FieldElement, 1000)
+	for i := range smallLayer {
+		smallLayer[i] = small.NewFieldElementFromInt64(int64(i * 65))
+	}
+	smallPacked := PackFieldElements(smallLayer)
+	assert.Len(t, smallPacked, (len(smallLayer)*17+7)/8)
+	assert.Less(t, len(smallPacked)*100, len(smallLayer)*small.ByteLen()*75)
+	unpacked, err = UnpackFieldElements(smallPacked, len(smallLayer), small)
+	assert.NoError(t, err)
+	assert.True(t, smallLayer[999].Equal(unpacked[999]))
+
+	// odd counts are zero padded
+	three := layer[:3]
+	unpacked, err = UnpackFieldElements(PackFieldElements(three), 3, PrimeField)
+	assert.NoError(t, err)
+	assert.True(t, three[2].Equal(unpacked[2]))
+
+	_, err = UnpackFieldElements(packed, len(layer)+1, PrimeField)
+	assert.Error(t, err)
+	_, err = UnpackFieldElements([]byte{0xff, 0xff, 0xff, 0xff}, 1, PrimeField)
+	assert.Error(t, err)
+
+	empty, err := UnpackFieldElements(PackFieldElements([]algebra.FieldElement{}), 0, PrimeField)
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+}
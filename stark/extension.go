@@ -0,0 +1,68 @@
+package stark
+
+import (
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/merkle"
+)
+
+// When the FRI challenges are drawn from the quadratic extension the
+// layers following the first fold hold extension elements while the FRI
+// domains stay in the base field. The leaf of an extension element is the
+// fixed width encoding of its coordinates A then B (see
+// ExtensionElement.Bytes), unlike the base field leaves whose minimal
+// encoding would make the boundary between the coordinates ambiguous.
+
+// ExtensionBytes returns the leaves committing to the extension elements.
+func ExtensionBytes(elems []algebra.ExtensionElement) [][]byte {
+
+	leaves := make([][]byte, len(elems))
+	for i, e := range elems {
+		leaves[i] = e.Bytes()
+	}
+	return leaves
+}
+
+// DomainHashExt returns a merkle root of the extension elements.
+func DomainHashExt(elems []algebra.ExtensionElement) []byte {
+	return merkle.Root(ExtensionBytes(elems))
+}
+
+// LeafHashExt returns the hash of the merkle leaf committing to e as used
+// by DomainHashExt.
+func LeafHashExt(e algebra.ExtensionElement) []byte {
+	return merkle.LeafHash(e.Bytes())
+}
+
+// VerifyMerkleProofExt checks that the leaf of e hashes up to the root
+// trough the audit path, see VerifyMerkleProof.
+func VerifyMerkleProofExt(root []byte, e algebra.ExtensionElement, path []merkle.AuditSiblings) bool {
+	return merkle.VerifyArity(root, e.Bytes(), path)
+}
+
+// FoldLayerExt folds a layer of extension elements over its base field
+// domain with an extension challenge as FoldLayer does, the inverse of 2x
+// stays in the base field so only the product by beta is an extension
+// multiplication.
+func FoldLayerExt(layer []algebra.ExtensionElement, domain []algebra.FieldElement, beta algebra.ExtensionElement, ext algebra.ExtensionField) ([]algebra.ExtensionElement, error) {
+
+	n := len(layer)
+	if n < 2 || n&(n-1) != 0 || len(domain) != n {
+		return nil, errFoldLayerSize
+	}
+	if ext.Base().Modulus().Cmp(domain[0].Field().Modulus()) != 0 {
+		return nil, errModulusMismatch
+	}
+	half := n / 2
+	inverses := FoldInverses(domain)
+	next := make([]algebra.ExtensionElement, half)
+	for i := 0; i < half; i++ {
+		if !domain[i+half].Equal(domain[i].Neg()) {
+			return nil, errFoldDomain
+		}
+		a, b, inv := layer[i], layer[i+half], inverses[i]
+		even := ext.MulBase(ext.Add(a, b), ext.Base().Mul(domain[i], inv))
+		odd := ext.MulBase(ext.Sub(a, b), inv)
+		next[i] = ext.Add(even, ext.Mul(beta, odd))
+	}
+	return next, nil
+}
@@ -0,0 +1,78 @@
+package stark
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/merkle"
+	"github.com/stretchr/testify/assert"
+)
+
+func testExtension(t *testing.T) algebra.ExtensionField {
+	t.Helper()
+	ext, err := algebra.NewExtensionField(PrimeField, PrimeFieldGen)
+	assert.NoError(t, err)
+	return ext
+}
+
+func TestDomainHashExt(t *testing.T) {
+	ext := testExtension(t)
+	elems := make([]algebra.ExtensionElement, 6)
+	for i := range elems {
+		elems[i] = ext.NewElement(PrimeField.NewFieldElementFromInt64(int64(i)), PrimeField.NewFieldElementFromInt64(int64(3141592*i+1)))
+	}
+	root := DomainHashExt(elems)
+
+	leaves := ExtensionBytes(elems)
+	for i, e := range elems {
+		path, err := merkle.ProofArity(leaves, i, 2)
+		assert.NoError(t, err)
+		assert.True(t, VerifyMerkleProofExt(root, e, path))
+		assert.False(t, VerifyMerkleProofExt(root, ext.Add(e, ext.One()), path))
+	}
+	assert.Equal(t, merkle.LeafHash(leaves[2]), LeafHashExt(elems[2]))
+
+	// swapping the coordinates changes the commitment
+	swapped := append([]algebra.ExtensionElement{}, elems...)
+	swapped[1] = ext.NewElement(elems[1].B, elems[1].A)
+	assert.NotEqual(t, root, DomainHashExt(swapped))
+}
+
+func TestFoldLayerExt(t *testing.T) {
+	ext := testExtension(t)
+	m := PrimeField.Modulus()
+	domain := GenElems(subgroupGenerator(8), 8)
+	layer := make([]algebra.FieldElement, len(domain))
+	for i := range layer {
+		layer[i] = PrimeField.NewFieldElementFromInt64(int64(7*i + 3))
+	}
+	beta := PrimeField.NewFieldElementFromInt64(31337)
+
+	// over embedded base elements the fold matches FoldLayer
+	embedded := make([]algebra.ExtensionElement, len(layer))
+	for i, v := range layer {
+		embedded[i] = ext.Embed(v)
+	}
+	expected, err := FoldLayer(layer, domain, beta, m)
+	assert.NoError(t, err)
+	next, err := FoldLayerExt(embedded, domain, ext.Embed(beta), ext)
+	assert.NoError(t, err)
+	for i := range next {
+		assert.True(t, next[i].Equal(ext.Embed(expected[i])))
+	}
+
+	// the fold is linear in the layer so it folds each coordinate of
+	// u.layer with the base challenge
+	shifted := make([]algebra.ExtensionElement, len(layer))
+	for i, v := range layer {
+		shifted[i] = ext.NewElement(PrimeField.Zero(), v)
+	}
+	next, err = FoldLayerExt(shifted, domain, ext.Embed(beta), ext)
+	assert.NoError(t, err)
+	for i := range next {
+		assert.True(t, next[i].Equal(ext.NewElement(PrimeField.Zero(), expected[i])))
+	}
+
+	_, err = FoldLayerExt(embedded[:3], domain[:3], ext.Embed(beta), ext)
+	assert.ErrorIs(t, err, errFoldLayerSize)
+}
@@ -0,0 +1,110 @@
+package stark
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+)
+
+// The Fibonacci domain parameters and constraints are expensive to build
+// so they're computed once and shared by the tests.
+var (
+	fibOnce        sync.Once
+	fibParams      *DomainParameters
+	fibConstraints []poly.Polynomial
+	fibErr         error
+)
+
+// loadFibonacci returns the domain parameters of domainparams.json, built
+// by DefaultFibonacciParameters, and the constraint quotients of the
+// Fibonacci program.
+func loadFibonacci(t testing.TB) (*DomainParameters, []poly.Polynomial) {
+	t.Helper()
+
+	fibOnce.Do(func() {
+		if fibParams, fibErr = DefaultFibonacciParameters(); fibErr != nil {
+			return
+		}
+		c1, c2, c3 := GenerateProgramConstraints(fibParams.Polynomial.Clone(0), fibParams.GeneratorG)
+		fibConstraints = []poly.Polynomial{c1, c2, c3}
+	})
+	if fibErr != nil {
+		t.Fatal("failed to load domain params with error :", fibErr)
+	}
+	return fibParams, fibConstraints
+}
+
+var (
+	fibProofOnce sync.Once
+	fibProof     StarkProof
+	fibProofErr  error
+)
+
+// loadFibonacciProof returns a proof of the Fibonacci program generated
+// with the default prover config.
+func loadFibonacciProof(t testing.TB) (*DomainParameters, StarkProof) {
+	t.Helper()
+
+	params, constraints := loadFibonacci(t)
+	fibProofOnce.Do(func() {
+		var state *ProverState
+		if state, fibProofErr = ProveAIR(params, FibonacciAIR{}, constraints, ProverConfig{}); fibProofErr != nil {
+			return
+		}
+		fibProof, fibProofErr = state.Proof()
+	})
+	if fibProofErr != nil {
+		t.Fatal("failed to generate the proof with error :", fibProofErr)
+	}
+	return params, fibProof
+}
+
+// fibComposition draws the composition coefficients from ch and returns
+// the composition polynomial, its evaluations over the evaluation domain
+// and their merkle root.
+func fibComposition(t testing.TB, ch *Channel) (poly.Polynomial, []algebra.FieldElement, []byte) {
+	t.Helper()
+
+	params, constraints := loadFibonacci(t)
+
+	cp := CompositionPolynomial(constraints, ch, ProverConfig{})
+	evals := evalComposition(cp, params.EvaluationDomain)
+	return cp, evals, DomainHash(evals)
+}
+
+// smallFRI runs the FRI commitment of a degree 15 polynomial over a coset
+// of the subgroup of order 128, it is cheap enough for unit tests.
+type smallFRI struct {
+	poly    poly.Polynomial
+	domain  []algebra.FieldElement
+	evals   []algebra.FieldElement
+	channel *Channel
+	domains [][]algebra.FieldElement
+	polys   []poly.Polynomial
+	layers  [][]algebra.FieldElement
+	roots   [][]byte
+}
+
+func newSmallFRI(t testing.TB) *smallFRI {
+	t.Helper()
+
+	w := PrimeFieldGen.Exp(algebra.FromInt64(3221225472 / 128))
+	domain := GenElems(w, 128)
+	for i := range domain {
+		domain[i] = PrimeField.Mul(PrimeFieldGen, domain[i])
+	}
+	p := poly.NewPolynomialInts(3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5, 8, 9, 7, 9, 3)
+	evals := evalComposition(p, domain)
+	root := DomainHash(evals)
+
+	ch := NewChannel()
+	ch.Send(root)
+	domains, polys, layers, roots, err := GenerateFRICommitment(p, domain, evals, root, ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &smallFRI{p, domain, evals, ch, domains, polys, layers, roots}
+}
@@ -1,7 +1,12 @@
 package stark
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math/big"
+	"math/bits"
+	"runtime"
 	"github.com/ayushn2/go-stark.git/algebra"
 	"github.com/ayushn2/go-stark.git/poly"
 	"github.com/ayushn2/go-stark.git/merkle"
@@ -73,6 +78,12 @@ func oddCoeffs(p poly.Polynomial) poly.Polynomial {
 
 // NextFRIPolynomial creates the next FRI polynomial.
 func NextFRIPolynomial(p poly.Polynomial, beta algebra.FieldElement) poly.Polynomial {
+	return nextFRIPolynomial(p, beta, true)
+}
+
+// nextFRIPolynomial creates the next FRI polynomial, trim releases the
+// capacity of the intermediate polynomials.
+func nextFRIPolynomial(p poly.Polynomial, beta algebra.FieldElement, trim bool) poly.Polynomial {
 
 	field := beta.Field()
 	oddCoefficients := oddCoeffs(p)
@@ -83,7 +94,10 @@ func NextFRIPolynomial(p poly.Polynomial, beta algebra.FieldElement) poly.Polyno
 
 	nextFRIPoly := scaledCoeffs.Add(evenCoefficients, field.Modulus())
 
-	return nextFRIPoly
+	if !trim {
+		return nextFRIPoly
+	}
+	return nextFRIPoly.Trim()
 
 }
 
@@ -92,25 +106,144 @@ func NextFRIPolynomial(p poly.Polynomial, beta algebra.FieldElement) poly.Polyno
 // to create the next fri layer we evaluate the FRI-polynomial over the FRI-domain
 func NextFRILayer(domain []algebra.FieldElement, p poly.Polynomial, beta algebra.FieldElement) ([]algebra.FieldElement, poly.Polynomial, []algebra.FieldElement) {
 
-	domainEval := func(p poly.Polynomial, domain []algebra.FieldElement) []algebra.FieldElement {
-
-		field := PrimeField
-		evals := make([]algebra.FieldElement, len(domain))
-
-		for idx, elem := range domain {
-			eval := p.Eval(elem.Big(), elem.Field().Modulus())
-			evals[idx] = field.NewFieldElement(eval)
-		}
-		return evals
-	}
 	nextFRIDomain := NextFRIDomain(domain)
 	nextFRIPoly := NextFRIPolynomial(p, beta)
-	nextLayer := domainEval(nextFRIPoly, nextFRIDomain)
+	nextLayer := evalDomain(nextFRIPoly, nextFRIDomain, nil)
 
 	return nextFRIDomain, nextFRIPoly, nextLayer
 }
 
+// The next FRI layer doesn't need the polynomial, writing p(x) = E(x^2) + x.O(x^2)
+// the next polynomial is E + beta.O and the evaluations at x and -x give
+// E(x^2) = (p(x) + p(-x))/2
+// O(x^2) = (p(x) - p(-x))/2x
+// The FRI domains are cosets of subgroups of even order so -x = domain[i + n/2]
+// for x = domain[i] and the ith element of the next layer only depends on
+// the elements i and i + n/2 of the layer.
+
+var (
+	errFoldLayerSize = errors.New("FRI layer size must be an even power of two matching its domain")
+	errFoldDomain    = errors.New("FRI domain isn't symmetric, domain[i+n/2] must be -domain[i]")
+	errFoldInverses  = errors.New("FRI fold inverse table must hold half the domain")
+)
+
+// foldPair returns the evaluation of the next FRI polynomial at x^2 given
+// a = p(x) and b = p(-x).
+func foldPair(a, b, x, beta algebra.FieldElement) algebra.FieldElement {
+
+	field := x.Field()
+	two := field.NewFieldElementFromInt64(2)
+	even := field.Div(field.Add(a, b), two)
+	odd := field.Div(field.Sub(a, b), field.Mul(two, x))
+	return field.Add(even, field.Mul(beta, odd))
+}
+
+// foldPairInv is foldPair given inv = (2x)^-1 rather than x, 1/2 = x.inv.
+func foldPairInv(a, b, x, inv, beta algebra.FieldElement) algebra.FieldElement {
+
+	field := x.Field()
+	even := field.Mul(field.Add(a, b), field.Mul(x, inv))
+	odd := field.Mul(field.Sub(a, b), inv)
+	return field.Add(even, field.Mul(beta, odd))
+}
+
+// foldPairArena is foldPairInv, or foldPair when inv is nil, computing the
+// intermediate values with the arena, the result is detached from it.
+func foldPairArena(a, b, x algebra.FieldElement, inv *algebra.FieldElement, beta algebra.FieldElement, arena *algebra.FieldArena) algebra.FieldElement {
+
+	var even, odd algebra.FieldElement
+	if inv != nil {
+		even = arena.Mul(arena.Add(a, b), arena.Mul(x, *inv))
+		odd = arena.Mul(arena.Sub(a, b), *inv)
+	} else {
+		two := arena.Field().NewFieldElementFromInt64(2)
+		even = arena.Div(arena.Add(a, b), two)
+		odd = arena.Div(arena.Sub(a, b), arena.Mul(two, x))
+	}
+	folded := arena.Detach(arena.Add(even, arena.Mul(beta, odd)))
+	arena.Reset()
+	return folded
+}
+
+// FoldInverses returns the table of (2x)^-1 for the first half of the
+// domain computed with a single inversion (see FiniteField.BatchInv).
+// The next FRI domain holds the squares x^2 so its table is derived
+// without inverting as (2x^2)^-1 = 2.((2x)^-1)^2 (see nextFoldInverses).
+func FoldInverses(domain []algebra.FieldElement) []algebra.FieldElement {
+
+	if len(domain) < 2 {
+		return nil
+	}
+	field := domain[0].Field()
+	doubled := make([]algebra.FieldElement, len(domain)/2)
+	for i := range doubled {
+		doubled[i] = domain[i].Double()
+	}
+	return field.BatchInv(doubled)
+}
+
+// nextFoldInverses returns the inverse table of the next FRI domain.
+func nextFoldInverses(inverses []algebra.FieldElement) []algebra.FieldElement {
+
+	next := make([]algebra.FieldElement, len(inverses)/2)
+	for i := range next {
+		next[i] = inverses[i].Square().Double()
+	}
+	return next
+}
+
+// FoldLayer folds the FRI layer evaluated over domain into the next layer
+// evaluated over the squares of the first half of the domain (see
+// NextFRIDomain) i.e (f(x)+f(-x))/2 + beta.(f(x)-f(-x))/2x.
+func FoldLayer(layer []algebra.FieldElement, domain []algebra.FieldElement, beta algebra.FieldElement, modulus *algebra.Integer) ([]algebra.FieldElement, error) {
+	return FoldLayerInv(layer, domain, nil, beta, modulus)
+}
+
+// FoldLayerInv folds the layer as FoldLayer using the inverse table of the
+// domain (see FoldInverses) instead of inverting 2x for each pair, a nil
+// table falls back to FoldLayer.
+func FoldLayerInv(layer []algebra.FieldElement, domain []algebra.FieldElement, inverses []algebra.FieldElement, beta algebra.FieldElement, modulus *algebra.Integer) ([]algebra.FieldElement, error) {
+	return foldLayer(layer, domain, inverses, beta, modulus, nil)
+}
+
+// foldLayer folds the layer as FoldLayerInv computing each pair with the
+// arena when one is given.
+func foldLayer(layer []algebra.FieldElement, domain []algebra.FieldElement, inverses []algebra.FieldElement, beta algebra.FieldElement, modulus *algebra.Integer, arena *algebra.FieldArena) ([]algebra.FieldElement, error) {
+
+	n := len(layer)
+	if n < 2 || n&(n-1) != 0 || len(domain) != n {
+		return nil, errFoldLayerSize
+	}
+	if beta.Field().Modulus() == nil || beta.Field().Modulus().Cmp(modulus) != 0 {
+		return nil, errModulusMismatch
+	}
+	half := n / 2
+	if inverses != nil && len(inverses) != half {
+		return nil, errFoldInverses
+	}
+	next := make([]algebra.FieldElement, half)
+	for i := 0; i < half; i++ {
+		if !domain[i+half].Equal(domain[i].Neg()) {
+			return nil, errFoldDomain
+		}
+		if arena != nil {
+			var inv *algebra.FieldElement
+			if inverses != nil {
+				inv = &inverses[i]
+			}
+			next[i] = foldPairArena(layer[i], layer[i+half], domain[i], inv, beta, arena)
+		} else if inverses != nil {
+			next[i] = foldPairInv(layer[i], layer[i+half], domain[i], inverses[i], beta)
+		} else {
+			next[i] = foldPair(layer[i], layer[i+half], domain[i], beta)
+		}
+	}
+	return next, nil
+}
+
 // DomainHash returns a merkle root of the domain elements
+// the leaves are the minimal big-endian encodings of the elements
+// i.e Big().Bytes() without zero padding.
 func DomainHash(domain []algebra.FieldElement) []byte {
 
 	domainBytes := make([][]byte, len(domain))
@@ -122,6 +255,67 @@ func DomainHash(domain []algebra.FieldElement) []byte {
 	return merkle.Root(domainBytes)
 }
 
+// The commitments of the package are in natural order : the ith leaf is
+// the evaluation at the ith point of the domain i.e offset.h^i for the
+// evaluation domain and the query at index i opens that leaf.
+// NTT implementations commonly output the evaluations in bit-reversed
+// order i.e the ith value is the evaluation at offset.h^rev(i) where rev
+// reverses the log2(n) lowest bits of i, such outputs are committed with
+// DomainHashBitReversed.
+
+// BitReversePermute returns xs with the element at index i moved to the
+// index whose log2(len(xs)) lowest bits are those of i reversed, the
+// permutation is its own inverse.
+// It panics if len(xs) isn't a power of two.
+func BitReversePermute(xs []algebra.FieldElement) []algebra.FieldElement {
+
+	n := len(xs)
+	if n == 0 || n&(n-1) != 0 {
+		panic(fmt.Sprintf("bit reversal of %d elements, must be a power of two", n))
+	}
+	logN := bits.TrailingZeros(uint(n))
+	permuted := make([]algebra.FieldElement, n)
+	for i, x := range xs {
+		permuted[bits.Reverse(uint(i))>>(bits.UintSize-logN)] = x
+	}
+	return permuted
+}
+
+// DomainHashBitReversed returns the natural order commitment of DomainHash
+// given the evaluations in bit-reversed order, so that
+// DomainHashBitReversed(BitReversePermute(evals)) == DomainHash(evals).
+func DomainHashBitReversed(evals []algebra.FieldElement) []byte {
+	return DomainHash(BitReversePermute(evals))
+}
+
+// LeafHash returns the hash of the merkle leaf committing to fe as used
+// by DomainHash i.e SHA3-256(0x00 || fe.Big().Bytes()), the authentication
+// paths of the package are verified from this hash so custom commitments
+// must hash their leaves the same way to stay compatible.
+func LeafHash(fe algebra.FieldElement) []byte {
+	return merkle.LeafHash(fe.Big().Bytes())
+}
+
+// DomainHashParallel returns the same merkle root as DomainHash, the
+// leaves and the subtrees are hashed across runtime.NumCPU() goroutines.
+func DomainHashParallel(domain []algebra.FieldElement) []byte {
+	return merkle.RootParallel(DomainBytes(domain), runtime.NumCPU())
+}
+
+// DomainHashLE returns a merkle root of the domain elements using their
+// fixed width little-endian encodings as leaves, for interoperability
+// with implementations using little-endian limbs.
+func DomainHashLE(domain []algebra.FieldElement) []byte {
+
+	domainBytes := make([][]byte, len(domain))
+
+	for idx, elem := range domain {
+		domainBytes[idx] = elem.Field().BytesLE(elem)
+	}
+
+	return merkle.Root(domainBytes)
+}
+
 // DomainBytes returns a byte serialized domain element set
 func DomainBytes(domain []algebra.FieldElement) [][]byte {
 
@@ -144,14 +338,15 @@ func cosetDomainBytes(domain []*big.Int) [][]byte {
 	return domainBytes
 }
 
-// serializeAuditPath serializes a merkle audithash
+// serializeAuditPath serializes a merkle audithash, each hash is followed
+// by a byte set to 1 when it is the right operator.
 func serializeAuditPath(ap []merkle.AuditHash) []byte {
 	var auditPath = make([]byte, 0)
 
 	for _, path := range ap {
 
 		var b = make([]byte, 0, 33)
-		copy(b, path.Val)
+		b = append(b, path.Val...)
 		if path.RightOperator {
 			b = append(b, 1)
 		} else {
@@ -166,7 +361,27 @@ func serializeAuditPath(ap []merkle.AuditHash) []byte {
 // GenerateFRICommitment given the composition polynomial
 // the evaluation domain, the evaluations on said domain and
 // the first commitment root.
-func GenerateFRICommitment(compositionPoly poly.Polynomial, domain []algebra.FieldElement, compositionEvals []algebra.FieldElement, compositionRoot []byte, fs Channel) ([][]algebra.FieldElement, []poly.Polynomial, [][]algebra.FieldElement, [][]byte) {
+// The FRI roots and the last layer constant are sent trough the channel
+// so the caller's transcript reflects the whole commitment phase.
+// Each layer is folded from the previous one (see FoldLayer), the FRI
+// polynomials are folded alongside to detect the last layer.
+// Evaluations that can't be folded over the domain are reported as an
+// error.
+func GenerateFRICommitment(compositionPoly poly.Polynomial, domain []algebra.FieldElement, compositionEvals []algebra.FieldElement, compositionRoot []byte, ch *Channel) ([][]algebra.FieldElement, []poly.Polynomial, [][]algebra.FieldElement, [][]byte, error) {
+	return GenerateFRICommitmentInv(compositionPoly, domain, compositionEvals, compositionRoot, nil, ch)
+}
+
+// GenerateFRICommitmentInv is GenerateFRICommitment folding the layers
+// with the inverse table of the first domain (see FoldInverses), the table
+// of each next domain is derived from the previous one. A nil table
+// inverts 2x at each fold, the commitment is the same.
+func GenerateFRICommitmentInv(compositionPoly poly.Polynomial, domain []algebra.FieldElement, compositionEvals []algebra.FieldElement, compositionRoot []byte, inverses []algebra.FieldElement, ch *Channel) ([][]algebra.FieldElement, []poly.Polynomial, [][]algebra.FieldElement, [][]byte, error) {
+	return generateFRICommitment(compositionPoly, domain, compositionEvals, compositionRoot, inverses, ch, ProverConfig{})
+}
+
+// generateFRICommitment commits to the FRI layers, folding them with an
+// arena when the config enables it.
+func generateFRICommitment(compositionPoly poly.Polynomial, domain []algebra.FieldElement, compositionEvals []algebra.FieldElement, compositionRoot []byte, inverses []algebra.FieldElement, ch *Channel, cfg ProverConfig) ([][]algebra.FieldElement, []poly.Polynomial, [][]algebra.FieldElement, [][]byte, error) {
 
 	FRIPolynomials := []poly.Polynomial{compositionPoly}
 	FRIDomains := [][]algebra.FieldElement{domain}
@@ -175,12 +390,21 @@ func GenerateFRICommitment(compositionPoly poly.Polynomial, domain []algebra.Fie
 
 	iter := FRIPolynomials[len(FRIPolynomials)-1]
 	field := PrimeField
+	arena := cfg.arena()
 
-	for iter.Degree() > 0 {
+	for !iter.IsConstant() {
 
-		beta := field.NewFieldElement(fs.RandFE(PrimeField.Modulus()))
+		beta := field.NewFieldElement(ch.RandFE(PrimeField.Modulus()))
 
-		nextFRIDomain, nextFRIPoly, nextFRILayer := NextFRILayer(FRIDomains[len(FRIDomains)-1], FRIPolynomials[len(FRIPolynomials)-1], beta)
+		nextFRIDomain := NextFRIDomain(FRIDomains[len(FRIDomains)-1])
+		nextFRIPoly := nextFRIPolynomial(FRIPolynomials[len(FRIPolynomials)-1], beta, !cfg.keepFRICapacity)
+		nextFRILayer, err := foldLayer(FRILayers[len(FRILayers)-1], FRIDomains[len(FRIDomains)-1], inverses, beta, field.Modulus(), arena)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		if inverses != nil {
+			inverses = nextFoldInverses(inverses)
+		}
 
 		root := DomainHash(nextFRILayer)
 
@@ -189,14 +413,77 @@ func GenerateFRICommitment(compositionPoly poly.Polynomial, domain []algebra.Fie
 		FRILayers = append(FRILayers, nextFRILayer)
 		FRIMerkleRoots = append(FRIMerkleRoots, root)
 
-		fs.Send(FRIMerkleRoots[len(FRIMerkleRoots)-1])
+		ch.Send(FRIMerkleRoots[len(FRIMerkleRoots)-1])
 
 		iter = FRIPolynomials[len(FRIPolynomials)-1]
 
 	}
-	fs.Send(FRIPolynomials[len(FRIPolynomials)-1][0].Bytes())
+	ch.Send(FRIPolynomials[len(FRIPolynomials)-1][0].Bytes())
+
+	return FRIDomains, FRIPolynomials, FRILayers, FRIMerkleRoots, nil
+}
+
+// ExpectedFRILayers returns the number of FRI layers, including the first
+// one, committed over an evaluation domain of domainSize points when each
+// layer folds its domain by foldingFactor until it holds at most
+// lastLayerBound points. The last polynomial is constant once its domain
+// shrinks to the blowup so GenerateFRICommitment with a blowup of 8 and a
+// folding factor of 2 commits to ExpectedFRILayers(size, 2, 8) layers.
+// It panics if foldingFactor < 2 or lastLayerBound < 1.
+func ExpectedFRILayers(domainSize uint64, foldingFactor, lastLayerBound int) int {
+
+	if foldingFactor < 2 || lastLayerBound < 1 {
+		panic(fmt.Sprintf("invalid FRI folding factor %d or last layer bound %d", foldingFactor, lastLayerBound))
+	}
+	layers := 1
+	for size := domainSize; size > uint64(lastLayerBound); layers++ {
+		size = (size + uint64(foldingFactor) - 1) / uint64(foldingFactor)
+	}
+	return layers
+}
+
+// ExpectedLastLayerConstant folds p with the FRI challenges betas, one per
+// round folding by foldingFactor, and returns the constant the last FRI
+// layer collapses to. Writing p(x) = Sum(c_j.x^j) the constant is
+// Sum(c_j.b_j) where b_j multiplies the challenges selected by the digits
+// of j, so it only depends on p and the betas drawn from the transcript.
+// A round folding by 2^r folds r times by 2 with beta, beta^2, ..., as
+// FoldLayerBy does. An invalid folding factor or betas that don't fold p
+// down to a constant are reported as an error.
+func ExpectedLastLayerConstant(p poly.Polynomial, foldingFactor int, betas []algebra.FieldElement) (algebra.FieldElement, error) {
+
+	if foldingFactor < 2 || foldingFactor&(foldingFactor-1) != 0 {
+		return algebra.FieldElement{}, errFoldingFactor
+	}
+	for _, beta := range betas {
+		for k := foldingFactor; k > 1; k /= 2 {
+			p, beta = NextFRIPolynomial(p, beta), beta.Square()
+		}
+	}
+	if !p.IsConstant() {
+		return algebra.FieldElement{}, errFRISchedule
+	}
+	if len(p) == 0 {
+		return PrimeField.Zero(), nil
+	}
+	return PrimeField.NewFieldElement(p[0]), nil
+}
 
-	return FRIDomains, FRIPolynomials, FRILayers, FRIMerkleRoots
+// IsConstantLayer checks that every element of the FRI layer is the same
+// and returns that element, the last FRI layer must be constant since the
+// last FRI polynomial is.
+// An empty layer isn't constant.
+func IsConstantLayer(layer []algebra.FieldElement) (algebra.FieldElement, bool) {
+
+	if len(layer) == 0 {
+		return algebra.FieldElement{}, false
+	}
+	for _, elem := range layer[1:] {
+		if !elem.Equal(layer[0]) {
+			return algebra.FieldElement{}, false
+		}
+	}
+	return layer[0], true
 }
 
 // In order to verify the commitment proofs we need to implement to new functions
@@ -213,7 +500,11 @@ func GenerateFRICommitment(compositionPoly poly.Polynomial, domain []algebra.Fie
 // - Sibling Element on the fri-layer if the element is cp_i(x) it's sibling
 // is cp_i(-x)
 // - The merkle proof of the sibling.
-func DecommitFRILayers(index int, channel *Channel, friLayers [][]algebra.FieldElement) {
+// The opened elements and their authentication paths are also recorded
+// in the returned FRIQuery.
+func DecommitFRILayers(index int, channel *Channel, friLayers [][]algebra.FieldElement) (FRIQuery, error) {
+
+	query := FRIQuery{Index: index}
 
 	for i := 0; i < len(friLayers)-1; i++ {
 		layer := friLayers[i]
@@ -225,12 +516,12 @@ func DecommitFRILayers(index int, channel *Channel, friLayers [][]algebra.FieldE
 		elemProof, err := merkle.Proof(DomainBytes(layer), index)
 
 		if err != nil {
-			panic(err)
+			return FRIQuery{}, err
 		}
 		siblingBytes := layer[siblingIndex].Big().Bytes()
 		siblingProof, err := merkle.Proof(DomainBytes(layer), siblingIndex)
 		if err != nil {
-			panic(err)
+			return FRIQuery{}, err
 		}
 		elemProofBytes := serializeAuditPath(elemProof)
 		siblingProofBytes := serializeAuditPath(siblingProof)
@@ -240,9 +531,13 @@ func DecommitFRILayers(index int, channel *Channel, friLayers [][]algebra.FieldE
 		channel.Send(siblingBytes)
 		channel.Send(siblingProofBytes)
 
+		query.Layers = append(query.Layers, FRILayerOpening{index, layer[index], auditPathHashes(elemProof)})
+		query.Siblings = append(query.Siblings, FRILayerOpening{siblingIndex, layer[siblingIndex], auditPathHashes(siblingProof)})
 	}
 	// Send the last layer element
 	channel.Send(friLayers[len(friLayers)-1][0].Big().Bytes())
+
+	return query, nil
 }
 
 // Decommiting on the trace polynomial involves verifying the evaluation
@@ -255,57 +550,106 @@ func DecommitFRILayers(index int, channel *Channel, friLayers [][]algebra.FieldE
 
 // DecommitOnQuery takes an index, a channel, coset evaluations and sends
 // the evaluations and their proofs at the given index
-func DecommitOnQuery(index int, channel *Channel, cosetEval []*big.Int, friLayers [][]algebra.FieldElement) {
+func DecommitOnQuery(index int, channel *Channel, cosetEval []*big.Int, friLayers [][]algebra.FieldElement) (FRIQuery, error) {
 
-	if index+16 > len(cosetEval) {
-		panic("coset eval index out of range")
+	if index < 0 || index >= len(cosetEval) {
+		return FRIQuery{}, fmt.Errorf("index %d is out of the evaluation domain range [0, %d)", index, len(cosetEval))
 	}
 
 	cosetBytes := cosetDomainBytes(cosetEval)
+	// the evaluation domain is cyclic, g.x and g^2.x wrap around
+	indices := []int{index, (index + 8) % len(cosetEval), (index + 16) % len(cosetEval)}
 
-	firstEvalBytes := cosetBytes[index]
-	firstEvalAP, err := merkle.Proof(cosetBytes, index)
-	if err != nil {
-		panic(err)
-	}
-	firstEvalAPSerialized := serializeAuditPath(firstEvalAP)
-
-	channel.Send(firstEvalBytes)
-	channel.Send(firstEvalAPSerialized)
+	trace := make([]FRILayerOpening, 0, len(indices))
+	for _, i := range indices {
+		evalAP, err := merkle.Proof(cosetBytes, i)
+		if err != nil {
+			return FRIQuery{}, err
+		}
+		channel.Send(cosetBytes[i])
+		channel.Send(serializeAuditPath(evalAP))
 
-	secondEvalBytes := cosetBytes[index+8]
-	secondEvalAP, err := merkle.Proof(cosetBytes, index+8)
-	if err != nil {
-		panic(err)
+		trace = append(trace, FRILayerOpening{i, PrimeField.NewFieldElement(cosetEval[i]), auditPathHashes(evalAP)})
 	}
-	secondEvalAPSerialized := serializeAuditPath(secondEvalAP)
 
-	channel.Send(secondEvalBytes)
-	channel.Send(secondEvalAPSerialized)
-
-	thirdEvalBytes := cosetBytes[index+16]
-	thirdEvalAP, err := merkle.Proof(cosetBytes, index+16)
+	query, err := DecommitFRILayers(index, channel, friLayers)
 	if err != nil {
-		panic(err)
+		return FRIQuery{}, err
 	}
-	thirdEvalAPSerialized := serializeAuditPath(thirdEvalAP)
+	query.Trace = trace
+	return query, nil
+}
 
-	channel.Send(thirdEvalBytes)
-	channel.Send(thirdEvalAPSerialized)
+// numQueries is the number of queries drawn by FRIDecommit.
+const numQueries = 3
 
-	DecommitFRILayers(index, channel, friLayers)
+// FRIDecommit receives random values from the verifier (using FS)
+// and decommits on each query index, the FRI layers openings are returned.
+// The query indices are distinct, an index drawn twice is redrawn.
+func FRIDecommit(channel *Channel, cosetEval []*big.Int, friLayers [][]algebra.FieldElement) ([]FRIQuery, error) {
+	return FRIDecommitContext(context.Background(), channel, cosetEval, friLayers)
 }
 
-// FRIDecommit receives random values from the verifier (using FS)
-// and decommits on each query index.
-func FRIDecommit(channel *Channel, cosetEval []*big.Int, friLayers [][]algebra.FieldElement) {
+// FRIDecommitContext decommits like FRIDecommit but checks ctx before each
+// query, on cancellation it returns the queries opened so far along with
+// the context error. A query is always sent trough the channel entirely so
+// the channel holds the transcript of the returned queries.
+func FRIDecommitContext(ctx context.Context, channel *Channel, cosetEval []*big.Int, friLayers [][]algebra.FieldElement) ([]FRIQuery, error) {
+	return decommitQueries(ctx, channel, cosetEval, friLayers, drawQueryIndices(channel, len(cosetEval)))
+}
+
+// drawQueryIndices draws numQueries distinct indices in [0, n) from the
+// channel, or n indices when the domain is smaller.
+func drawQueryIndices(channel *Channel, n int) []int {
 
 	lb := big.NewInt(0)
-	ub := big.NewInt(8196 - 16)
+	ub := big.NewInt(int64(n - 1))
+
+	var indices []int
+	drawn := make(map[int]bool)
 
-	for i := 0; i < 3; i++ {
-		randIdx := channel.RandInt(lb, ub)
+	for len(indices) < numQueries && len(indices) < n {
+		randIdx := int(channel.RandInt(lb, ub).Int64())
+		if drawn[randIdx] {
+			continue
+		}
+		drawn[randIdx] = true
+		indices = append(indices, randIdx)
+	}
+	return indices
+}
+
+// FRIDecommitQueries decommits on the given query indices, the indices must
+// be distinct and within the evaluation domain [0, len(cosetEval)).
+// Invalid indices are reported before anything is sent trough the channel.
+func FRIDecommitQueries(channel *Channel, cosetEval []*big.Int, friLayers [][]algebra.FieldElement, indices []int) ([]FRIQuery, error) {
+	return decommitQueries(context.Background(), channel, cosetEval, friLayers, indices)
+}
+
+// decommitQueries decommits on the query indices until ctx is done.
+func decommitQueries(ctx context.Context, channel *Channel, cosetEval []*big.Int, friLayers [][]algebra.FieldElement, indices []int) ([]FRIQuery, error) {
+
+	seen := make(map[int]int, len(indices))
+	for i, idx := range indices {
+		if idx < 0 || idx >= len(cosetEval) {
+			return nil, fmt.Errorf("query %d : index %d is out of the evaluation domain range [0, %d)", i, idx, len(cosetEval))
+		}
+		if j, ok := seen[idx]; ok {
+			return nil, fmt.Errorf("query %d : index %d duplicates query %d", i, idx, j)
+		}
+		seen[idx] = i
+	}
 
-		DecommitOnQuery(int(randIdx.Int64()), channel, cosetEval, friLayers)
+	queries := make([]FRIQuery, 0, len(indices))
+	for _, idx := range indices {
+		if err := ctx.Err(); err != nil {
+			return queries, err
+		}
+		query, err := DecommitOnQuery(idx, channel, cosetEval, friLayers)
+		if err != nil {
+			return queries, err
+		}
+		queries = append(queries, query)
 	}
-}
\ No newline at end of file
+	return queries, nil
+}
@@ -0,0 +1,422 @@
+package stark
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateFRICommitmentAdvancesChannel(t *testing.T) {
+	params, _ := loadFibonacci(t)
+
+	ch := NewChannel()
+	ch.Send(params.EvaluationRoot)
+	cp, evals, root := fibComposition(t, ch)
+	ch.Send(root)
+
+	before := len(ch.Proof)
+	state := append([]byte{}, ch.State...)
+
+	_, friPolys, _, friRoots, err := GenerateFRICommitment(cp, params.EvaluationDomain, evals, root, ch)
+	assert.NoError(t, err)
+
+	// each folding round draws beta and sends the layer root, then the
+	// last layer constant is sent.
+	folds := len(friRoots) - 1
+	assert.Len(t, ch.Proof, before+2*folds+1)
+	assert.NotEqual(t, state, ch.State)
+	assert.Equal(t, sendOperator+hex.EncodeToString(friRoots[len(friRoots)-1]), ch.Proof[len(ch.Proof)-2])
+	assert.Equal(t, sendOperator+hex.EncodeToString(friPolys[len(friPolys)-1][0].Bytes()), ch.Proof[len(ch.Proof)-1])
+}
+
+// friBenchDegree bounds the degree of the polynomial committed by the FRI
+// trimming benchmarks, its evaluation domain has a blowup of 2.
+const friBenchDegree = 1 << 20
+
+// benchmarkFRITrim commits to the FRI layers of a random polynomial of
+// degree 2^20-1 and reports the peak Alloc sampled during the commitment
+// above the heap of the inputs, with or without trimming the folded
+// polynomials.
+func benchmarkFRITrim(b *testing.B, trim bool) {
+	size := uint64(2 * friBenchDegree)
+	h := subgroupGenerator(size)
+	p := poly.RandomPolynomial(friBenchDegree-1, 31)
+	evals, err := p.EvalCosetNTT(PrimeFieldGen, h, size, PrimeField.Modulus())
+	if err != nil {
+		b.Fatal(err)
+	}
+	H := GenElems(h, int(size))
+	domain := make([]algebra.FieldElement, size)
+	for i := range domain {
+		domain[i] = PrimeField.Mul(PrimeFieldGen, H[i])
+	}
+	inverses := FoldInverses(domain)
+	root := DomainHash(evals)
+	cfg := ProverConfig{keepFRICapacity: !trim}
+
+	var peak uint64
+	var stats runtime.MemStats
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+		runtime.ReadMemStats(&stats)
+		base := stats.Alloc
+		done, sampled := make(chan struct{}), make(chan uint64)
+		go samplePeakAlloc(done, sampled)
+
+		ch := NewChannel()
+		ch.Send(root)
+		if _, _, _, _, err := generateFRICommitment(p, domain, evals, root, inverses, ch, cfg); err != nil {
+			b.Fatal(err)
+		}
+		close(done)
+		if alloc := <-sampled; alloc > base && alloc-base > peak {
+			peak = alloc - base
+		}
+	}
+	b.ReportMetric(float64(peak)/(1<<20), "peak-MB")
+}
+
+// samplePeakAlloc reads the heap statistics every millisecond until done
+// is closed and then sends the highest Alloc read.
+func samplePeakAlloc(done <-chan struct{}, peak chan<- uint64) {
+
+	var stats runtime.MemStats
+	var highest uint64
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	for {
+		runtime.ReadMemStats(&stats)
+		highest = max(highest, stats.Alloc)
+		select {
+		case <-done:
+			peak <- highest
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func BenchmarkFRITrim(b *testing.B) {
+	benchmarkFRITrim(b, true)
+}
+
+func BenchmarkFRINoTrim(b *testing.B) {
+	benchmarkFRITrim(b, false)
+}
+
+func TestIsConstantLayer(t *testing.T) {
+	_, ok := IsConstantLayer(nil)
+	assert.False(t, ok)
+
+	one := PrimeField.One()
+	x, ok := IsConstantLayer([]algebra.FieldElement{one})
+	assert.True(t, ok)
+	assert.True(t, x.Equal(one))
+
+	x, ok = IsConstantLayer([]algebra.FieldElement{one, one, one})
+	assert.True(t, ok)
+	assert.True(t, x.Equal(one))
+
+	_, ok = IsConstantLayer([]algebra.FieldElement{one, one, one.Double()})
+	assert.False(t, ok)
+}
+
+func TestVerifyLayerOpening(t *testing.T) {
+	fri := newSmallFRI(t)
+	assert.Len(t, fri.layers, ExpectedFRILayers(uint64(len(fri.domain)), 2, 8))
+
+	query, err := DecommitFRILayers(37, fri.channel, fri.layers)
+	assert.NoError(t, err)
+	assert.Len(t, query.Layers, len(fri.layers)-1)
+
+	for i := range query.Layers {
+		elem, sibling := query.Layers[i], query.Siblings[i]
+		assert.True(t, VerifyLayerOpening(fri.roots[i], elem.Value, elem.Index, elem.Path), "layer %d", i)
+		assert.True(t, VerifyLayerOpening(fri.roots[i], sibling.Value, sibling.Index, sibling.Path), "layer %d", i)
+		assert.Equal(t, len(fri.layers[i])/2, (sibling.Index-elem.Index+len(fri.layers[i]))%len(fri.layers[i]))
+
+		assert.False(t, VerifyLayerOpening(fri.roots[i], elem.Value.Double(), elem.Index, elem.Path))
+		assert.False(t, VerifyLayerOpening(fri.roots[i], elem.Value, elem.Index^1, elem.Path))
+	}
+}
+
+// cancelAfter is a context canceled once Err has been checked n times.
+type cancelAfter struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.n == 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+func TestFRIDecommitContext(t *testing.T) {
+	cosetEval := func(fri *smallFRI) []*big.Int {
+		evals := make([]*big.Int, len(fri.evals))
+		for i, e := range fri.evals {
+			evals[i] = e.Big()
+		}
+		return evals
+	}
+
+	fri := newSmallFRI(t)
+	queries, err := FRIDecommitContext(&cancelAfter{context.Background(), 1}, fri.channel, cosetEval(fri), fri.layers)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, queries, 1)
+
+	// the channel holds the transcript of the first query only
+	expected := newSmallFRI(t)
+	indices := drawQueryIndices(expected.channel, len(expected.evals))
+	first, err := FRIDecommitQueries(expected.channel, cosetEval(expected), expected.layers, indices[:1])
+	assert.NoError(t, err)
+	assert.Equal(t, first, queries)
+	assert.Equal(t, expected.channel.State, fri.channel.State)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	queries, err = FRIDecommitContext(canceled, newSmallFRI(t).channel, cosetEval(fri), fri.layers)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, queries)
+}
+
+func TestFRIDecommitQueriesIndices(t *testing.T) {
+	fri := newSmallFRI(t)
+	cosetEval := make([]*big.Int, len(fri.evals))
+	for i, e := range fri.evals {
+		cosetEval[i] = e.Big()
+	}
+	state := fri.channel.State
+
+	_, err := FRIDecommitQueries(fri.channel, cosetEval, fri.layers, []int{3, len(cosetEval)})
+	assert.Error(t, err)
+	_, err = FRIDecommitQueries(fri.channel, cosetEval, fri.layers, []int{-1})
+	assert.Error(t, err)
+	_, err = FRIDecommitQueries(fri.channel, cosetEval, fri.layers, []int{5, 9, 5})
+	assert.Error(t, err)
+	assert.Equal(t, state, fri.channel.State)
+
+	// the last indices wrap around the evaluation domain
+	queries, err := FRIDecommitQueries(fri.channel, cosetEval, fri.layers, []int{0, len(cosetEval) - 1})
+	assert.NoError(t, err)
+	assert.Len(t, queries, 2)
+
+	queries, err = FRIDecommit(fri.channel, cosetEval, fri.layers)
+	assert.NoError(t, err)
+	assert.Len(t, queries, 3)
+	seen := make(map[int]bool)
+	for _, q := range queries {
+		assert.False(t, seen[q.Index])
+		seen[q.Index] = true
+	}
+}
+
+func TestEvalDomainArena(t *testing.T) {
+	fri := newSmallFRI(t)
+	arena := algebra.NewFieldArena(PrimeField)
+
+	assert.Equal(t, fri.evals, evalDomain(fri.poly, fri.domain, arena))
+	for i := range fri.polys {
+		assert.Equal(t, fri.layers[i], evalDomain(fri.polys[i], fri.domains[i], arena), "layer %d", i)
+	}
+
+	// folding with the arena commits to the same layers, with or without
+	// the inverse table, as does keeping the capacity of the polynomials
+	for _, cfg := range []ProverConfig{{UseArena: true}, {UseArena: true, keepFRICapacity: true}} {
+		for _, inverses := range [][]algebra.FieldElement{nil, FoldInverses(fri.domain)} {
+			ch := NewChannel()
+			ch.Send(fri.roots[0])
+			_, polys, layers, roots, err := generateFRICommitment(fri.poly, fri.domain, fri.evals, fri.roots[0], inverses, ch, cfg)
+			assert.NoError(t, err)
+			assert.Equal(t, fri.roots, roots)
+			assert.Equal(t, fri.layers, layers)
+			assert.Equal(t, fri.channel.State, ch.State)
+			for i := range polys {
+				assert.Equal(t, 0, fri.polys[i].Compare(&polys[i]), "polynomial %d", i)
+			}
+		}
+	}
+}
+
+func TestGenerateFRICommitmentErrors(t *testing.T) {
+	fri := newSmallFRI(t)
+
+	// evaluations that don't match the domain can't be folded
+	_, _, _, _, err := GenerateFRICommitment(fri.poly, fri.domain, fri.evals[:64], fri.roots[0], NewChannel())
+	assert.ErrorIs(t, err, errFoldLayerSize)
+	_, err = DecommitOnQuery(-1, NewChannel(), nil, fri.layers)
+	assert.Error(t, err)
+}
+
+func TestFoldLayer(t *testing.T) {
+	fri := newSmallFRI(t)
+	beta := PrimeField.NewFieldElementFromInt64(271828)
+
+	folded, err := FoldLayer(fri.evals, fri.domain, beta, PrimeField.Modulus())
+	assert.NoError(t, err)
+	_, _, expected := NextFRILayer(fri.domain, fri.poly, beta)
+	assert.Equal(t, expected, folded)
+
+	_, err = FoldLayer(fri.evals[:96], fri.domain[:96], beta, PrimeField.Modulus())
+	assert.ErrorIs(t, err, errFoldLayerSize)
+	_, err = FoldLayer(fri.evals, fri.domain[:64], beta, PrimeField.Modulus())
+	assert.ErrorIs(t, err, errFoldLayerSize)
+	_, err = FoldLayer(fri.evals, fri.domain, beta, algebra.FromInt64(17))
+	assert.ErrorIs(t, err, errModulusMismatch)
+
+	shuffled := append([]algebra.FieldElement{}, fri.domain...)
+	shuffled[0], shuffled[1] = shuffled[1], shuffled[0]
+	_, err = FoldLayer(fri.evals, shuffled, beta, PrimeField.Modulus())
+	assert.ErrorIs(t, err, errFoldDomain)
+}
+
+func TestFoldInverses(t *testing.T) {
+	fri := newSmallFRI(t)
+	beta := PrimeField.NewFieldElementFromInt64(271828)
+
+	inverses := FoldInverses(fri.domain)
+	assert.Len(t, inverses, len(fri.domain)/2)
+	for i, inv := range inverses {
+		assert.True(t, inv.Equal(fri.domain[i].Double().Inv()), "inverse %d", i)
+	}
+	next := nextFoldInverses(inverses)
+	assert.Equal(t, FoldInverses(fri.domains[1]), next)
+
+	folded, err := FoldLayerInv(fri.evals, fri.domain, inverses, beta, PrimeField.Modulus())
+	assert.NoError(t, err)
+	expected, _ := FoldLayer(fri.evals, fri.domain, beta, PrimeField.Modulus())
+	assert.Equal(t, expected, folded)
+	_, err = FoldLayerInv(fri.evals, fri.domain, next, beta, PrimeField.Modulus())
+	assert.ErrorIs(t, err, errFoldInverses)
+
+	// the whole commitment is unchanged by the table
+	ch := NewChannel()
+	ch.Send(fri.roots[0])
+	domains, polys, layers, roots, err := GenerateFRICommitmentInv(fri.poly, fri.domain, fri.evals, fri.roots[0], inverses, ch)
+	assert.NoError(t, err)
+	assert.Equal(t, fri.domains, domains)
+	assert.Equal(t, fri.polys, polys)
+	assert.Equal(t, fri.layers, layers)
+	assert.Equal(t, fri.roots, roots)
+	assert.Equal(t, fri.channel.State, ch.State)
+}
+
+func benchmarkProofStages(b *testing.B, cfg ProverConfig) {
+	params, constraints := loadFibonacci(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ProveAIR(params, FibonacciAIR{}, constraints, cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProofStages(b *testing.B) {
+	benchmarkProofStages(b, ProverConfig{})
+}
+
+func BenchmarkProofStagesArena(b *testing.B) {
+	benchmarkProofStages(b, ProverConfig{UseArena: true})
+}
+
+func BenchmarkProofStagesInvertPerFold(b *testing.B) {
+	benchmarkProofStages(b, ProverConfig{InvertPerFold: true})
+}
+
+func TestExpectedFRILayers(t *testing.T) {
+	assert.Equal(t, 11, ExpectedFRILayers(8192, 2, 8))
+	assert.Equal(t, 5, ExpectedFRILayers(128, 2, 8))
+	assert.Equal(t, 6, ExpectedFRILayers(8192, 4, 8))
+	assert.Equal(t, 14, ExpectedFRILayers(8192, 2, 1))
+	assert.Equal(t, 6, ExpectedFRILayers(8192, 8, 1))
+	assert.Equal(t, 3, ExpectedFRILayers(100, 10, 1))
+
+	// a domain within the bound is a single constant layer
+	assert.Equal(t, 1, ExpectedFRILayers(8, 2, 8))
+	assert.Equal(t, 1, ExpectedFRILayers(1, 2, 1))
+
+	assert.Panics(t, func() { ExpectedFRILayers(8192, 1, 8) })
+}
+
+func TestCheckFRIDegreeBound(t *testing.T) {
+	_, proof := loadFibonacciProof(t)
+
+	// the composition polynomial has degree 1023 over 8192 points
+	for _, degree := range []int{1023, 600, 512} {
+		ok, err := CheckFRIDegreeBound(proof.FRI, degree)
+		assert.NoError(t, err)
+		assert.True(t, ok, "degree %d", degree)
+	}
+	for _, degree := range []int{1024, 2047, 511, 0} {
+		ok, err := CheckFRIDegreeBound(proof.FRI, degree)
+		assert.NoError(t, err)
+		assert.False(t, ok, "degree %d", degree)
+	}
+
+	// a layer opened on a tree of the wrong size
+	tampered := proof.FRI
+	tampered.Queries = append([]FRIQuery{}, proof.FRI.Queries...)
+	tampered.Queries[1].Siblings = append([]FRILayerOpening{}, proof.FRI.Queries[1].Siblings...)
+	tampered.Queries[1].Siblings[3].Path = tampered.Queries[1].Siblings[3].Path[1:]
+	ok, err := CheckFRIDegreeBound(tampered, 1023)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = CheckFRIDegreeBound(proof.FRI, -1)
+	assert.ErrorIs(t, err, errNegativeDegree)
+	_, err = CheckFRIDegreeBound(FRIProof{}, 1023)
+	assert.ErrorIs(t, err, errNoFRIRoots)
+	tampered.Queries[0].Layers = tampered.Queries[0].Layers[1:]
+	_, err = CheckFRIDegreeBound(tampered, 1023)
+	assert.ErrorIs(t, err, errQueryOpenings)
+}
+
+func TestExpectedLastLayerConstant(t *testing.T) {
+	small := newSmallFRI(t)
+	m := PrimeField.Modulus()
+
+	ch := NewChannel()
+	ch.Send(small.roots[0])
+	var betas []algebra.FieldElement
+	for _, root := range small.roots[1:] {
+		betas = append(betas, PrimeField.NewFieldElement(ch.RandFE(m)))
+		ch.Send(root)
+	}
+	last, ok := IsConstantLayer(small.layers[len(small.layers)-1])
+	assert.True(t, ok)
+	expected, err := ExpectedLastLayerConstant(small.poly, 2, betas)
+	assert.NoError(t, err)
+	assert.True(t, expected.Equal(last))
+
+	// folding by 4 twice
+	betas = betas[:2]
+	layer, domain := small.evals, small.domain
+	for _, beta := range betas {
+		layer, err = FoldLayerBy(layer, domain, 4, beta, m)
+		assert.NoError(t, err)
+		domain = NextFRIDomain(NextFRIDomain(domain))
+	}
+	last, ok = IsConstantLayer(layer)
+	assert.True(t, ok)
+	expected, err = ExpectedLastLayerConstant(small.poly, 4, betas)
+	assert.NoError(t, err)
+	assert.True(t, expected.Equal(last))
+
+	_, err = ExpectedLastLayerConstant(small.poly, 2, betas)
+	assert.Equal(t, errFRISchedule, err)
+	_, err = ExpectedLastLayerConstant(small.poly, 3, betas)
+	assert.Equal(t, errFoldingFactor, err)
+}
@@ -0,0 +1,162 @@
+package stark
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/merkle"
+)
+
+// The FRI proof gathers the layer commitments and, for each query, the
+// opened layer elements with their authentication paths so a third party
+// can check every opening against the layer roots.
+
+// FRILayerOpening holds an element of a FRI layer and its authentication
+// path i.e the sibling hashes from the leaf level up to the root.
+type FRILayerOpening struct {
+	Index int
+	Value algebra.FieldElement
+	Path  [][]byte
+}
+
+// FRIQuery holds the openings of a query on every FRI layer but the last,
+// Layers[i] is the element at the query index and Siblings[i] the element
+// at the sibling index of the ith layer.
+// Trace holds the openings of the trace evaluations read by the constraints
+// at the query point i.e f(x), f(g.x) and f(g^2.x), or the openings of the
+// inputs of an aggregated FRI (see AggregateFRI).
+// Extension holds the openings of the extension columns of a RandomizedAIR
+// column by column, each at the offsets of the AIR as Trace.
+// Columns holds the opening of the composition columns at the query index
+// when the composition is split, Layers[0] then opens their combination.
+type FRIQuery struct {
+	Index     int
+	Trace     []FRILayerOpening
+	Layers    []FRILayerOpening
+	Siblings  []FRILayerOpening
+	Extension []FRILayerOpening
+	Columns   CompositionColumnsOpening
+}
+
+// FRIProof holds the FRI layers merkle roots, the constant of the last layer
+// and the queries openings.
+type FRIProof struct {
+	Roots     [][]byte
+	LastLayer algebra.FieldElement
+	Queries   []FRIQuery
+}
+
+// auditPathHashes extracts the sibling hashes of a merkle audit path.
+func auditPathHashes(ap []merkle.AuditHash) [][]byte {
+	path := make([][]byte, len(ap))
+	for i, h := range ap {
+		path[i] = h.Val
+	}
+	return path
+}
+
+// serializeLayerPath serializes the authentication path of the element at
+// index of a balanced tree as serializeAuditPath does, the sibling is on the
+// right when the bit of the index is 0.
+func serializeLayerPath(index int, path [][]byte) []byte {
+
+	ap := make([]merkle.AuditHash, len(path))
+	for i, h := range path {
+		ap[i] = merkle.AuditHash{Val: h, RightOperator: (index>>uint(i))&1 == 0}
+	}
+	return serializeAuditPath(ap)
+}
+
+// VerifyLayerOpening checks that value at index hashes up to the layer root
+// trough the authentication path. FRI layers have a power of two length so
+// their merkle tree is balanced and the ith bit of the index tells on which
+// side the ith sibling is.
+func VerifyLayerOpening(root []byte, value algebra.FieldElement, index int, path [][]byte) bool {
+	return verifyLeaf(root, value.Big().Bytes(), index, path)
+}
+
+// verifyLeaf checks that the leaf at index of a balanced tree hashes up to
+// root trough the authentication path.
+func verifyLeaf(root []byte, leaf []byte, index int, path [][]byte) bool {
+
+	if index < 0 || index>>uint(len(path)) != 0 {
+		return false
+	}
+	siblings := make([]merkle.AuditSiblings, len(path))
+	for i, h := range path {
+		if (index>>uint(i))&1 == 0 {
+			siblings[i].Right = [][]byte{h}
+		} else {
+			siblings[i].Left = [][]byte{h}
+		}
+	}
+	return merkle.VerifyArity(root, leaf, siblings)
+}
+
+// Each FRI round halves the degree of the folded polynomial, the prover
+// folds until the polynomial is constant so a polynomial of degree d takes
+// bits.Len(d) rounds and the proof commits to bits.Len(d) + 1 layers. The
+// layer sizes follow from the authentication paths : a layer of 2^k
+// elements has paths of k siblings.
+// The layer count only bounds the degree to the next power of two, degrees
+// d and d' with bits.Len(d) = bits.Len(d') can't be told apart.
+
+var errNegativeDegree = errors.New("claimed degree can't be negative")
+
+// CheckFRIDegreeBound checks that the proof is consistent with a first layer
+// polynomial of degree claimedDegree : the number of layers matches the
+// folding rounds of that degree, every query opens each layer but the last
+// one and the opened layers halve in size down to a last layer of at least
+// one element, with a first layer at least twice as large as the claimed
+// degree so the degree test isn't trivial.
+// A malformed proof is reported as an error.
+func CheckFRIDegreeBound(proof FRIProof, claimedDegree int) (bool, error) {
+
+	if claimedDegree < 0 {
+		return false, errNegativeDegree
+	}
+	if len(proof.Roots) == 0 {
+		return false, errNoFRIRoots
+	}
+	rounds := len(proof.Roots) - 1
+	if rounds != bits.Len(uint(claimedDegree)) {
+		return false, nil
+	}
+	for _, query := range proof.Queries {
+		if len(query.Layers) != rounds || len(query.Siblings) != rounds {
+			return false, errQueryOpenings
+		}
+		for i, opening := range query.Layers {
+			depth := len(opening.Path)
+			if len(query.Siblings[i].Path) != depth || depth != len(query.Layers[0].Path)-i {
+				return false, nil
+			}
+		}
+		if rounds > 0 {
+			firstLayer := 1 << len(query.Layers[0].Path)
+			if len(query.Layers[0].Path) < rounds || firstLayer <= 2*claimedDegree {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+var errFRIRounds = errors.New("FRI proof folds more rounds than the composition degree bound allows")
+
+// ExpectedFRIRounds returns the number of FRI rounds folding the composition
+// polynomial of the AIR down to a constant over an evaluation domain of
+// domainSize points with the given blowup i.e bits.Len of its degree bound
+// for a trace of domainSize / blowup rows (see CompositionDegreeBound).
+// A prover folding more rounds turns a polynomial of any degree into a
+// constant, one folding fewer rounds down to a constant proves a lower
+// degree e.g for a trace shorter than the trace domain.
+func ExpectedFRIRounds(air AIR, domainSize, blowup int) (int, error) {
+
+	if blowup < 1 || domainSize < blowup || domainSize%blowup != 0 {
+		return 0, fmt.Errorf("domain size %d isn't a multiple of the blowup %d", domainSize, blowup)
+	}
+	return bits.Len(uint(CompositionDegreeBound(air, domainSize/blowup))), nil
+}
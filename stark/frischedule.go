@@ -0,0 +1,282 @@
+package stark
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/merkle"
+	"github.com/ayushn2/go-stark.git/poly"
+)
+
+// FRI doesn't have to halve the layers at each round : folding by a factor
+// k writes p(x) = Sum(x^j.P_j(x^k)) for 0 <= j < k and the next polynomial
+// is Sum(beta^j.P_j) over the domain of the x^k. Since two folds by 2 with
+// the challenges beta and beta^2 give P_0 + beta.P_1 + beta^2.P_2 +
+// beta^3.P_3, a fold by k = 2^r is computed as r folds by 2 with the
+// challenges beta, beta^2, ..., beta^(2^(r-1)).
+// A round folding by k commits to a single layer, so large factors save
+// layers and authentication paths at the cost of opening the k elements of
+// the coset of the query (x.w^j for the primitive kth root w) in each layer
+// instead of a pair.
+// A FRIConfig schedules the factors of the rounds e.g {4, 4, 2, 2}, the
+// schedule is recorded in the proof and sent trough the channel before the
+// first root so the challenges are bound to it. The verifier folds the
+// openings of each round with its factor, the factors must multiply to the
+// degree bound it expects : a schedule folding further would turn any
+// polynomial into a constant.
+
+var (
+	errFoldingFactor = errors.New("FRI folding factors must be powers of two dividing the layer size")
+	errFRISchedule   = errors.New("FRI schedule doesn't fold the polynomial down to a constant")
+	errCosetOpenings = errors.New("query coset openings don't match the FRI schedule")
+	errScheduleBound = errors.New("FRI schedule doesn't fold the expected degree bound")
+)
+
+// FRIConfig configures a scheduled FRI.
+type FRIConfig struct {
+	// FoldingSchedule holds the folding factor of each round, nil folds by
+	// 2 until the polynomial is constant.
+	FoldingSchedule []int
+}
+
+// ScheduledFRIQuery holds the openings of a query, Cosets[i] holds the
+// openings of the coset of the query in the ith layer ordered by index.
+type ScheduledFRIQuery struct {
+	Index  int
+	Cosets [][]FRILayerOpening
+}
+
+// ScheduledFRIProof holds the folding schedule, the layer roots, the
+// constant of the last layer and the query openings of a scheduled FRI.
+type ScheduledFRIProof struct {
+	Schedule  []int
+	Roots     [][]byte
+	LastLayer algebra.FieldElement
+	Queries   []ScheduledFRIQuery
+}
+
+// validFactor checks that factor is a power of two of at least 2 dividing
+// size.
+func validFactor(factor, size int) bool {
+	return factor >= 2 && factor&(factor-1) == 0 && size%factor == 0
+}
+
+// FoldLayerBy folds the layer evaluated over domain by factor with the
+// challenge beta, the next domain holds the x^factor of the first
+// len(domain)/factor points of domain.
+func FoldLayerBy(layer []algebra.FieldElement, domain []algebra.FieldElement, factor int, beta algebra.FieldElement, modulus *algebra.Integer) ([]algebra.FieldElement, error) {
+
+	if !validFactor(factor, len(layer)) {
+		return nil, errFoldingFactor
+	}
+	for ; factor > 1; factor /= 2 {
+		next, err := FoldLayer(layer, domain, beta, modulus)
+		if err != nil {
+			return nil, err
+		}
+		layer, domain, beta = next, NextFRIDomain(domain), beta.Square()
+	}
+	return layer, nil
+}
+
+// foldCoset folds the k values of a coset x.w^j for 0 <= j < k, w a
+// primitive kth root of unity, into the value of the next layer at x^k.
+func foldCoset(values []algebra.FieldElement, x, w, beta algebra.FieldElement) algebra.FieldElement {
+
+	field := x.Field()
+	for len(values) > 1 {
+		half := len(values) / 2
+		next := make([]algebra.FieldElement, half)
+		point := x
+		for j := range next {
+			next[j] = foldPair(values[j], values[j+half], point, beta)
+			point = field.Mul(point, w)
+		}
+		values, x, w, beta = next, x.Square(), w.Square(), beta.Square()
+	}
+	return values[0]
+}
+
+// sendSchedule sends the folding factors as 8 bytes big-endian integers.
+func sendSchedule(ch *Channel, schedule []int) {
+	b := make([]byte, 8*len(schedule))
+	for i, factor := range schedule {
+		binary.BigEndian.PutUint64(b[8*i:], uint64(factor))
+	}
+	ch.Send(b)
+}
+
+// ProveFRISchedule commits to the evaluations of p over domain and folds
+// them following the schedule of cfg, by default 2 for each bit of the
+// degree of p. The schedule, the roots and the last layer constant are
+// sent trough the channel before the queries are drawn and opened.
+// A schedule that doesn't fold p down to a constant is reported as an
+// error.
+func ProveFRISchedule(p poly.Polynomial, domain []algebra.FieldElement, cfg FRIConfig, ch *Channel) (ScheduledFRIProof, error) {
+
+	if len(domain) == 0 {
+		return ScheduledFRIProof{}, errNoDomainElement
+	}
+	field := domain[0].Field()
+	modulus := field.Modulus()
+	schedule := cfg.FoldingSchedule
+	if schedule == nil {
+		for d := p.Trim().Degree(); d > 0; d /= 2 {
+			schedule = append(schedule, 2)
+		}
+	}
+	sendSchedule(ch, schedule)
+
+	layer := evalDomain(p, domain, nil)
+	layers := [][]algebra.FieldElement{layer}
+	roots := [][]byte{DomainHash(layer)}
+	ch.Send(roots[0])
+
+	for _, factor := range schedule {
+		if !validFactor(factor, len(layer)) {
+			return ScheduledFRIProof{}, errFoldingFactor
+		}
+		beta := field.NewFieldElement(ch.RandFE(modulus))
+		next, err := FoldLayerBy(layer, domain, factor, beta, modulus)
+		if err != nil {
+			return ScheduledFRIProof{}, err
+		}
+		for k := factor; k > 1; k /= 2 {
+			domain = NextFRIDomain(domain)
+		}
+		layer = next
+		layers = append(layers, layer)
+		roots = append(roots, DomainHash(layer))
+		ch.Send(roots[len(roots)-1])
+	}
+	lastLayer, ok := IsConstantLayer(layer)
+	if !ok {
+		return ScheduledFRIProof{}, errFRISchedule
+	}
+	ch.Send(lastLayer.Big().Bytes())
+
+	n := len(layers[0])
+	queries := make([]ScheduledFRIQuery, 0, numQueries)
+	for _, index := range drawQueryIndices(ch, n) {
+		query := ScheduledFRIQuery{Index: index}
+		for i, factor := range schedule {
+			leaves := DomainBytes(layers[i])
+			stride := len(layers[i]) / factor
+			coset := make([]FRILayerOpening, factor)
+			for j := range coset {
+				idx := index%stride + j*stride
+				ap, err := merkle.Proof(leaves, idx)
+				if err != nil {
+					return ScheduledFRIProof{}, err
+				}
+				ch.Send(leaves[idx])
+				ch.Send(serializeAuditPath(ap))
+				coset[j] = FRILayerOpening{idx, layers[i][idx], auditPathHashes(ap)}
+			}
+			query.Cosets = append(query.Cosets, coset)
+		}
+		queries = append(queries, query)
+	}
+
+	return ScheduledFRIProof{
+		Schedule:  schedule,
+		Roots:     roots,
+		LastLayer: lastLayer,
+		Queries:   queries,
+	}, nil
+}
+
+// VerifyFRISchedule verifies a proof generated by ProveFRISchedule over the
+// coset offset.<generator> of size n that the committed polynomial has a
+// degree lower than degreeBound, a power of two, following the schedule
+// recorded in the proof whose factors must multiply to degreeBound.
+// The transcript is replayed from a fresh channel, for each query
+// and round it checks the coset openings against the layer root, that the
+// coset holds the value folded by the previous round and folds it down to
+// the next layer, the last fold must give the last layer constant.
+// A malformed proof is reported as an error, a proof that doesn't verify
+// returns false.
+func VerifyFRISchedule(modulus *algebra.Integer, offset, generator algebra.FieldElement, n, degreeBound int, proof ScheduledFRIProof) (bool, error) {
+
+	field := generator.Field()
+	if field.Modulus() == nil || offset.Field().Modulus() == nil {
+		return false, errNoDomainElement
+	}
+	if modulus == nil || field.Modulus().Cmp(modulus) != 0 {
+		return false, errModulusMismatch
+	}
+	if len(proof.Roots) != len(proof.Schedule)+1 {
+		return false, errNoFRIRoots
+	}
+	size, product := n, 1
+	for _, factor := range proof.Schedule {
+		if !validFactor(factor, size) {
+			return false, errFoldingFactor
+		}
+		size /= factor
+		product *= factor
+	}
+	if product != degreeBound {
+		return false, errScheduleBound
+	}
+
+	ch := NewChannel()
+	sendSchedule(ch, proof.Schedule)
+	ch.Send(proof.Roots[0])
+	betas := make([]algebra.FieldElement, len(proof.Schedule))
+	for i := range betas {
+		betas[i] = field.NewFieldElement(ch.RandFE(modulus))
+		ch.Send(proof.Roots[i+1])
+	}
+	ch.Send(proof.LastLayer.Big().Bytes())
+
+	indices := drawQueryIndices(ch, n)
+	if len(proof.Queries) != len(indices) {
+		return false, errQueriesCount
+	}
+	for q, query := range proof.Queries {
+		if query.Index != indices[q] {
+			return false, nil
+		}
+		if len(query.Cosets) != len(proof.Schedule) {
+			return false, errCosetOpenings
+		}
+		// the layer domain is the coset offset^K.<generator^K> after
+		// folding by K
+		layerOffset, layerGen, length := offset, generator, n
+		var folded algebra.FieldElement
+		for i, factor := range proof.Schedule {
+			coset := query.Cosets[i]
+			if len(coset) != factor {
+				return false, errCosetOpenings
+			}
+			stride := length / factor
+			values := make([]algebra.FieldElement, factor)
+			for j, opening := range coset {
+				if opening.Index != query.Index%stride+j*stride {
+					return false, nil
+				}
+				if !VerifyLayerOpening(proof.Roots[i], opening.Value, opening.Index, opening.Path) {
+					return false, nil
+				}
+				ch.Send(opening.Value.Big().Bytes())
+				ch.Send(serializeLayerPath(opening.Index, opening.Path))
+				values[j] = opening.Value
+			}
+			if i > 0 && !folded.Equal(values[(query.Index%length)/stride]) {
+				return false, nil
+			}
+			x := EvalDomainPoint(layerOffset, layerGen, query.Index%stride)
+			w := layerGen.Exp(algebra.FromInt64(int64(stride)))
+			folded = foldCoset(values, x, w, betas[i])
+
+			k := algebra.FromInt64(int64(factor))
+			layerOffset, layerGen, length = layerOffset.Exp(k), layerGen.Exp(k), stride
+		}
+		if len(proof.Schedule) > 0 && !folded.Equal(proof.LastLayer) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,68 @@
+package stark
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFRISchedule(t *testing.T) {
+	small := newSmallFRI(t)
+	m := PrimeField.Modulus()
+	w := PrimeFieldGen.Exp(algebra.FromInt64(3221225472 / 128))
+	n := len(small.domain)
+
+	// folding by 4 then 2 gives the layer folded twice by 2
+	beta := PrimeField.NewFieldElementFromInt64(7)
+	byFour, err := FoldLayerBy(small.evals, small.domain, 4, beta, m)
+	assert.NoError(t, err)
+	byTwo, _ := FoldLayer(small.evals, small.domain, beta, m)
+	byTwo, _ = FoldLayer(byTwo, NextFRIDomain(small.domain), beta.Square(), m)
+	assert.Equal(t, byTwo, byFour)
+	_, err = FoldLayerBy(small.evals, small.domain, 3, beta, m)
+	assert.Equal(t, errFoldingFactor, err)
+
+	fixed, err := ProveFRISchedule(small.poly, small.domain, FRIConfig{}, NewChannel())
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 2, 2, 2}, fixed.Schedule)
+	scheduled, err := ProveFRISchedule(small.poly, small.domain, FRIConfig{FoldingSchedule: []int{4, 2, 2}}, NewChannel())
+	assert.NoError(t, err)
+	assert.Len(t, scheduled.Roots, 4)
+	assert.Len(t, scheduled.Queries[0].Cosets[0], 4)
+
+	for _, proof := range []ScheduledFRIProof{fixed, scheduled} {
+		ok, err := VerifyFRISchedule(m, PrimeFieldGen, w, n, 16, proof)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	// folding down to a single element makes any layer constant, the
+	// verifier rejects a schedule beyond its degree bound
+	high := poly.RandomPolynomial(127, 31)
+	folded, err := ProveFRISchedule(high, small.domain, FRIConfig{FoldingSchedule: []int{2, 2, 2, 2, 2, 2, 2}}, NewChannel())
+	assert.NoError(t, err)
+	_, err = VerifyFRISchedule(m, PrimeFieldGen, w, n, 16, folded)
+	assert.Equal(t, errScheduleBound, err)
+
+	// a schedule folding too little leaves a non constant layer
+	_, err = ProveFRISchedule(small.poly, small.domain, FRIConfig{FoldingSchedule: []int{4, 2}}, NewChannel())
+	assert.Equal(t, errFRISchedule, err)
+
+	// the schedule is bound to the transcript
+	tampered := scheduled
+	tampered.Schedule = []int{2, 4, 2}
+	ok, err := VerifyFRISchedule(m, PrimeFieldGen, w, n, 16, tampered)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	tampered.Schedule = []int{3, 2, 2}
+	_, err = VerifyFRISchedule(m, PrimeFieldGen, w, n, 16, tampered)
+	assert.Equal(t, errFoldingFactor, err)
+
+	opening := &scheduled.Queries[2].Cosets[1][1]
+	opening.Value = opening.Value.Double()
+	ok, err = VerifyFRISchedule(m, PrimeFieldGen, w, n, 16, scheduled)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
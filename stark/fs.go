@@ -1,10 +1,15 @@
 package stark
 
 import (
+	"encoding/binary"
 	"encoding/hex"
+	"fmt"
 	"math/big"
+	"math/bits"
 	"strings"
+	"time"
 
+	"github.com/ayushn2/go-stark.git/algebra"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -19,22 +24,49 @@ import (
 // use to simulate randomness when emulating prover-verifier interaction.
 // More : https://merlin.cool/
 
+// The hashing is fully described by the sequence of absorbed chunks :
+// the state starts as SHA3-256(0x00 || ChannelProtocolID()) and each
+// operation updates it as State = SHA3-256(State || chunk).
+// Send absorbs the sent bytes as is (no length prefix nor label), drawing a
+// random integer absorbs an empty chunk after the integer is derived from the
+// current state as min + (State mod (max - min + 1)) with State read as a
+// big-endian integer. Fork absorbs "fork:" followed by its label in a copy
+// of the channel. Other labels ("send:", "receiveRandInt:") only appear in
+// the human readable Proof and are never hashed.
+
 var (
 	sendOperator   = "send:"
 	receiveRandInt = "receiveRandInt:"
 	receiveRandFE  = "receiveRandFE:"
+	forkOperator   = "fork:"
 )
 
 // Channel represents a FS transcript cache
 type Channel struct {
-	State []byte
-	Proof []string
+	State      []byte
+	Proof      []string
+	transcript [][]byte
+}
+
+// channelProtocolID domain separates the transcripts of this package from
+// other protocols hashing with SHA3-256. It is bumped whenever the bytes
+// sent trough the channel change :
+// - v1 : authentication paths only sent their direction bytes.
+// - v2 : authentication paths send each hash followed by its direction.
+const channelProtocolID = "go-stark/v2"
+
+// ChannelProtocolID returns the protocol identifier absorbed by every new
+// channel. Every challenge derives from it so changing it breaks the
+// compatibility with existing proofs.
+func ChannelProtocolID() string {
+	return channelProtocolID
 }
 
-// NewChannel creates a new instance of the FS channel
+// NewChannel creates a new instance of the FS channel, its initial state
+// has absorbed the protocol identifier.
 func NewChannel() *Channel {
 	return &Channel{
-		State: []byte{0},
+		State: hash(concat([]byte{0}, []byte(channelProtocolID))),
 		Proof: make([]string, 0, 64),
 	}
 }
@@ -46,6 +78,7 @@ func (ch *Channel) Send(s []byte) {
 	builder.WriteString(hex.EncodeToString(s))
 
 	ch.Proof = append(ch.Proof, builder.String())
+	ch.transcript = append(ch.transcript, append([]byte{}, s...))
 	ch.State = hash(concat(ch.State, s))
 }
 
@@ -65,6 +98,7 @@ func (ch *Channel) RandInt(min, max *big.Int) *big.Int {
 	builder.WriteString(num.String())
 
 	ch.Proof = append(ch.Proof, builder.String())
+	ch.transcript = append(ch.transcript, []byte{})
 	ch.State = hash(ch.State)
 	return num
 
@@ -83,6 +117,178 @@ func (ch *Channel) RandFE(m *big.Int) *big.Int {
 	return num
 
 }
+
+// FEStream returns a function drawing a new random element of ff at each
+// call, each draw advances the channel state as RandFE does. The prover
+// and the verifier must draw from their streams the same number of times
+// at the same points of the transcript or every later challenge differs.
+func (ch *Channel) FEStream(ff algebra.FiniteField) func() algebra.FieldElement {
+	return func() algebra.FieldElement {
+		return ff.NewFieldElement(ch.RandFE(ff.Modulus()))
+	}
+}
+
+// RandFEBatch draws count random field elements from a single state of the
+// channel. The ith challenge is derived as :
+// c_0 = State mod m
+// c_i = SHA3-256(State || i) mod m for i > 0 with i encoded as an 8 bytes
+// big-endian integer.
+// The state is then advanced once by absorbing an empty chunk, so that
+// RandFEBatch(m, 1) matches RandFE(m).
+func (ch *Channel) RandFEBatch(m *big.Int, count int) []*big.Int {
+
+	challenges := make([]*big.Int, count)
+	for i := 0; i < count; i++ {
+		seed := ch.State
+		if i > 0 {
+			counter := make([]byte, 8)
+			binary.BigEndian.PutUint64(counter, uint64(i))
+			seed = hash(concat(append([]byte{}, ch.State...), counter))
+		}
+		challenges[i] = new(big.Int).Mod(new(big.Int).SetBytes(seed), m)
+
+		var builder strings.Builder
+		builder.WriteString(receiveRandInt)
+		builder.WriteString(challenges[i].String())
+		ch.Proof = append(ch.Proof, builder.String())
+	}
+	ch.transcript = append(ch.transcript, []byte{})
+	ch.State = hash(ch.State)
+
+	return challenges
+}
+
+// The proof of work makes the prover grind a nonce such that
+// SHA3-256(State || nonce) starts with the required number of zero bits,
+// nonce encoded as an 8 bytes big-endian integer. The nonce is then sent
+// trough the channel so every later challenge is bound to the work, which
+// makes grinding on the challenges as expensive as the difficulty.
+
+// maxPowBits bounds the proof of work difficulty, grinding 2^40 digests
+// already takes hours and a difficulty above the digest width would never
+// be reached.
+const maxPowBits = 40
+
+var errPowDifficulty = fmt.Errorf("proof of work difficulty exceeds %d bits", maxPowBits)
+
+// powCalibration is the number of hashes timed by ProofOfWorkTimed to
+// estimate the hash rate.
+const powCalibration = 1 << 12
+
+// powDigest returns SHA3-256(State || nonce).
+func (ch *Channel) powDigest(nonce uint64) []byte {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, nonce)
+	return hash(concat(append([]byte{}, ch.State...), counter))
+}
+
+// leadingZeros returns the number of leading zero bits of digest.
+func leadingZeros(digest []byte) uint {
+	var zeros uint
+	for _, b := range digest {
+		if b != 0 {
+			return zeros + uint(bits.LeadingZeros8(b))
+		}
+		zeros += 8
+	}
+	return zeros
+}
+
+// ProofOfWork grinds the smallest nonce whose digest with the current state
+// starts with difficulty zero bits and sends it trough the channel.
+// A difficulty above maxPowBits is rejected before grinding.
+func (ch *Channel) ProofOfWork(difficulty uint) (uint64, error) {
+
+	if difficulty > maxPowBits {
+		return 0, errPowDifficulty
+	}
+	var nonce uint64
+	for leadingZeros(ch.powDigest(nonce)) < difficulty {
+		nonce++
+	}
+	ch.sendNonce(nonce)
+	return nonce, nil
+}
+
+// VerifyProofOfWork checks the nonce against the current state for the
+// given difficulty, a valid nonce is sent trough the channel as the prover
+// did so both transcripts stay in sync.
+func (ch *Channel) VerifyProofOfWork(nonce uint64, difficulty uint) bool {
+
+	if leadingZeros(ch.powDigest(nonce)) < difficulty {
+		return false
+	}
+	ch.sendNonce(nonce)
+	return true
+}
+
+// sendNonce sends the nonce as an 8 bytes big-endian integer.
+func (ch *Channel) sendNonce(nonce uint64) {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, nonce)
+	ch.Send(counter)
+}
+
+// ProofOfWorkTimed picks the difficulty so that grinding takes about target
+// on the current machine and runs ProofOfWork with it. The hash rate is
+// estimated by timing a few thousand digests, grinding b bits takes 2^b
+// digests on average so the difficulty is log2(rate * target) capped at
+// maxPowBits.
+// The difficulty depends on the machine, it must be sent to the verifier
+// alongside the nonce and checked against the minimum it accepts.
+func (ch *Channel) ProofOfWorkTimed(target time.Duration) (nonce uint64, difficulty uint) {
+
+	start := time.Now()
+	for i := uint64(0); i < powCalibration; i++ {
+		ch.powDigest(i)
+	}
+	elapsed := time.Since(start)
+	if elapsed <= 0 {
+		elapsed = 1
+	}
+	hashes := float64(powCalibration) * float64(target) / float64(elapsed)
+	for difficulty < maxPowBits && float64(uint64(1)<<(difficulty+1)) <= hashes {
+		difficulty++
+	}
+	// the difficulty is capped so the grinding can't fail
+	nonce, _ = ch.ProofOfWork(difficulty)
+	return nonce, difficulty
+}
+
+// Fork returns a copy of the channel that has absorbed the label, the
+// channel itself is left unchanged. Forks share the transcript committed so
+// far but draw independent challenges, forks of the same channel must use
+// distinct labels since forks with the same label draw the same challenges.
+func (ch *Channel) Fork(label string) *Channel {
+
+	chunk := []byte(forkOperator + label)
+	return &Channel{
+		State:      hash(concat(append([]byte{}, ch.State...), chunk)),
+		Proof:      append(append(make([]string, 0, len(ch.Proof)+1), ch.Proof...), forkOperator+label),
+		transcript: append(ch.Transcript(), chunk),
+	}
+}
+
+// clone returns a copy of the channel that draws the same challenges, the
+// channel itself is left unchanged by the copy.
+func (ch *Channel) clone() *Channel {
+	return &Channel{
+		State:      append([]byte{}, ch.State...),
+		Proof:      append([]string{}, ch.Proof...),
+		transcript: ch.Transcript(),
+	}
+}
+
+// Transcript returns the ordered list of chunks absorbed by the channel's
+// hash, replaying them from the initial state reproduces every challenge.
+func (ch *Channel) Transcript() [][]byte {
+	transcript := make([][]byte, len(ch.transcript))
+	for i, chunk := range ch.transcript {
+		transcript[i] = append([]byte{}, chunk...)
+	}
+	return transcript
+}
+
 func concat(a, b []byte) []byte {
 	return append(a, b...)
 }
@@ -0,0 +1,153 @@
+package stark
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranscriptGolden(t *testing.T) {
+	ch := NewChannel()
+	ch.Send([]byte("go-stark"))
+	beta := ch.RandFE(PrimeField.Modulus())
+	ch.Send(PrimeField.NewFieldElementFromInt64(2550486681).Bytes())
+	idx := ch.RandInt(big.NewInt(0), big.NewInt(8175))
+
+	// golden values, a change here breaks compatibility with external verifiers
+	assert.Equal(t, "2431480536", beta.String())
+	assert.Equal(t, "5203", idx.String())
+	assert.Equal(t, "541fa9940beb7887813529fdefe43e7ee490a1ea3829c4934d8f55b4e18a9925", hex.EncodeToString(ch.State))
+
+	transcript := ch.Transcript()
+	assert.Len(t, transcript, 4)
+	assert.Equal(t, []byte("go-stark"), transcript[0])
+	assert.Empty(t, transcript[1])
+	assert.Equal(t, "98055699", hex.EncodeToString(transcript[2]))
+	assert.Empty(t, transcript[3])
+
+	// replay the transcript from the initial state
+	state := hash(concat([]byte{0}, []byte(ChannelProtocolID())))
+	for _, chunk := range transcript {
+		state = hash(concat(state, chunk))
+	}
+	assert.Equal(t, ch.State, state)
+}
+
+func TestChannelInitialState(t *testing.T) {
+	// golden value, a change here breaks compatibility with existing proofs
+	assert.Equal(t, "go-stark/v2", ChannelProtocolID())
+	assert.Equal(t, "4db5ce23aa9c217c3e3c005e8a7bc1cecd988ea3897ad09359d1c3cf67a60267", hex.EncodeToString(NewChannel().State))
+	assert.Empty(t, NewChannel().Transcript())
+}
+
+func TestRandFEBatch(t *testing.T) {
+	seeded := func() *Channel {
+		ch := NewChannel()
+		ch.Send([]byte("batch"))
+		return ch
+	}
+
+	single, batch := seeded(), seeded()
+	assert.Equal(t, single.RandFE(PrimeField.Modulus()), batch.RandFEBatch(PrimeField.Modulus(), 1)[0])
+	assert.Equal(t, single.State, batch.State)
+	assert.Equal(t, single.Proof, batch.Proof)
+
+	a, b := seeded().RandFEBatch(PrimeField.Modulus(), 16), seeded().RandFEBatch(PrimeField.Modulus(), 16)
+	assert.Equal(t, a, b)
+	seen := make(map[string]bool)
+	for _, c := range a {
+		assert.Equal(t, -1, c.Cmp(PrimeField.Modulus()))
+		seen[c.String()] = true
+	}
+	assert.Len(t, seen, 16)
+}
+
+func TestProofOfWork(t *testing.T) {
+	seeded := func() *Channel {
+		ch := NewChannel()
+		ch.Send([]byte("grind"))
+		return ch
+	}
+
+	prover, verifier := seeded(), seeded()
+	nonce, err := prover.ProofOfWork(10)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, leadingZeros(seeded().powDigest(nonce)), uint(10))
+	assert.True(t, verifier.VerifyProofOfWork(nonce, 10))
+	assert.Equal(t, prover.State, verifier.State)
+
+	// an invalid nonce leaves the channel untouched
+	rejected := seeded()
+	assert.False(t, rejected.VerifyProofOfWork(nonce, 64))
+	assert.Equal(t, seeded().State, rejected.State)
+
+	// a difficulty that can't be reached is rejected before grinding
+	_, err = rejected.ProofOfWork(257)
+	assert.ErrorIs(t, err, errPowDifficulty)
+	assert.Equal(t, seeded().State, rejected.State)
+}
+
+func TestProofOfWorkTimed(t *testing.T) {
+	ch := NewChannel()
+	ch.Send([]byte("grind"))
+	before := append([]byte{}, ch.State...)
+
+	nonce, difficulty := ch.ProofOfWorkTimed(5 * time.Millisecond)
+	assert.LessOrEqual(t, difficulty, uint(maxPowBits))
+
+	verifier := &Channel{State: before}
+	assert.True(t, verifier.VerifyProofOfWork(nonce, difficulty))
+	assert.Equal(t, ch.State, verifier.State)
+}
+
+func TestChannelFork(t *testing.T) {
+	m := PrimeField.Modulus()
+	ch := NewChannel()
+	ch.Send([]byte("common prefix"))
+	state := append([]byte{}, ch.State...)
+
+	a, b, again := ch.Fork("a"), ch.Fork("b"), ch.Fork("a")
+	assert.Equal(t, state, ch.State)
+	assert.Len(t, ch.Transcript(), 1)
+
+	ca, cb, cagain := a.RandFE(m), b.RandFE(m), again.RandFE(m)
+	assert.NotEqual(t, ca, cb)
+	assert.Equal(t, ca, cagain)
+	assert.NotEqual(t, ch.RandFE(m), ca)
+
+	// the fork is replayed from its transcript
+	replay := hash(concat([]byte{0}, []byte(ChannelProtocolID())))
+	for _, chunk := range a.Transcript() {
+		replay = hash(concat(replay, chunk))
+	}
+	assert.Equal(t, a.State, replay)
+}
+
+func TestFEStream(t *testing.T) {
+	m := PrimeField.Modulus()
+	prover, verifier := NewChannel(), NewChannel()
+	prover.Send([]byte("seed"))
+	verifier.Send([]byte("seed"))
+
+	p, v := prover.FEStream(PrimeField), verifier.FEStream(PrimeField)
+	seen := make(map[string]bool)
+	for i := 0; i < 16; i++ {
+		x, y := p(), v()
+		assert.True(t, x.Equal(y), "pull %d", i)
+		seen[x.Key()] = true
+	}
+	assert.Len(t, seen, 16)
+	assert.Equal(t, prover.State, verifier.State)
+	assert.Len(t, prover.Transcript(), 17)
+
+	// the stream continues the channel's challenges
+	replay := NewChannel()
+	replay.Send([]byte("seed"))
+	first := PrimeField.NewFieldElement(replay.RandFE(m))
+	other := NewChannel()
+	other.Send([]byte("seed"))
+	assert.True(t, first.Equal(other.FEStream(PrimeField)()))
+}
@@ -0,0 +1,19 @@
+package stark
+
+import (
+	"testing"
+)
+
+func FuzzUnmarshalDomainParameters(f *testing.F) {
+	f.Add([]byte(`{"Field":"17","computation_trace":["1","2"],"G_generator":"4","G_subgroup":["1","4","16","13"],"H_generator":"2","H_subgroup":["1","2"],"evaluation_domain":["3","6"],"interpoland_polynomial":["1","1"],"polynomial_evaluations":["4","7"],"evaluation_commitment":"00ff"}`))
+	f.Add([]byte(`{"Field":"0","G_generator":"1","H_generator":"1"}`))
+	f.Add([]byte(`{"Field":"17","computation_trace":["x"]}`))
+	f.Add([]byte(`{}`))
+	f.Add([]byte(`null`))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		params := &DomainParameters{}
+		// must never panic, errors are fine
+		_ = params.UnmarshalJSON(b)
+	})
+}
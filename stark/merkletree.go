@@ -0,0 +1,116 @@
+package stark
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/merkle"
+)
+
+// MerkleTree commits to a list of field elements using a tree of
+// configurable arity, the leaves are encoded as in DomainHash so a
+// binary tree has the same root as DomainHash.
+type MerkleTree struct {
+	arity  int
+	leaves [][]byte
+	root   []byte
+}
+
+// NewMerkleTreeArity builds the merkle tree of given arity (2, 4 or 8
+// are the usual choices) over the leaves, it panics if arity < 2.
+func NewMerkleTreeArity(leaves []algebra.FieldElement, arity int) *MerkleTree {
+
+	if arity < 2 {
+		panic(fmt.Sprintf("merkle tree arity must be at least 2, got %d", arity))
+	}
+	leavesBytes := DomainBytes(leaves)
+
+	return &MerkleTree{
+		arity:  arity,
+		leaves: leavesBytes,
+		root:   merkle.RootArity(leavesBytes, arity),
+	}
+}
+
+// Root returns the root of the tree.
+func (t *MerkleTree) Root() []byte {
+	return t.root
+}
+
+// Arity returns the arity of the tree.
+func (t *MerkleTree) Arity() int {
+	return t.arity
+}
+
+// Proof returns the audit path of the leaf at index i.
+func (t *MerkleTree) Proof(i int) ([]merkle.AuditSiblings, error) {
+	return merkle.ProofArity(t.leaves, i, t.arity)
+}
+
+// VerifyMerkleProof checks that leaf hashes up to the root trough the audit
+// path, the arity of the tree is implied by the path.
+func VerifyMerkleProof(root []byte, leaf algebra.FieldElement, path []merkle.AuditSiblings) bool {
+	return merkle.VerifyArity(root, leaf.Big().Bytes(), path)
+}
+
+var (
+	errNoColumns     = errors.New("no columns to commit")
+	errColumnLengths = errors.New("columns have different lengths")
+)
+
+// CommitRows commits to the rows of a multi-register trace in a binary
+// tree, the ith leaf packs the values of all the columns at the ith row
+// (see PackFieldElements) so a single authentication path opens every
+// register of a row.
+func CommitRows(columns [][]algebra.FieldElement) (*MerkleTree, error) {
+
+	if len(columns) == 0 || len(columns[0]) == 0 {
+		return nil, errNoColumns
+	}
+	for _, column := range columns[1:] {
+		if len(column) != len(columns[0]) {
+			return nil, errColumnLengths
+		}
+	}
+	leaves := compositionRows(columns)
+
+	return &MerkleTree{
+		arity:  2,
+		leaves: leaves,
+		root:   merkle.RootArity(leaves, 2),
+	}, nil
+}
+
+// RowOpening holds the packed leaf of a row committed by CommitRows and
+// its audit path.
+type RowOpening struct {
+	Index int
+	Leaf  []byte
+	Path  []merkle.AuditSiblings
+}
+
+// OpenRow opens the ith leaf of the tree.
+func (t *MerkleTree) OpenRow(i int) (RowOpening, error) {
+
+	path, err := t.Proof(i)
+	if err != nil {
+		return RowOpening{}, err
+	}
+	return RowOpening{Index: i, Leaf: t.leaves[i], Path: path}, nil
+}
+
+// VerifyRow checks the row opening against the root and recovers the
+// values of the width registers of the row, it returns false if the leaf
+// doesn't hash up to the root or doesn't pack width elements of ff.
+func VerifyRow(root []byte, opening RowOpening, width int, ff algebra.FiniteField) ([]algebra.FieldElement, bool) {
+
+	if !merkle.VerifyArity(root, opening.Leaf, opening.Path) {
+		return nil, false
+	}
+	values, err := UnpackFieldElements(opening.Leaf, width, ff)
+	if err != nil {
+		return nil, false
+	}
+	return values, true
+}
@@ -0,0 +1,147 @@
+package stark
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/merkle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerkleTreeArity(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 7, 8, 9, 37, 64} {
+		leaves := GenElems(PrimeFieldGen, n)
+		assert.Equal(t, DomainHash(leaves), NewMerkleTreeArity(leaves, 2).Root(), "n = %d", n)
+
+		for _, arity := range []int{2, 4, 8} {
+			tree := NewMerkleTreeArity(leaves, arity)
+			for i, leaf := range leaves {
+				path, err := tree.Proof(i)
+				assert.NoError(t, err)
+				assert.True(t, VerifyMerkleProof(tree.Root(), leaf, path), "arity %d n %d i %d", arity, n, i)
+				assert.False(t, VerifyMerkleProof(tree.Root(), leaf.Double(), path))
+			}
+		}
+	}
+
+	_, err := NewMerkleTreeArity(GenElems(PrimeFieldGen, 4), 4).Proof(4)
+	assert.Error(t, err)
+}
+
+func TestCommitRows(t *testing.T) {
+	// a three-register trace of 10 rows
+	columns := [][]algebra.FieldElement{
+		GenElems(PrimeFieldGen, 10),
+		GenElems(PrimeFieldGen.Square(), 10),
+		GenElems(PrimeField.NewFieldElementFromInt64(3), 10),
+	}
+	tree, err := CommitRows(columns)
+	assert.NoError(t, err)
+
+	opening, err := tree.OpenRow(6)
+	assert.NoError(t, err)
+	values, ok := VerifyRow(tree.Root(), opening, 3, PrimeField)
+	assert.True(t, ok)
+	for j, column := range columns {
+		assert.True(t, values[j].Equal(column[6]), "register %d", j)
+	}
+
+	// the opening is bound to its row and the tree to every register
+	forged := opening
+	forged.Leaf = PackFieldElements([]algebra.FieldElement{columns[0][6], columns[1][6], columns[2][5]})
+	_, ok = VerifyRow(tree.Root(), forged, 3, PrimeField)
+	assert.False(t, ok)
+	_, ok = VerifyRow(tree.Root(), opening, 2, PrimeField)
+	assert.False(t, ok)
+
+	_, err = CommitRows([][]algebra.FieldElement{columns[0], columns[1][1:]})
+	assert.Equal(t, errColumnLengths, err)
+	_, err = CommitRows(nil)
+	assert.Equal(t, errNoColumns, err)
+	_, err = tree.OpenRow(10)
+	assert.Error(t, err)
+}
+
+func BenchmarkMerkleTreeArity(b *testing.B) {
+	leaves := GenElems(PrimeFieldGen, 8192)
+
+	for _, arity := range []int{2, 4, 8} {
+		tree := NewMerkleTreeArity(leaves, arity)
+		path, err := tree.Proof(4321)
+		if err != nil {
+			b.Fatal(err)
+		}
+		size := 0
+		for _, level := range path {
+			for _, h := range append(level.Left, level.Right...) {
+				size += len(h)
+			}
+		}
+		b.Run(fmt.Sprintf("arity-%d", arity), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				VerifyMerkleProof(tree.Root(), leaves[4321], path)
+			}
+			b.ReportMetric(float64(size), "proof-bytes")
+		})
+	}
+}
+
+func TestDomainHashParallel(t *testing.T) {
+	for _, n := range []int{0, 1, 7, 1023, 1024, 1025, 3000, 4096, 5000} {
+		domain := GenElems(PrimeFieldGen, n)
+		serial := DomainHash(domain)
+		assert.Equal(t, serial, DomainHashParallel(domain), "n = %d", n)
+		for _, workers := range []int{1, 2, 3, 8} {
+			assert.Equal(t, serial, merkle.RootParallel(DomainBytes(domain), workers), "n = %d workers = %d", n, workers)
+		}
+	}
+}
+
+func BenchmarkDomainHash(b *testing.B) {
+	domain := GenElems(PrimeFieldGen, 1<<20)
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			DomainHash(domain)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			DomainHashParallel(domain)
+		}
+	})
+}
+
+func TestLeafHash(t *testing.T) {
+	domain := GenSeq()[:8]
+
+	level := make([][]byte, len(domain))
+	for i, fe := range domain {
+		level[i] = LeafHash(fe)
+	}
+	for len(level) > 1 {
+		next := make([][]byte, len(level)/2)
+		for i := range next {
+			next[i] = merkle.NodeHash(level[2*i], level[2*i+1])
+		}
+		level = next
+	}
+	assert.Equal(t, DomainHash(domain), level[0])
+}
+
+func TestBitReversePermute(t *testing.T) {
+	xs := GenSeq()[:8]
+	permuted := BitReversePermute(xs)
+	for i, j := range []int{0, 4, 2, 6, 1, 5, 3, 7} {
+		assert.True(t, permuted[i].Equal(xs[j]), "index %d", i)
+	}
+	assert.Equal(t, xs, BitReversePermute(permuted))
+	assert.Equal(t, xs[:1], BitReversePermute(xs[:1]))
+	assert.Panics(t, func() { BitReversePermute(xs[:6]) })
+
+	// an NTT evaluating in bit-reversed order commits like DomainHash
+	fri := newSmallFRI(t)
+	assert.Equal(t, fri.roots[0], DomainHashBitReversed(BitReversePermute(fri.evals)))
+	assert.NotEqual(t, fri.roots[0], DomainHash(BitReversePermute(fri.evals)))
+}
@@ -0,0 +1,84 @@
+package stark
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+)
+
+// The public outputs of a program are the values its boundary constraints
+// pin e.g FibSeq(1022) = 2338775057. A boundary constraint is a constraint
+// of degree 1 holding on a single row and reading that row i.e the offset 0.
+// The proof carries the outputs and the prover sends them trough the
+// channel right after the trace commitment so every challenge is bound to
+// them, the verifier checks that the boundary constraints of its AIR
+// vanish on exactly those outputs before replaying the transcript.
+
+// boundaryConstraints returns the indices of the boundary constraints of
+// the AIR.
+func boundaryConstraints(air AIR) []int {
+	var indices []int
+	for i := 0; i < air.NumConstraints(); i++ {
+		if air.ConstraintDegree(i) == 1 && len(air.ConstraintRows(i)) == 1 {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// BoundaryOutputs returns the values of the trace at the rows of the
+// boundary constraints of the AIR in the order of the constraints, rows
+// outside of the trace are skipped.
+func BoundaryOutputs(air AIR, trace []algebra.FieldElement) []Boundary {
+
+	var outputs []Boundary
+	for _, i := range boundaryConstraints(air) {
+		row := air.ConstraintRows(i)[0]
+		if row >= 0 && row < len(trace) {
+			outputs = append(outputs, Boundary{Row: row, Value: trace[row]})
+		}
+	}
+	return outputs
+}
+
+// sendPublicOutputs sends each output as its row, an 8 bytes big-endian
+// integer, followed by the fixed width encoding of its value.
+func sendPublicOutputs(ch *Channel, outputs []Boundary) {
+	for _, output := range outputs {
+		row := make([]byte, 8)
+		binary.BigEndian.PutUint64(row, uint64(output.Row))
+		ch.Send(append(row, output.Value.Bytes()...))
+	}
+}
+
+// checkPublicOutputs checks that the outputs are those of the boundary
+// constraints of the AIR over the trace domain generated by g.
+func checkPublicOutputs(air AIR, g algebra.FieldElement, outputs []Boundary) error {
+
+	indices := boundaryConstraints(air)
+	if len(outputs) != len(indices) {
+		return fmt.Errorf("proof has %d public outputs, the AIR has %d boundary constraints", len(outputs), len(indices))
+	}
+	values := make([]algebra.FieldElement, len(air.Offsets()))
+	for j, i := range indices {
+		output := outputs[j]
+		if output.Value.Field().Modulus() == nil || output.Value.Field().Modulus().Cmp(g.Field().Modulus()) != 0 {
+			return fmt.Errorf("public output %d isn't an element of the trace field", j)
+		}
+		if row := air.ConstraintRows(i)[0]; output.Row != row {
+			return fmt.Errorf("public output %d is at row %d, constraint %d holds at row %d", j, output.Row, i, row)
+		}
+		for k := range values {
+			values[k] = output.Value
+		}
+		nums, err := air.EvalNumerators(g.Exp(algebra.FromInt64(int64(output.Row))), g, values)
+		if err != nil {
+			return err
+		}
+		if !nums[i].IsZero() {
+			return fmt.Errorf("constraint %d doesn't hold on the public output %d at row %d", i, j, output.Row)
+		}
+	}
+	return nil
+}
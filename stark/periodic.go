@@ -0,0 +1,82 @@
+package stark
+
+import (
+	"errors"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+)
+
+// A periodic column is a public column of the trace whose values repeat
+// every k rows, the values of a single period are known to both the prover
+// and the verifier.
+// Over a trace domain of size n generated by g, w = g^(n/k) generates the
+// subgroup of order k and the column is p(x^(n/k)) where p interpolates
+// the period values over the powers of w, since (g^i)^(n/k) = w^(i mod k).
+// The verifier only interpolates k values to evaluate the column at any
+// point, constraints reference the column by evaluating it in their
+// EvalNumerators.
+
+// PeriodicColumn holds the values of a single period of a public column.
+type PeriodicColumn struct {
+	Values []algebra.FieldElement
+}
+
+var (
+	errEmptyColumn  = errors.New("periodic column has no values")
+	errColumnPeriod = errors.New("trace domain size isn't a multiple of the column period")
+)
+
+// NewPeriodicColumn creates a periodic column repeating values.
+func NewPeriodicColumn(values ...algebra.FieldElement) PeriodicColumn {
+	return PeriodicColumn{Values: values}
+}
+
+// Period returns the number of rows after which the column repeats.
+func (c PeriodicColumn) Period() int {
+	return len(c.Values)
+}
+
+// cycle returns the interpolant of a single period and the exponent n/k.
+func (c PeriodicColumn) cycle(g algebra.FieldElement, n int) (poly.Polynomial, int, error) {
+
+	k := c.Period()
+	if k == 0 {
+		return nil, 0, errEmptyColumn
+	}
+	if n <= 0 || n%k != 0 {
+		return nil, 0, errColumnPeriod
+	}
+	w := g.Exp(algebra.FromInt64(int64(n / k)))
+	p := poly.Lagrange(generatePoints(GenElems(w, k), c.Values), g.Field().Modulus())
+	return p, n / k, nil
+}
+
+// Polynomial returns the polynomial of the column over the trace domain
+// of size n generated by g.
+func (c PeriodicColumn) Polynomial(g algebra.FieldElement, n int) (poly.Polynomial, error) {
+
+	p, step, err := c.cycle(g, n)
+	if err != nil {
+		return nil, err
+	}
+	q := make(poly.Polynomial, p.Degree()*step+1)
+	for i := range q {
+		q[i] = new(algebra.Integer)
+	}
+	for i := range p {
+		q[i*step].Set(p[i])
+	}
+	return q, nil
+}
+
+// EvalAt evaluates the column at x given the trace domain of size n
+// generated by g.
+func (c PeriodicColumn) EvalAt(x, g algebra.FieldElement, n int) (algebra.FieldElement, error) {
+
+	p, step, err := c.cycle(g, n)
+	if err != nil {
+		return algebra.FieldElement{}, err
+	}
+	return p.EvalAt(x.Exp(algebra.FromInt64(int64(step)))), nil
+}
@@ -0,0 +1,94 @@
+package stark
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/stretchr/testify/assert"
+)
+
+// evenStepAIR enforces a_{i+1} = a_i + 1 on the even rows of a trace of
+// length 8 only, the transition is enabled by a period 2 selector column.
+type evenStepAIR struct {
+	selector PeriodicColumn
+}
+
+func newEvenStepAIR() evenStepAIR {
+	return evenStepAIR{NewPeriodicColumn(PrimeField.One(), PrimeField.Zero())}
+}
+
+func (evenStepAIR) Offsets() []int {
+	return []int{0, 1}
+}
+
+func (evenStepAIR) NumConstraints() int {
+	return 1
+}
+
+func (evenStepAIR) ConstraintDegree(i int) int {
+	return 1
+}
+
+func (evenStepAIR) ConstraintRows(i int) []int {
+	return []int{0, 1, 2, 3, 4, 5, 6}
+}
+
+func (a evenStepAIR) EvalNumerators(x, g algebra.FieldElement, values []algebra.FieldElement) ([]algebra.FieldElement, error) {
+	if len(values) != 2 {
+		return nil, errTraceValuesCount
+	}
+	s, err := a.selector.EvalAt(x, g, 8)
+	if err != nil {
+		return nil, err
+	}
+	step := PrimeField.Sub(values[1], values[0].AddInt64(1))
+	return []algebra.FieldElement{PrimeField.Mul(s, step)}, nil
+}
+
+func TestPeriodicColumn(t *testing.T) {
+	_, g := skipTrace()
+	column := NewPeriodicColumn(
+		PrimeField.NewFieldElementFromInt64(7),
+		PrimeField.NewFieldElementFromInt64(11),
+		PrimeField.NewFieldElementFromInt64(13),
+		PrimeField.NewFieldElementFromInt64(17),
+	)
+	assert.Equal(t, 4, column.Period())
+
+	p, err := column.Polynomial(g, 8)
+	assert.NoError(t, err)
+	for i, x := range GenElems(g, 8) {
+		v, err := column.EvalAt(x, g, 8)
+		assert.NoError(t, err)
+		assert.True(t, v.Equal(column.Values[i%4]), "row %d", i)
+		assert.True(t, p.EvalAt(x).Equal(v), "row %d", i)
+	}
+
+	x := PrimeField.NewFieldElementFromInt64(31415)
+	v, err := column.EvalAt(x, g, 8)
+	assert.NoError(t, err)
+	assert.True(t, p.EvalAt(x).Equal(v))
+
+	_, err = column.EvalAt(x, g, 6)
+	assert.ErrorIs(t, err, errColumnPeriod)
+	_, err = NewPeriodicColumn().EvalAt(x, g, 8)
+	assert.ErrorIs(t, err, errEmptyColumn)
+}
+
+func TestPeriodicSelector(t *testing.T) {
+	_, g := skipTrace()
+	// the odd rows don't follow the transition
+	trace := make([]algebra.FieldElement, 8)
+	for i := range trace {
+		trace[i] = PrimeField.NewFieldElementFromInt64(int64(10*(i/2) + i%2))
+	}
+	params := &DomainParameters{Trace: trace, GeneratorG: g}
+	assert.NoError(t, params.CheckConstraintsOnTraceDomain(newEvenStepAIR()))
+
+	// the transition is enforced on every row without the selector
+	always := evenStepAIR{NewPeriodicColumn(PrimeField.One())}
+	assert.EqualError(t, params.CheckConstraintsOnTraceDomain(always), "constraint 0 doesn't hold at row 1")
+
+	trace[5] = trace[5].Double()
+	assert.EqualError(t, params.CheckConstraintsOnTraceDomain(newEvenStepAIR()), "constraint 0 doesn't hold at row 4")
+}
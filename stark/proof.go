@@ -0,0 +1,553 @@
+package stark
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+)
+
+// A proof doesn't carry the trace commitment, the verifier receives it
+// alongside the public inputs which describe the program and the domains
+// the proof was generated over.
+
+// StarkProof holds the commitments and the query openings of a proof,
+// the first FRI root is the commitment to the composition polynomial
+// evaluations.
+type StarkProof struct {
+	// Field identifies the field of the proof elements.
+	Field FieldHeader
+	// PublicOutputs are the trace values pinned by the boundary
+	// constraints, they're bound to the transcript.
+	PublicOutputs []Boundary
+	// ExtensionRoots commit to the extension columns of a RandomizedAIR.
+	ExtensionRoots [][]byte
+	// ColumnsRoot commits to the composition columns, it is empty when the
+	// composition isn't split.
+	ColumnsRoot []byte
+	FRI         FRIProof
+	// ProofOfWorkBits is the difficulty the prover ground, 0 when the
+	// proof of work was disabled. The verifier rejects a proof ground with
+	// another difficulty than its own rather than skipping the check.
+	ProofOfWorkBits uint
+	// ProofOfWorkNonce is the nonce ground before the queries are drawn,
+	// it is ignored when the proof of work is disabled.
+	ProofOfWorkNonce uint64
+}
+
+// A proof decoded over the wrong field would be read as different
+// elements, the proof records the width of its encoded elements and a
+// digest of the field modulus so the verifier rejects it upfront.
+
+var (
+	errFieldWidth  = errors.New("proof element width doesn't match the verifier's field")
+	errFieldDigest = errors.New("proof field digest doesn't match the verifier's field")
+)
+
+// FieldHeader identifies the field of a proof.
+type FieldHeader struct {
+	// ByteLen is the width of the encoded elements.
+	ByteLen int
+	// Digest is the SHA3-256 hash of the big-endian modulus.
+	Digest []byte
+}
+
+// NewFieldHeader returns the header of the field ff.
+func NewFieldHeader(ff algebra.FiniteField) FieldHeader {
+	return FieldHeader{ByteLen: ff.ByteLen(), Digest: hash(ff.Modulus().Bytes())}
+}
+
+// check checks that the header is the one of the field ff.
+func (h FieldHeader) check(ff algebra.FiniteField) error {
+	if h.ByteLen != ff.ByteLen() {
+		return fmt.Errorf("%w : %d bytes, expected %d", errFieldWidth, h.ByteLen, ff.ByteLen())
+	}
+	if !bytes.Equal(h.Digest, hash(ff.Modulus().Bytes())) {
+		return errFieldDigest
+	}
+	return nil
+}
+
+// PublicInputs holds the statement known to both the prover and the verifier.
+type PublicInputs struct {
+	// AIR describes the program constraints, defaults to FibonacciAIR
+	// when nil.
+	AIR AIR
+	// TraceGenerator generates the trace domain.
+	TraceGenerator algebra.FieldElement
+	// DomainGenerator generates the subgroup H, the evaluation domain
+	// is the coset DomainOffset.H of size DomainSize.
+	DomainGenerator algebra.FieldElement
+	DomainOffset    algebra.FieldElement
+	DomainSize      int
+	// Blowup is the number of evaluation domain points per trace row i.e
+	// the query at index i reads the row offset k at index i + k.Blowup.
+	Blowup int
+}
+
+// air returns the AIR of the statement or the default one.
+func (pub PublicInputs) air() AIR {
+	if pub.AIR == nil {
+		return FibonacciAIR{}
+	}
+	return pub.AIR
+}
+
+// PublicInputs returns the public inputs of the Fibonacci program proven
+// over the domain parameters.
+func (params *DomainParameters) PublicInputs() PublicInputs {
+
+	pub := PublicInputs{
+		AIR:             FibonacciAIR{},
+		TraceGenerator:  params.GeneratorG,
+		DomainGenerator: params.GeneratorH,
+		DomainSize:      len(params.EvaluationDomain),
+	}
+	if len(params.EvaluationDomain) > 0 {
+		pub.DomainOffset = params.EvaluationDomain[0]
+	}
+	if len(params.SubgroupG) > 0 {
+		pub.Blowup = len(params.SubgroupH) / len(params.SubgroupG)
+	}
+	return pub
+}
+
+// Proof returns the proof gathered by the prover stages, the state must
+// have gone trough OpenQueries.
+func (state *ProverState) Proof() (StarkProof, error) {
+
+	if len(state.FRILayers) == 0 {
+		return StarkProof{}, errFRINotRun
+	}
+	if len(state.Queries) == 0 {
+		return StarkProof{}, errNoQueries
+	}
+	lastLayer, ok := IsConstantLayer(state.FRILayers[len(state.FRILayers)-1])
+	if !ok {
+		return StarkProof{}, errLastLayerNotConstant
+	}
+	return StarkProof{
+		Field:          NewFieldHeader(lastLayer.Field()),
+		PublicOutputs:  state.PublicOutputs,
+		ExtensionRoots: state.ExtensionRoots,
+		ColumnsRoot:    state.ColumnsRoot,
+		FRI: FRIProof{
+			Roots:     state.FRIRoots,
+			LastLayer: lastLayer,
+			Queries:   state.Queries,
+		},
+		ProofOfWorkBits:  state.Config.ProofOfWorkBits,
+		ProofOfWorkNonce: state.ProofOfWorkNonce,
+	}, nil
+}
+
+// The JSON encoding of a proof is meant for debugging and tooling, it is
+// self-describing : the field modulus and the field elements are decimal
+// strings, the merkle roots and authentication paths are hex strings.
+
+// jsonLayerOpening is the JSON encoding of a FRILayerOpening.
+type jsonLayerOpening struct {
+	Index int      `json:"index"`
+	Value string   `json:"value"`
+	Path  []string `json:"path"`
+}
+
+// jsonFRIQuery is the JSON encoding of a FRIQuery.
+type jsonFRIQuery struct {
+	Index     int                 `json:"index"`
+	Trace     []jsonLayerOpening  `json:"trace_openings"`
+	Layers    []jsonLayerOpening  `json:"layer_openings"`
+	Siblings  []jsonLayerOpening  `json:"sibling_openings"`
+	Extension []jsonLayerOpening  `json:"extension_openings,omitempty"`
+	Columns   *jsonColumnsOpening `json:"composition_columns,omitempty"`
+}
+
+// jsonColumnsOpening is the JSON encoding of a CompositionColumnsOpening.
+type jsonColumnsOpening struct {
+	Index  int      `json:"index"`
+	Values []string `json:"values"`
+	Path   []string `json:"path"`
+}
+
+// jsonBoundary is the JSON encoding of a public output.
+type jsonBoundary struct {
+	Row   int    `json:"row"`
+	Value string `json:"value"`
+}
+
+// jsonStarkProof is the JSON encoding of a StarkProof.
+type jsonStarkProof struct {
+	Field          string         `json:"field"`
+	PublicOutputs  []jsonBoundary `json:"public_outputs,omitempty"`
+	ExtensionRoots []string       `json:"extension_roots,omitempty"`
+	ColumnsRoot    string         `json:"composition_columns_root,omitempty"`
+	Roots          []string       `json:"fri_roots"`
+	LastLayer      string         `json:"last_layer"`
+	PowBits        uint           `json:"pow_bits,omitempty"`
+	PowNonce       uint64         `json:"pow_nonce,omitempty"`
+	// Queries come last so the header can be read before them (see
+	// VerifyStream).
+	Queries []jsonFRIQuery `json:"queries"`
+}
+
+var errNoProofField = errors.New("proof last layer isn't a field element")
+
+// encodeHexes encodes each byte slice as hex, nil is kept as nil.
+func encodeHexes(bs [][]byte) []string {
+	if bs == nil {
+		return nil
+	}
+	res := make([]string, len(bs))
+	for i, b := range bs {
+		res[i] = hex.EncodeToString(b)
+	}
+	return res
+}
+
+// decodeHexes decodes each hex string, nil is kept as nil.
+func decodeHexes(ss []string) ([][]byte, error) {
+	if ss == nil {
+		return nil, nil
+	}
+	res := make([][]byte, len(ss))
+	for i, s := range ss {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = b
+	}
+	return res, nil
+}
+
+// encodeOpenings encodes the layer openings, nil is kept as nil.
+func encodeOpenings(openings []FRILayerOpening) []jsonLayerOpening {
+	if openings == nil {
+		return nil
+	}
+	res := make([]jsonLayerOpening, len(openings))
+	for i, o := range openings {
+		res[i] = jsonLayerOpening{o.Index, o.Value.Big().String(), encodeHexes(o.Path)}
+	}
+	return res
+}
+
+// decodeElement decodes a decimal field element which must be reduced.
+func decodeElement(field algebra.FiniteField, s string) (algebra.FieldElement, error) {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return algebra.FieldElement{}, errors.New("bad number encoding")
+	}
+	if n.Sign() < 0 || n.Cmp(field.Modulus()) >= 0 {
+		return algebra.FieldElement{}, errNotReduced
+	}
+	return field.NewFieldElement(n), nil
+}
+
+// decodeOpenings decodes the layer openings, nil is kept as nil.
+func decodeOpenings(field algebra.FiniteField, openings []jsonLayerOpening) ([]FRILayerOpening, error) {
+	if openings == nil {
+		return nil, nil
+	}
+	res := make([]FRILayerOpening, len(openings))
+	for i, o := range openings {
+		value, err := decodeElement(field, o.Value)
+		if err != nil {
+			return nil, err
+		}
+		path, err := decodeHexes(o.Path)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = FRILayerOpening{o.Index, value, path}
+	}
+	return res, nil
+}
+
+// encodeColumns encodes the composition columns opening, nil when there is
+// no column.
+func encodeColumns(opening CompositionColumnsOpening) *jsonColumnsOpening {
+	if len(opening.Values) == 0 {
+		return nil
+	}
+	values := make([]string, len(opening.Values))
+	for i, v := range opening.Values {
+		values[i] = v.Big().String()
+	}
+	return &jsonColumnsOpening{opening.Index, values, encodeHexes(opening.Path)}
+}
+
+// decodeColumns decodes the composition columns opening, nil is decoded
+// as an opening without columns.
+func decodeColumns(field algebra.FiniteField, opening *jsonColumnsOpening) (CompositionColumnsOpening, error) {
+	if opening == nil {
+		return CompositionColumnsOpening{}, nil
+	}
+	values := make([]algebra.FieldElement, len(opening.Values))
+	for i, v := range opening.Values {
+		value, err := decodeElement(field, v)
+		if err != nil {
+			return CompositionColumnsOpening{}, err
+		}
+		values[i] = value
+	}
+	path, err := decodeHexes(opening.Path)
+	if err != nil {
+		return CompositionColumnsOpening{}, err
+	}
+	return CompositionColumnsOpening{opening.Index, values, path}, nil
+}
+
+// encodeOutputs encodes the public outputs, nil is kept as nil.
+func encodeOutputs(outputs []Boundary) []jsonBoundary {
+	if outputs == nil {
+		return nil
+	}
+	res := make([]jsonBoundary, len(outputs))
+	for i, o := range outputs {
+		res[i] = jsonBoundary{o.Row, o.Value.Big().String()}
+	}
+	return res
+}
+
+// decodeOutputs decodes the public outputs, nil is kept as nil.
+func decodeOutputs(field algebra.FiniteField, outputs []jsonBoundary) ([]Boundary, error) {
+	if outputs == nil {
+		return nil, nil
+	}
+	res := make([]Boundary, len(outputs))
+	for i, o := range outputs {
+		value, err := decodeElement(field, o.Value)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = Boundary{o.Row, value}
+	}
+	return res, nil
+}
+
+// MarshalJSON encodes the proof as self-describing JSON.
+func (p StarkProof) MarshalJSON() ([]byte, error) {
+
+	field := p.FRI.LastLayer.Field().Modulus()
+	if field == nil {
+		return nil, errNoProofField
+	}
+	jsonProof := jsonStarkProof{
+		Field:          field.String(),
+		ExtensionRoots: encodeHexes(p.ExtensionRoots),
+		ColumnsRoot:    hex.EncodeToString(p.ColumnsRoot),
+		PublicOutputs:  encodeOutputs(p.PublicOutputs),
+		Roots:          encodeHexes(p.FRI.Roots),
+		LastLayer:      p.FRI.LastLayer.Big().String(),
+		PowBits:        p.ProofOfWorkBits,
+		PowNonce:       p.ProofOfWorkNonce,
+	}
+	if p.FRI.Queries != nil {
+		jsonProof.Queries = make([]jsonFRIQuery, len(p.FRI.Queries))
+	}
+	for i, q := range p.FRI.Queries {
+		jsonProof.Queries[i] = jsonFRIQuery{
+			Index:     q.Index,
+			Trace:     encodeOpenings(q.Trace),
+			Layers:    encodeOpenings(q.Layers),
+			Siblings:  encodeOpenings(q.Siblings),
+			Extension: encodeOpenings(q.Extension),
+			Columns:   encodeColumns(q.Columns),
+		}
+	}
+	return json.MarshalIndent(jsonProof, "", " ")
+}
+
+// UnmarshalJSON decodes a proof encoded by MarshalJSON, field elements
+// that aren't reduced modulo the field order are rejected.
+func (p *StarkProof) UnmarshalJSON(b []byte) error {
+
+	var jsonProof jsonStarkProof
+	if err := json.Unmarshal(b, &jsonProof); err != nil {
+		return err
+	}
+	proof, err := jsonProof.decode()
+	if err != nil {
+		return err
+	}
+	*p = proof
+	return nil
+}
+
+// decode decodes the field elements and the hashes of the proof.
+func (jsonProof jsonStarkProof) decode() (StarkProof, error) {
+
+	modulus, ok := new(big.Int).SetString(jsonProof.Field, 10)
+	if !ok {
+		return StarkProof{}, errors.New("bad number encoding")
+	}
+	if modulus.Cmp(big.NewInt(1)) <= 0 {
+		return StarkProof{}, errBadModulus
+	}
+	field, _ := algebra.NewFiniteField(modulus)
+
+	proof := StarkProof{Field: NewFieldHeader(field), ProofOfWorkBits: jsonProof.PowBits, ProofOfWorkNonce: jsonProof.PowNonce}
+	var err error
+	if proof.PublicOutputs, err = decodeOutputs(field, jsonProof.PublicOutputs); err != nil {
+		return StarkProof{}, err
+	}
+	if proof.ExtensionRoots, err = decodeHexes(jsonProof.ExtensionRoots); err != nil {
+		return StarkProof{}, err
+	}
+	if jsonProof.ColumnsRoot != "" {
+		if proof.ColumnsRoot, err = hex.DecodeString(jsonProof.ColumnsRoot); err != nil {
+			return StarkProof{}, err
+		}
+	}
+	if proof.FRI.Roots, err = decodeHexes(jsonProof.Roots); err != nil {
+		return StarkProof{}, err
+	}
+	if proof.FRI.LastLayer, err = decodeElement(field, jsonProof.LastLayer); err != nil {
+		return StarkProof{}, err
+	}
+	if jsonProof.Queries != nil {
+		proof.FRI.Queries = make([]FRIQuery, len(jsonProof.Queries))
+	}
+	for i, q := range jsonProof.Queries {
+		if proof.FRI.Queries[i], err = q.decode(field); err != nil {
+			return StarkProof{}, err
+		}
+	}
+	return proof, nil
+}
+
+// decode decodes the openings of the query.
+func (q jsonFRIQuery) decode(field algebra.FiniteField) (FRIQuery, error) {
+
+	query := FRIQuery{Index: q.Index}
+	var err error
+	if query.Trace, err = decodeOpenings(field, q.Trace); err != nil {
+		return FRIQuery{}, err
+	}
+	if query.Layers, err = decodeOpenings(field, q.Layers); err != nil {
+		return FRIQuery{}, err
+	}
+	if query.Siblings, err = decodeOpenings(field, q.Siblings); err != nil {
+		return FRIQuery{}, err
+	}
+	if query.Extension, err = decodeOpenings(field, q.Extension); err != nil {
+		return FRIQuery{}, err
+	}
+	if query.Columns, err = decodeColumns(field, q.Columns); err != nil {
+		return FRIQuery{}, err
+	}
+	return query, nil
+}
+
+// A verifier exposed to the network decodes proofs from untrusted peers,
+// DecodeProof bounds the bytes read from the peer before decoding them and
+// the number of queries before decoding their openings so an oversized
+// proof is rejected without allocating for its claimed content.
+
+const (
+	// DefaultMaxProofSize bounds the size of a JSON proof, the proofs of
+	// the Fibonacci program are about a hundred kilobytes.
+	DefaultMaxProofSize = 16 << 20
+	// DefaultMaxQueries bounds the number of queries of a proof.
+	DefaultMaxQueries = 256
+)
+
+var (
+	errProofTooLarge  = errors.New("proof exceeds the maximum proof size")
+	errTooManyQueries = errors.New("proof exceeds the maximum number of queries")
+)
+
+// VerifierConfig holds the limits enforced on decoded proofs, zero values
+// select the defaults.
+type VerifierConfig struct {
+	// MaxProofSize is the maximum size in bytes of an encoded proof.
+	MaxProofSize int64
+	// MaxQueries is the maximum number of queries of a proof.
+	MaxQueries int
+}
+
+// maxProofSize returns the configured maximum proof size or the default.
+func (cfg VerifierConfig) maxProofSize() int64 {
+	if cfg.MaxProofSize <= 0 {
+		return DefaultMaxProofSize
+	}
+	return cfg.MaxProofSize
+}
+
+// maxQueries returns the configured maximum number of queries or the
+// default.
+func (cfg VerifierConfig) maxQueries() int {
+	if cfg.MaxQueries <= 0 {
+		return DefaultMaxQueries
+	}
+	return cfg.MaxQueries
+}
+
+// DecodeProof reads a JSON proof encoded by MarshalJSON from r, it reads at
+// most MaxProofSize bytes and rejects proofs holding more than MaxQueries
+// queries.
+func DecodeProof(r io.Reader, cfg VerifierConfig) (StarkProof, error) {
+
+	limit := cfg.maxProofSize()
+	b, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return StarkProof{}, err
+	}
+	if int64(len(b)) > limit {
+		return StarkProof{}, errProofTooLarge
+	}
+	var jsonProof jsonStarkProof
+	if err := json.Unmarshal(b, &jsonProof); err != nil {
+		return StarkProof{}, err
+	}
+	if len(jsonProof.Queries) > cfg.maxQueries() {
+		return StarkProof{}, errTooManyQueries
+	}
+	return jsonProof.decode()
+}
+
+// Transports with strict size limits (e.g calldata) may trade soundness for
+// size by dropping queries. Each query catches a prover committing to a
+// function far from a low degree polynomial with probability about
+// 1 - 1/blowup, so a proof keeping m queries has a soundness error of
+// about blowup^-m i.e log2(blowup) bits per query are lost with each
+// dropped query. A pruned proof is rejected by Verify, which expects every
+// drawn query, and is checked with VerifyFRISubset on the kept queries.
+
+// PruneSafetyFloor is the number of queries under which a pruned proof is
+// considered unsafe.
+const PruneSafetyFloor = 2
+
+var (
+	// ErrPruneBelowFloor is returned along with the pruned proof when it
+	// keeps less than PruneSafetyFloor queries, callers accepting the
+	// soundness loss may ignore it.
+	ErrPruneBelowFloor = fmt.Errorf("pruned proof keeps less than %d queries", PruneSafetyFloor)
+	errPruneQueries    = errors.New("a pruned proof must keep at least one query")
+)
+
+// Prune returns a copy of the proof keeping its first maxQueries queries in
+// the order they were drawn, a proof with at most maxQueries queries is
+// returned as is. The pruned proof has a reduced soundness (see above),
+// below PruneSafetyFloor queries it is returned with ErrPruneBelowFloor.
+func (p StarkProof) Prune(maxQueries int) (StarkProof, error) {
+
+	if maxQueries < 1 {
+		return StarkProof{}, errPruneQueries
+	}
+	if len(p.FRI.Queries) <= maxQueries {
+		return p, nil
+	}
+	pruned := p
+	pruned.FRI.Queries = append([]FRIQuery{}, p.FRI.Queries[:maxQueries]...)
+	if maxQueries < PruneSafetyFloor {
+		return pruned, ErrPruneBelowFloor
+	}
+	return pruned, nil
+}
@@ -0,0 +1,142 @@
+package stark
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStarkProofJSON(t *testing.T) {
+	params, proof := loadFibonacciProof(t)
+
+	b, err := json.Marshal(proof)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"field":"3221225473"`)
+
+	var decoded StarkProof
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, proof, decoded)
+
+	ok, err := VerifyWithCommitment(PrimeField.Modulus(), params.EvaluationRoot, params.PublicInputs(), decoded, ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	unreduced := strings.Replace(string(b), `"last_layer":"`+proof.FRI.LastLayer.Big().String()+`"`, `"last_layer":"3221225474"`, 1)
+	assert.ErrorIs(t, json.Unmarshal([]byte(unreduced), &decoded), errNotReduced)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"field":"17","fri_roots":["zz"],"last_layer":"1"}`), &decoded))
+	_, err = json.Marshal(StarkProof{})
+	assert.Error(t, err)
+}
+
+// endlessQueries streams a proof header followed by an endless query list.
+type endlessQueries struct {
+	header bool
+	read   int
+}
+
+func (r *endlessQueries) Read(b []byte) (int, error) {
+	n := 0
+	if !r.header {
+		n = copy(b, `{"field":"3221225473","fri_roots":[],"last_layer":"1","queries":[`)
+		r.header = true
+	}
+	for n < len(b) {
+		b[n] = "{},"[(r.read+n)%3]
+		n++
+	}
+	r.read += n
+	return n, nil
+}
+
+func TestDecodeProofLimits(t *testing.T) {
+	params, proof := loadFibonacciProof(t)
+
+	b, err := json.Marshal(proof)
+	assert.NoError(t, err)
+	decoded, err := DecodeProof(strings.NewReader(string(b)), VerifierConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, proof, decoded)
+	ok, err := VerifyWithCommitment(PrimeField.Modulus(), params.EvaluationRoot, params.PublicInputs(), decoded, ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = DecodeProof(strings.NewReader(string(b)), VerifierConfig{MaxQueries: len(proof.FRI.Queries) - 1})
+	assert.ErrorIs(t, err, errTooManyQueries)
+
+	r := &endlessQueries{}
+	_, err = DecodeProof(r, VerifierConfig{MaxProofSize: 1 << 16})
+	assert.ErrorIs(t, err, errProofTooLarge)
+	assert.LessOrEqual(t, r.read, 2<<16)
+
+	_, err = DecodeProof(strings.NewReader(`{"field":"3221225473","last_layer":"1","queries":[{},{},{}]}`), VerifierConfig{MaxQueries: 2})
+	assert.ErrorIs(t, err, errTooManyQueries)
+}
+
+func TestProofFieldHeader(t *testing.T) {
+	params, proof := loadFibonacciProof(t)
+	m := PrimeField.Modulus()
+	assert.Equal(t, NewFieldHeader(PrimeField), proof.Field)
+
+	// the same proof serialized over the 8 bytes wide Goldilocks field
+	b, err := json.Marshal(proof)
+	assert.NoError(t, err)
+	wide := strings.Replace(string(b), `"field":"3221225473"`, `"field":"18446744069414584321"`, 1)
+	decoded, err := DecodeProof(strings.NewReader(wide), VerifierConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, 8, decoded.Field.ByteLen)
+	_, err = Verify(m, params.EvaluationRoot, params.PublicInputs(), decoded, ProverConfig{})
+	assert.ErrorIs(t, err, errFieldWidth)
+
+	// a field of the same width is told apart by the digest
+	other, _ := algebra.NewFiniteField(new(algebra.Integer).SetUint64(2013265921))
+	tampered := proof
+	tampered.Field = NewFieldHeader(other)
+	_, err = Verify(m, params.EvaluationRoot, params.PublicInputs(), tampered, ProverConfig{})
+	assert.ErrorIs(t, err, errFieldDigest)
+
+	tampered.Field = FieldHeader{}
+	_, err = Verify(m, params.EvaluationRoot, params.PublicInputs(), tampered, ProverConfig{})
+	assert.ErrorIs(t, err, errFieldWidth)
+}
+
+func TestPrune(t *testing.T) {
+	params, proof := loadFibonacciProof(t)
+	transcript := func() *Channel {
+		ch := NewChannel()
+		ch.Send(params.EvaluationRoot)
+		sendPublicOutputs(ch, proof.PublicOutputs)
+		IndependentRandomCombiner{}.Coefficients(FibonacciAIR{}.NumConstraints(), ch)
+		return ch
+	}
+
+	pruned, err := proof.Prune(2)
+	assert.NoError(t, err)
+	assert.Len(t, pruned.FRI.Queries, 2)
+	assert.Len(t, proof.FRI.Queries, 3)
+	assert.Equal(t, proof.FRI.Queries[:2], pruned.FRI.Queries)
+
+	kept := []int{pruned.FRI.Queries[0].Index, pruned.FRI.Queries[1].Index}
+	ok, err := VerifyFRISubset(pruned.FRI, pruned.FRI.Roots[0], kept, transcript())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	// the dropped query can't be checked anymore
+	_, err = VerifyFRISubset(pruned.FRI, pruned.FRI.Roots[0], []int{proof.FRI.Queries[2].Index}, transcript())
+	assert.Equal(t, errQueryNotInProof, err)
+
+	pruned, err = proof.Prune(1)
+	assert.Equal(t, ErrPruneBelowFloor, err)
+	assert.Len(t, pruned.FRI.Queries, 1)
+	ok, err = VerifyFRISubset(pruned.FRI, pruned.FRI.Roots[0], kept[:1], transcript())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	same, err := proof.Prune(10)
+	assert.NoError(t, err)
+	assert.Equal(t, proof, same)
+	_, err = proof.Prune(0)
+	assert.Equal(t, errPruneQueries, err)
+}
@@ -0,0 +1,415 @@
+package stark
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/merkle"
+	"github.com/ayushn2/go-stark.git/poly"
+)
+
+// The proving pipeline is split in stages, each stage reads the values
+// produced by the previous ones from the prover state and records its own.
+// Prove runs the stages in order, callers that need to interleave custom
+// steps (logging, extra commitments...) can call the stages themselves
+// as long as they keep the same order since every stage draws from or
+// sends to the Fiat-Shamir channel.
+// CommitTrace -> CommitExtension -> BuildComposition -> CommitComposition -> RunFRI -> OpenQueries
+
+// ProverState holds the values produced by the proving stages.
+type ProverState struct {
+	Params  *DomainParameters
+	Config  ProverConfig
+	Channel *Channel
+
+	// AIR describes the program, defaults to FibonacciAIR when nil.
+	AIR AIR
+
+	// PublicOutputs are the trace values pinned by the boundary
+	// constraints of the AIR.
+	PublicOutputs []Boundary
+
+	// The extension columns of a RandomizedAIR over the trace domain, the
+	// challenge they're computed from, their evaluations over the
+	// evaluation domain and the merkle root of each column evaluations.
+	ExtensionChallenge algebra.FieldElement
+	ExtensionColumns   [][]algebra.FieldElement
+	ExtensionEvals     [][]algebra.FieldElement
+	ExtensionRoots     [][]byte
+
+	// Constraints are the constraint quotients of the program, when left
+	// empty BuildComposition derives the Fibonacci program constraints.
+	Constraints []poly.Polynomial
+
+	CompositionPoly  poly.Polynomial
+	CompositionEvals []algebra.FieldElement
+	CompositionRoot  []byte
+
+	// A composition of degree at least the trace domain size is split in
+	// CompositionColumns committed jointly under ColumnsRoot, FRI then runs
+	// on their random combination ColumnsMix and CompositionRoot commits
+	// to its evaluations (see SplitComposition).
+	CompositionColumns []poly.Polynomial
+	ColumnEvals        [][]algebra.FieldElement
+	ColumnsRoot        []byte
+	ColumnsMix         poly.Polynomial
+	ColumnsMixEvals    []algebra.FieldElement
+
+	// FoldInverses is the table of (2x)^-1 over the evaluation domain
+	// used to fold the FRI layers, RunFRI computes it when nil.
+	FoldInverses []algebra.FieldElement
+
+	FRIDomains [][]algebra.FieldElement
+	FRIPolys   []poly.Polynomial
+	FRILayers  [][]algebra.FieldElement
+	FRIRoots   [][]byte
+
+	// ProofOfWorkNonce is ground before the queries are drawn when the
+	// config sets a difficulty.
+	ProofOfWorkNonce uint64
+	Queries          []FRIQuery
+
+	traceCommitted bool
+}
+
+var (
+	errNoParams                = errors.New("prover state has no domain parameters")
+	errNoEvaluationRoot        = errors.New("domain parameters have no evaluation root")
+	errTraceNotCommitted       = errors.New("trace isn't committed, run CommitTrace first")
+	errNoComposition           = errors.New("composition polynomial isn't built, run BuildComposition first")
+	errCompositionNotCommitted = errors.New("composition isn't committed, run CommitComposition first")
+	errFRINotRun               = errors.New("FRI layers aren't committed, run RunFRI first")
+	errExtensionColumns        = errors.New("extension columns don't match the RandomizedAIR")
+)
+
+// NewProverState creates the initial prover state with a fresh channel.
+func NewProverState(params *DomainParameters, cfg ProverConfig) *ProverState {
+	return &ProverState{
+		Params:  params,
+		Config:  cfg,
+		Channel: NewChannel(),
+		AIR:     FibonacciAIR{},
+	}
+}
+
+// CommitTrace sends the commitment of the trace polynomial evaluations
+// and the public outputs of the trace trough the channel.
+func CommitTrace(state *ProverState) (*ProverState, error) {
+
+	if state.Params == nil {
+		return state, errNoParams
+	}
+	if len(state.Params.EvaluationRoot) == 0 {
+		return state, errNoEvaluationRoot
+	}
+	air := state.AIR
+	if air == nil {
+		air = FibonacciAIR{}
+	}
+	state.Channel.Send(state.Params.EvaluationRoot)
+	state.PublicOutputs = BoundaryOutputs(air, state.Params.Trace)
+	sendPublicOutputs(state.Channel, state.PublicOutputs)
+	state.traceCommitted = true
+	return state, nil
+}
+
+// CommitExtension draws the extension challenge from the channel when the
+// AIR is a RandomizedAIR, interpolates each extension column over the trace
+// domain and sends the commitment of its evaluations over the evaluation
+// domain, otherwise the state is left as is.
+func CommitExtension(state *ProverState) (*ProverState, error) {
+
+	if !state.traceCommitted {
+		return state, errTraceNotCommitted
+	}
+	air, ok := state.AIR.(RandomizedAIR)
+	if !ok {
+		return state, nil
+	}
+	params := state.Params
+	field := params.GeneratorG.Field()
+	modulus := field.Modulus()
+	n := len(params.EvaluationDomain)
+	state.ExtensionChallenge = field.NewFieldElement(state.Channel.RandFE(modulus))
+
+	columns := air.ExtensionColumns(state.ExtensionChallenge)
+	if len(columns) != air.NumExtensionColumns() {
+		return state, errExtensionColumns
+	}
+	state.ExtensionColumns = make([][]algebra.FieldElement, len(columns))
+	state.ExtensionEvals = make([][]algebra.FieldElement, len(columns))
+	state.ExtensionRoots = make([][]byte, len(columns))
+	for i, column := range columns {
+		if len(column) == 0 || len(column) > len(params.SubgroupG) {
+			return state, fmt.Errorf("%w : column %d has %d rows, the trace domain %d", errExtensionColumns, i, len(column), len(params.SubgroupG))
+		}
+		padded := make([]algebra.FieldElement, len(params.SubgroupG))
+		for j := range padded {
+			padded[j] = column[min(j, len(column)-1)]
+		}
+		f, err := InterpolateSubgroup(padded, params.GeneratorG, modulus)
+		if err != nil {
+			return state, err
+		}
+		evals, err := f.EvalCosetNTT(params.EvaluationDomain[0], params.GeneratorH, uint64(n), modulus)
+		if err != nil {
+			return state, err
+		}
+		state.ExtensionColumns[i], state.ExtensionEvals[i] = padded, evals
+		state.ExtensionRoots[i] = DomainHash(evals)
+		state.Channel.Send(state.ExtensionRoots[i])
+	}
+	return state, nil
+}
+
+// BuildComposition combines the constraint quotients into the composition
+// polynomial and evaluates it over the evaluation domain.
+func BuildComposition(state *ProverState) (*ProverState, error) {
+
+	if state.Params == nil {
+		return state, errNoParams
+	}
+	if !state.traceCommitted {
+		return state, errTraceNotCommitted
+	}
+	if len(state.Constraints) == 0 {
+		var err error
+		switch air := state.AIR.(type) {
+		case nil, FibonacciAIR:
+			state.Constraints, err = ProgramConstraints(state.Params.Polynomial.Clone(0), state.Params.GeneratorG)
+		case RandomizedAIR:
+			state.Constraints, err = ExtendedConstraintQuotients(air, state.Params, state.ExtensionChallenge, state.ExtensionEvals)
+		default:
+			state.Constraints, err = ConstraintQuotients(air, state.Params)
+		}
+		if err != nil {
+			return state, err
+		}
+	}
+	state.CompositionPoly = CompositionPolynomial(state.Constraints, state.Channel, state.Config)
+	state.CompositionEvals = evalDomain(state.CompositionPoly, state.Params.EvaluationDomain, state.Config.arena())
+	return state, nil
+}
+
+// CommitComposition sends the commitment of the composition polynomial
+// evaluations trough the channel. A composition of degree at least the
+// trace domain size is split in columns whose joint commitment is sent
+// first, the commitment sent next is that of their combination with
+// weights drawn from the channel.
+func CommitComposition(state *ProverState) (*ProverState, error) {
+
+	if len(state.CompositionEvals) == 0 {
+		return state, errNoComposition
+	}
+	n := len(state.Params.SubgroupG)
+	if state.CompositionPoly.Degree() < n {
+		state.CompositionRoot = DomainHashParallel(state.CompositionEvals)
+		state.Channel.Send(state.CompositionRoot)
+		return state, nil
+	}
+
+	field := state.Params.GeneratorG.Field()
+	state.CompositionColumns = SplitComposition(state.CompositionPoly, n)
+	state.ColumnEvals, state.ColumnsRoot = CommitCompositionColumns(state.CompositionColumns, state.Params.EvaluationDomain)
+	state.Channel.Send(state.ColumnsRoot)
+	weights := make([]algebra.FieldElement, len(state.CompositionColumns))
+	for j := range weights {
+		weights[j] = field.NewFieldElement(state.Channel.RandFE(field.Modulus()))
+	}
+	state.ColumnsMix = combine(state.CompositionColumns, weights)
+	state.ColumnsMixEvals = make([]algebra.FieldElement, len(state.Params.EvaluationDomain))
+	values := make([]algebra.FieldElement, len(state.ColumnEvals))
+	for i := range state.ColumnsMixEvals {
+		for j, evals := range state.ColumnEvals {
+			values[j] = evals[i]
+		}
+		state.ColumnsMixEvals[i] = mixColumns(weights, values)
+	}
+	state.CompositionRoot = DomainHashParallel(state.ColumnsMixEvals)
+	state.Channel.Send(state.CompositionRoot)
+	return state, nil
+}
+
+// RunFRI commits to the FRI layers of the composition polynomial, or of
+// the combination of its columns when split, folding them with the inverse
+// table of the evaluation domain unless the config inverts per fold and
+// with an arena when the config uses one.
+func RunFRI(state *ProverState) (*ProverState, error) {
+
+	if len(state.CompositionRoot) == 0 {
+		return state, errCompositionNotCommitted
+	}
+	if state.FoldInverses == nil && !state.Config.InvertPerFold {
+		state.FoldInverses = FoldInverses(state.Params.EvaluationDomain)
+	}
+	p, evals := state.CompositionPoly, state.CompositionEvals
+	if len(state.CompositionColumns) > 0 {
+		p, evals = state.ColumnsMix, state.ColumnsMixEvals
+	}
+	var err error
+	state.FRIDomains, state.FRIPolys, state.FRILayers, state.FRIRoots, err = generateFRICommitment(p, state.Params.EvaluationDomain, evals, state.CompositionRoot, state.FoldInverses, state.Channel, state.Config)
+	return state, err
+}
+
+// OpenQueries decommits the trace and the FRI layers on the queries drawn
+// from the channel, after grinding the proof of work if enabled, followed
+// by the extension columns of a RandomizedAIR and the composition columns
+// when the composition is split.
+func OpenQueries(state *ProverState) (*ProverState, error) {
+
+	if len(state.FRILayers) == 0 {
+		return state, errFRINotRun
+	}
+	if state.Config.ProofOfWorkBits > 0 {
+		nonce, err := state.Channel.ProofOfWork(state.Config.ProofOfWorkBits)
+		if err != nil {
+			return state, err
+		}
+		state.ProofOfWorkNonce = nonce
+	}
+	if len(state.ExtensionEvals) == 0 && len(state.ColumnEvals) == 0 {
+		queries, err := FRIDecommit(state.Channel, state.Params.PolynomialEvaluations, state.FRILayers)
+		if err != nil {
+			return state, err
+		}
+		state.Queries = queries
+		return state, nil
+	}
+
+	n := len(state.Params.EvaluationDomain)
+	blowup := n / len(state.Params.SubgroupG)
+	state.Queries = nil
+	for _, index := range drawQueryIndices(state.Channel, n) {
+		queries, err := FRIDecommitQueries(state.Channel, state.Params.PolynomialEvaluations, state.FRILayers, []int{index})
+		if err != nil {
+			return state, err
+		}
+		query := queries[0]
+		for _, evals := range state.ExtensionEvals {
+			leaves := DomainBytes(evals)
+			for _, k := range state.AIR.Offsets() {
+				idx := ((index+k*blowup)%n + n) % n
+				ap, err := merkle.Proof(leaves, idx)
+				if err != nil {
+					return state, err
+				}
+				state.Channel.Send(leaves[idx])
+				state.Channel.Send(serializeAuditPath(ap))
+				query.Extension = append(query.Extension, FRILayerOpening{idx, evals[idx], auditPathHashes(ap)})
+			}
+		}
+		if len(state.ColumnEvals) > 0 {
+			opening, err := OpenCompositionColumns(state.ColumnEvals, index)
+			if err != nil {
+				return state, err
+			}
+			state.Channel.Send(PackFieldElements(opening.Values))
+			state.Channel.Send(serializeLayerPath(opening.Index, opening.Path))
+			query.Columns = opening
+		}
+		state.Queries = append(state.Queries, query)
+	}
+	return state, nil
+}
+
+// proveStages are the proving stages in the order Prove runs them, the
+// queries are opened last so ProveCommitOnly runs all the others.
+var proveStages = []func(*ProverState) (*ProverState, error){
+	CommitTrace,
+	CommitExtension,
+	BuildComposition,
+	CommitComposition,
+	RunFRI,
+	OpenQueries,
+}
+
+// Prove runs the proving stages in order over the domain parameters.
+func Prove(params *DomainParameters, cfg ProverConfig) (*ProverState, error) {
+	return ProveAIR(params, FibonacciAIR{}, nil, cfg)
+}
+
+// ProveAIR runs the proving stages in order over the domain parameters
+// under the AIR with the given constraint quotients, nil constraints are
+// derived from the AIR by BuildComposition.
+func ProveAIR(params *DomainParameters, air AIR, constraints []poly.Polynomial, cfg ProverConfig) (*ProverState, error) {
+
+	state := NewProverState(params, cfg)
+	state.AIR, state.Constraints = air, constraints
+	return runStages(state, proveStages)
+}
+
+// runStages runs the stages in order over the prover state.
+func runStages(state *ProverState, stages []func(*ProverState) (*ProverState, error)) (*ProverState, error) {
+
+	var err error
+	for _, stage := range stages {
+		if state, err = stage(state); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// A PartialProof holds the commitments of a proof without its query
+// openings, the openings are deferred to CompleteProof e.g to draw the
+// queries of several proofs from a common transcript once all of them are
+// committed.
+
+var errNoPartialProof = errors.New("partial proof has no prover state, run ProveCommitOnly first")
+
+// PartialProof holds the commitments of a proof whose queries aren't
+// opened yet.
+type PartialProof struct {
+	ExtensionRoots [][]byte
+	ColumnsRoot    []byte
+	// FRIRoots starts with the composition root.
+	FRIRoots  [][]byte
+	LastLayer algebra.FieldElement
+	// Channel is the transcript after the commitments, the queries drawn
+	// from it by CompleteProof are those Prove would have opened.
+	Channel *Channel
+
+	state *ProverState
+}
+
+// ProveCommitOnly runs the proving stages up to RunFRI over the domain
+// parameters and returns their commitments.
+func ProveCommitOnly(params *DomainParameters, cfg ProverConfig) (*PartialProof, error) {
+
+	state, err := runStages(NewProverState(params, cfg), proveStages[:len(proveStages)-1])
+	if err != nil {
+		return nil, err
+	}
+	lastLayer, ok := IsConstantLayer(state.FRILayers[len(state.FRILayers)-1])
+	if !ok {
+		return nil, errLastLayerNotConstant
+	}
+	return &PartialProof{
+		ExtensionRoots: state.ExtensionRoots,
+		ColumnsRoot:    state.ColumnsRoot,
+		FRIRoots:       state.FRIRoots,
+		LastLayer:      lastLayer,
+		Channel:        state.Channel,
+		state:          state,
+	}, nil
+}
+
+// CompleteProof opens the queries of the partial proof drawn from ch, after
+// grinding the proof of work if enabled. The proof only verifies with
+// Verify when ch is the partial proof's Channel, a transcript that absorbed
+// more data must be replayed by the verifier. The queries are opened on a
+// copy of ch so neither ch nor the partial proof is changed, completing the
+// same partial proof twice gives the same proof.
+func CompleteProof(partial *PartialProof, ch *Channel) (StarkProof, error) {
+
+	if partial == nil || partial.state == nil {
+		return StarkProof{}, errNoPartialProof
+	}
+	state := *partial.state
+	state.Channel = ch.clone()
+	if _, err := OpenQueries(&state); err != nil {
+		return StarkProof{}, err
+	}
+	return state.Proof()
+}
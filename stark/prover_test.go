@@ -0,0 +1,273 @@
+package stark
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProveStages(t *testing.T) {
+	params, constraints := loadFibonacci(t)
+
+	proof, err := Prove(params, ProverConfig{})
+	assert.NoError(t, err)
+
+	// quotients computed ahead match those derived by BuildComposition
+	state, err := ProveAIR(params, FibonacciAIR{}, constraints, ProverConfig{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, proof.Channel.State, state.Channel.State)
+	assert.Equal(t, proof.Channel.Proof, state.Channel.Proof)
+	assert.Equal(t, proof.CompositionRoot, state.CompositionRoot)
+	assert.Equal(t, proof.FRIRoots, state.FRIRoots)
+	assert.Equal(t, len(proof.Queries), len(state.Queries))
+	for i := range proof.Queries {
+		assert.Equal(t, proof.Queries[i].Index, state.Queries[i].Index)
+	}
+	assert.Equal(t, 0, proof.CompositionPoly.Compare(&state.CompositionPoly))
+}
+
+func TestProveStagesOrder(t *testing.T) {
+	params, _ := loadFibonacci(t)
+
+	state := NewProverState(params, ProverConfig{})
+	_, err := BuildComposition(state)
+	assert.ErrorIs(t, err, errTraceNotCommitted)
+	_, err = CommitComposition(state)
+	assert.ErrorIs(t, err, errNoComposition)
+	_, err = RunFRI(state)
+	assert.ErrorIs(t, err, errCompositionNotCommitted)
+	_, err = OpenQueries(state)
+	assert.ErrorIs(t, err, errFRINotRun)
+
+	_, err = CommitTrace(NewProverState(nil, ProverConfig{}))
+	assert.ErrorIs(t, err, errNoParams)
+}
+
+func TestCompleteProof(t *testing.T) {
+	params, _ := loadFibonacci(t)
+
+	state, err := Prove(params, ProverConfig{})
+	assert.NoError(t, err)
+	expected, err := state.Proof()
+	assert.NoError(t, err)
+
+	partial, err := ProveCommitOnly(params, ProverConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, expected.FRI.Roots, partial.FRIRoots)
+	assert.Equal(t, expected.FRI.LastLayer, partial.LastLayer)
+
+	channel := *partial.Channel
+	proof, err := CompleteProof(partial, partial.Channel)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, proof)
+
+	// the partial proof channel isn't advanced, completing it again gives
+	// the same proof
+	assert.Equal(t, channel.State, partial.Channel.State)
+	assert.Equal(t, channel.Proof, partial.Channel.Proof)
+	again, err := CompleteProof(partial, partial.Channel)
+	assert.NoError(t, err)
+	assert.Equal(t, proof, again)
+
+	ok, err := VerifyWithCommitment(PrimeField.Modulus(), params.EvaluationRoot, params.PublicInputs(), proof, ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = CompleteProof(&PartialProof{}, NewChannel())
+	assert.ErrorIs(t, err, errNoPartialProof)
+}
+
+// runningProductAIR extends the Fibonacci program with the running product
+// z_i = Prod_{j<=i}(gamma - a_j) of the trace rows, its transition
+// z_{i+1} = z_i.(gamma - a_{i+1}) reads both the trace and the extension.
+type runningProductAIR struct {
+	FibonacciAIR
+	trace []algebra.FieldElement
+}
+
+func (runningProductAIR) NumConstraints() int {
+	return 4
+}
+
+func (a runningProductAIR) ConstraintRows(i int) []int {
+	if i < 3 {
+		return a.FibonacciAIR.ConstraintRows(i)
+	}
+	rows := make([]int, len(a.trace)-1)
+	for j := range rows {
+		rows[j] = j
+	}
+	return rows
+}
+
+func (a runningProductAIR) ConstraintDegree(i int) int {
+	if i < 3 {
+		return a.FibonacciAIR.ConstraintDegree(i)
+	}
+	return 2
+}
+
+func (runningProductAIR) NumExtensionColumns() int {
+	return 1
+}
+
+func (a runningProductAIR) ExtensionColumns(gamma algebra.FieldElement) [][]algebra.FieldElement {
+	z := []algebra.FieldElement{PrimeField.Sub(gamma, a.trace[0])}
+	for i := 1; i < len(a.trace); i++ {
+		z = append(z, PrimeField.Mul(z[i-1], PrimeField.Sub(gamma, a.trace[i])))
+	}
+	return [][]algebra.FieldElement{z}
+}
+
+func (a runningProductAIR) EvalExtendedNumerators(x, g, gamma algebra.FieldElement, values []algebra.FieldElement, extension [][]algebra.FieldElement) ([]algebra.FieldElement, error) {
+	nums, err := a.FibonacciAIR.EvalNumerators(x, g, values)
+	if err != nil {
+		return nil, err
+	}
+	z := extension[0]
+	return append(nums, PrimeField.Sub(z[1], PrimeField.Mul(z[0], PrimeField.Sub(gamma, values[1])))), nil
+}
+
+// forgedProductAIR commits to an extension column that isn't the running
+// product.
+type forgedProductAIR struct {
+	runningProductAIR
+}
+
+func (a forgedProductAIR) ExtensionColumns(gamma algebra.FieldElement) [][]algebra.FieldElement {
+	z := a.runningProductAIR.ExtensionColumns(gamma)[0]
+	z[500] = z[500].Double()
+	return [][]algebra.FieldElement{z}
+}
+
+func TestCommitExtension(t *testing.T) {
+	params, _ := loadFibonacci(t)
+	air := runningProductAIR{trace: params.Trace}
+
+	state := NewProverState(params, ProverConfig{})
+	state.AIR = air
+	_, err := CommitExtension(state)
+	assert.ErrorIs(t, err, errTraceNotCommitted)
+
+	state, err = CommitTrace(state)
+	assert.NoError(t, err)
+	state, err = CommitExtension(state)
+	assert.NoError(t, err)
+
+	// the challenge is drawn once the trace is committed and the column is
+	// committed over the evaluation domain
+	ch := NewChannel()
+	ch.Send(params.EvaluationRoot)
+	sendPublicOutputs(ch, state.PublicOutputs)
+	assert.True(t, state.ExtensionChallenge.Equal(PrimeField.NewFieldElement(ch.RandFE(PrimeField.Modulus()))))
+	assert.Len(t, state.ExtensionColumns[0], len(params.SubgroupG))
+	assert.Len(t, state.ExtensionEvals[0], len(params.EvaluationDomain))
+	ch.Send(DomainHash(state.ExtensionEvals[0]))
+	assert.Equal(t, ch.State, state.Channel.State)
+	assert.Equal(t, [][]byte{DomainHash(state.ExtensionEvals[0])}, state.ExtensionRoots)
+
+	// the evaluations extend the column : they interpolate back to a
+	// polynomial taking the column values over the trace domain
+	z := state.ExtensionColumns[0]
+	f, err := InterpolateSubgroup(z, params.GeneratorG, PrimeField.Modulus())
+	assert.NoError(t, err)
+	assert.True(t, f.EvalAt(params.EvaluationDomain[17]).Equal(state.ExtensionEvals[0][17]))
+
+	// an AIR without extension columns leaves the transcript as is
+	state, err = CommitTrace(NewProverState(params, ProverConfig{}))
+	assert.NoError(t, err)
+	before := state.Channel.State
+	state, err = CommitExtension(state)
+	assert.NoError(t, err)
+	assert.Equal(t, before, state.Channel.State)
+	assert.Nil(t, state.ExtensionRoots)
+}
+
+func TestProveRandomizedAIR(t *testing.T) {
+	params, _ := loadFibonacci(t)
+	m := PrimeField.Modulus()
+	air := runningProductAIR{trace: params.Trace}
+	pub := params.PublicInputs()
+	pub.AIR = air
+
+	proof, err := proveAIR(params, air, nil)
+	assert.NoError(t, err)
+	assert.Len(t, proof.ExtensionRoots, 1)
+	assert.Len(t, proof.FRI.Queries[0].Extension, len(air.Offsets()))
+
+	result, err := Verify(m, params.EvaluationRoot, pub, proof, ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, result.OK)
+
+	b, err := json.Marshal(proof)
+	assert.NoError(t, err)
+	var decoded StarkProof
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, proof.FRI.Queries[1].Extension, decoded.FRI.Queries[1].Extension)
+	ok, err := VerifyStream(m, params.EvaluationRoot, pub, bytes.NewReader(b), ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// the extension openings are checked against their commitment
+	tampered := proof
+	tampered.FRI.Queries = append([]FRIQuery{}, proof.FRI.Queries...)
+	tampered.FRI.Queries[1].Extension = append([]FRILayerOpening{}, proof.FRI.Queries[1].Extension...)
+	tampered.FRI.Queries[1].Extension[1].Value = tampered.FRI.Queries[1].Extension[1].Value.Double()
+	result, err = Verify(m, params.EvaluationRoot, pub, tampered, ProverConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, CheckMerkle, result.Failure.Check)
+	assert.Equal(t, 1, result.Failure.Query)
+
+	// the root count must be the one of the AIR
+	tampered = proof
+	tampered.ExtensionRoots = nil
+	_, err = Verify(m, params.EvaluationRoot, pub, tampered, ProverConfig{})
+	assert.ErrorIs(t, err, errExtensionRoots)
+	fibProof := proof
+	fibProof.ExtensionRoots = [][]byte{proof.ExtensionRoots[0]}
+	_, err = Verify(m, params.EvaluationRoot, params.PublicInputs(), fibProof, ProverConfig{})
+	assert.ErrorIs(t, err, errExtensionRoots)
+
+	// the extension constraint reads the committed column, a column that
+	// isn't the running product leaves a quotient that isn't a polynomial
+	_, err = proveAIR(params, forgedProductAIR{air}, nil)
+	assert.ErrorContains(t, err, "constraint 3 isn't divisible")
+}
+
+// BenchmarkProve proves the Fibonacci program over fixed parameters, the
+// channel is seeded by the protocol identifier only so every iteration
+// draws the same challenges and produces the same proof, which is checked.
+// Along with ns/op and the allocations it reports the coefficient
+// multiplications counted by poly.MulCount.
+func BenchmarkProve(b *testing.B) {
+	params, err := DefaultFibonacciParameters()
+	if err != nil {
+		b.Fatal(err)
+	}
+	constraints, err := ConstraintQuotients(FibonacciAIR{}, params)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var root []byte
+	b.ReportAllocs()
+	b.ResetTimer()
+	muls := poly.MulCount()
+	for i := 0; i < b.N; i++ {
+		state, err := ProveAIR(params, FibonacciAIR{}, constraints, ProverConfig{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		last := state.FRIRoots[len(state.FRIRoots)-1]
+		if root != nil && !bytes.Equal(root, last) {
+			b.Fatal("proof differs across iterations")
+		}
+		root = last
+	}
+	b.ReportMetric(float64(poly.MulCount()-muls)/float64(b.N), "muls/op")
+}
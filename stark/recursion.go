@@ -0,0 +1,136 @@
+package stark
+
+import (
+	"errors"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+)
+
+// Verifying a proof inside another proof requires expressing the verifier's
+// computation as an AIR. As a first step the VerifierProgram covers the FRI
+// folding of a single query : row i of the trace holds v_i the value of the
+// ith FRI layer at the query point, starting with the opened composition
+// value, and the transition checks the folding of foldPair
+// v_{i+1} = (v_i + s_i)/2 + beta_i.(v_i - s_i)/2x_i
+// written without divisions as
+// 2x_i.v_{i+1} - x_i.(v_i + s_i) - beta_i.(v_i - s_i) = 0
+// where the sibling openings s_i, the challenges beta_i and the layer points
+// x_i (x_{i+1} = x_i^2) are public columns. A boundary constraint asserts
+// that the last row is the last layer constant.
+// The merkle path checks and the transcript aren't covered yet.
+
+var errFoldingTrace = errors.New("FRI query openings don't match the folding challenges")
+
+// VerifierProgram is the AIR of the FRI folding of a single query.
+type VerifierProgram struct {
+	// LastLayer is the constant of the last FRI layer.
+	LastLayer algebra.FieldElement
+
+	trace    []algebra.FieldElement
+	foldings int
+	// the public columns interpolated over the trace domain
+	siblings, betas, points poly.Polynomial
+}
+
+// NewVerifierProgram builds the folding program of the query at the domain
+// point x given the FRI challenges and the last layer constant, the trace
+// is padded to a power of two by repeating the last layer.
+func NewVerifierProgram(query FRIQuery, betas []algebra.FieldElement, x, lastLayer algebra.FieldElement) (*VerifierProgram, error) {
+
+	layers := len(betas)
+	if layers == 0 || len(query.Layers) != layers || len(query.Siblings) != layers {
+		return nil, errFoldingTrace
+	}
+	n := 1
+	for n < layers+1 {
+		n <<= 1
+	}
+	field := x.Field()
+	trace := make([]algebra.FieldElement, n)
+	siblings := make([]algebra.FieldElement, n)
+	challenges := make([]algebra.FieldElement, n)
+	points := make([]algebra.FieldElement, n)
+	trace[0] = query.Layers[0].Value
+	for i := range trace {
+		siblings[i], challenges[i], points[i] = field.Zero(), field.Zero(), field.Zero()
+		if i < layers {
+			siblings[i], challenges[i], points[i] = query.Siblings[i].Value, betas[i], x
+			trace[i+1] = foldPair(trace[i], siblings[i], x, betas[i])
+			x = x.Square()
+		} else if i > layers {
+			trace[i] = trace[layers]
+		}
+	}
+
+	g := subgroupGenerator(uint64(n))
+	columns := make([]poly.Polynomial, 3)
+	for i, values := range [][]algebra.FieldElement{siblings, challenges, points} {
+		p, err := NewPeriodicColumn(values...).Polynomial(g, n)
+		if err != nil {
+			return nil, err
+		}
+		columns[i] = p
+	}
+	return &VerifierProgram{
+		LastLayer: lastLayer,
+		trace:     trace,
+		foldings:  layers,
+		siblings:  columns[0],
+		betas:     columns[1],
+		points:    columns[2],
+	}, nil
+}
+
+// Trace returns the padded folding trace, its first len(betas)+1 rows are
+// the values of the FRI layers at the query point.
+func (vp *VerifierProgram) Trace() []algebra.FieldElement {
+	return append([]algebra.FieldElement{}, vp.trace...)
+}
+
+// Offsets returns the rows i and i+1 read by the folding.
+func (*VerifierProgram) Offsets() []int {
+	return []int{0, 1}
+}
+
+// NumConstraints returns the folding transition and the last layer boundary.
+func (*VerifierProgram) NumConstraints() int {
+	return 2
+}
+
+// ConstraintRows returns the folded rows for the transition and the last
+// layer row for the boundary.
+func (vp *VerifierProgram) ConstraintRows(i int) []int {
+	if i == 1 {
+		return []int{vp.foldings}
+	}
+	rows := make([]int, vp.foldings)
+	for j := range rows {
+		rows[j] = j
+	}
+	return rows
+}
+
+// ConstraintDegree returns 2 for the transition whose public columns are
+// of the degree of the trace polynomial and 1 for the boundary.
+func (*VerifierProgram) ConstraintDegree(i int) int {
+	if i == 0 {
+		return 2
+	}
+	return 1
+}
+
+// EvalNumerators evaluates the folding and the boundary numerators at x,
+// values holds the trace values at x and g.x.
+func (vp *VerifierProgram) EvalNumerators(x, g algebra.FieldElement, values []algebra.FieldElement) ([]algebra.FieldElement, error) {
+
+	if len(values) != 2 {
+		return nil, errTraceValuesCount
+	}
+	field := x.Field()
+	s, beta, xi := vp.siblings.EvalAt(x), vp.betas.EvalAt(x), vp.points.EvalAt(x)
+	v, next := values[0], values[1]
+	fold := field.Sub(field.Mul(xi.Double(), next), field.Mul(xi, field.Add(v, s)))
+	fold = field.Sub(fold, field.Mul(beta, field.Sub(v, s)))
+	return []algebra.FieldElement{fold, field.Sub(v, vp.LastLayer)}, nil
+}
@@ -0,0 +1,88 @@
+package stark
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/stretchr/testify/assert"
+)
+
+// friChallenges replays the transcript of the Fibonacci proof up to the
+// FRI challenges.
+func friChallenges(params *DomainParameters, proof StarkProof) []algebra.FieldElement {
+	ch := NewChannel()
+	ch.Send(params.EvaluationRoot)
+	sendPublicOutputs(ch, proof.PublicOutputs)
+	IndependentRandomCombiner{}.Coefficients(FibonacciAIR{}.NumConstraints(), ch)
+	ch.Send(proof.FRI.Roots[0])
+	var betas []algebra.FieldElement
+	for _, root := range proof.FRI.Roots[1:] {
+		betas = append(betas, PrimeField.NewFieldElement(ch.RandFE(PrimeField.Modulus())))
+		ch.Send(root)
+	}
+	return betas
+}
+
+func TestVerifierProgram(t *testing.T) {
+	params, proof := loadFibonacciProof(t)
+	betas := friChallenges(params, proof)
+	query := proof.FRI.Queries[0]
+	x := params.EvaluationDomain[query.Index]
+
+	program, err := NewVerifierProgram(query, betas, x, proof.FRI.LastLayer)
+	assert.NoError(t, err)
+	trace := program.Trace()
+	assert.Len(t, trace, 16)
+	for i := 1; i < len(betas); i++ {
+		assert.True(t, trace[i].Equal(query.Layers[i].Value), "layer %d", i)
+	}
+	assert.True(t, trace[len(betas)].Equal(proof.FRI.LastLayer))
+
+	tb := NewTraceBuilder()
+	for _, v := range trace {
+		tb.AppendRow([]algebra.FieldElement{v})
+	}
+	// the trace openings of the queries are 8 points apart
+	assert.LessOrEqual(t, BlowupFor(program, len(trace), len(trace)), 8)
+	programParams, err := tb.Finalize(program, 8)
+	assert.NoError(t, err)
+	constraints, err := ConstraintQuotients(program, programParams)
+	assert.NoError(t, err)
+	assert.Len(t, constraints, 2)
+
+	state, err := ProveAIR(programParams, program, constraints, ProverConfig{})
+	assert.NoError(t, err)
+	// the composition of the program reaches the trace domain size so it is
+	// split in columns, the composition recombined at every query matches
+	// the program constraints
+	assert.NotEmpty(t, state.ColumnsRoot)
+	ch := NewChannel()
+	ch.Send(programParams.EvaluationRoot)
+	sendPublicOutputs(ch, state.PublicOutputs)
+	coeffs := IndependentRandomCombiner{}.Coefficients(program.NumConstraints(), ch)
+	for _, q := range state.Queries {
+		x := programParams.EvaluationDomain[q.Index]
+		composition, ok := RecombineComposition(state.ColumnsRoot, q.Columns, x, len(programParams.SubgroupG))
+		assert.True(t, ok)
+		opening := ColumnOpening{
+			Index:       q.Index,
+			X:           x,
+			Trace:       []algebra.FieldElement{q.Trace[0].Value, q.Trace[1].Value},
+			Composition: composition,
+		}
+		ok, err := CheckCompositionAtQueries([]ColumnOpening{opening}, coeffs, program, programParams.GeneratorG)
+		assert.NoError(t, err)
+		assert.True(t, ok, "query %d", q.Index)
+	}
+
+	// a wrong last layer doesn't satisfy the boundary
+	wrong, err := NewVerifierProgram(query, betas, x, proof.FRI.LastLayer.Double())
+	assert.NoError(t, err)
+	_, err = tb.Finalize(wrong, 8)
+	assert.Error(t, err)
+	_, err = ConstraintQuotients(wrong, programParams)
+	assert.EqualError(t, err, "constraint 1 isn't divisible by the zerofier of its rows")
+
+	_, err = NewVerifierProgram(query, betas[1:], x, proof.FRI.LastLayer)
+	assert.ErrorIs(t, err, errFoldingTrace)
+}
@@ -0,0 +1,165 @@
+package stark
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+)
+
+// A constraint doesn't have to hold on every row of the trace e.g a
+// boundary holds on the first row only and a transition reading row i+1
+// can't hold on the last row. A Selector describes the rows on which a
+// constraint is enabled, the constraint numerator is divided by the
+// zerofier of those rows so it is ignored everywhere else.
+// Selecting every kth row of the whole trace domain of size n has the
+// sparse zerofier x^(n/k) - g^(r.n/k) for the rows r mod k, other
+// selections multiply the linear factors (x - g^i) of their rows.
+
+// Selector selects the rows Start, Start+Step, ... below End of a trace of
+// n rows, an End lower or equal to zero is relative to n e.g End = -1
+// selects up to the row n-2.
+type Selector struct {
+	Start int
+	End   int
+	Step  int
+}
+
+// AllRows selects every row of the trace.
+func AllRows() Selector {
+	return Selector{Start: 0, End: 0, Step: 1}
+}
+
+// FirstRow selects the first row of the trace.
+func FirstRow() Selector {
+	return Selector{Start: 0, End: 1, Step: 1}
+}
+
+// RowRange selects the rows start to end - 1.
+func RowRange(start, end int) Selector {
+	return Selector{Start: start, End: end, Step: 1}
+}
+
+// EveryNthRow selects the rows r such that r = offset mod step.
+func EveryNthRow(step, offset int) Selector {
+	return Selector{Start: offset, End: 0, Step: step}
+}
+
+// end returns the row bounding the selection over n rows.
+func (s Selector) end(n int) int {
+	if s.End <= 0 {
+		return n + s.End
+	}
+	return s.End
+}
+
+// Rows returns the selected rows of a trace of n rows.
+// It panics if Step < 1.
+func (s Selector) Rows(n int) []int {
+
+	if s.Step < 1 {
+		panic(fmt.Sprintf("invalid selector step %d", s.Step))
+	}
+	var rows []int
+	for i := s.Start; i < s.end(n) && i < n; i += s.Step {
+		if i >= 0 {
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
+
+// periodic reports whether the selection covers a whole residue class of
+// the rows modulo Step.
+func (s Selector) periodic(n int) bool {
+	return s.Step >= 1 && n%s.Step == 0 && s.Start >= 0 && s.Start < s.Step && s.end(n) > n-s.Step
+}
+
+// Zerofier returns the polynomial vanishing exactly on the selected rows of
+// the trace domain of size n generated by g.
+func (s Selector) Zerofier(g algebra.FieldElement, n int) poly.Polynomial {
+
+	m := g.Field().Modulus()
+	if s.periodic(n) {
+		k := n / s.Step
+		root := g.Exp(algebra.FromInt64(int64(s.Start * k)))
+		return poly.NewPolynomialInts(1).MulXPow(k).Sub(poly.NewPolynomial([]algebra.FieldElement{root}), m)
+	}
+	z := poly.NewPolynomialInts(1)
+	for _, i := range s.Rows(n) {
+		root := g.Exp(algebra.FromInt64(int64(i)))
+		z = z.Mul(poly.NewPolynomialInts(0, 1).Sub(poly.NewPolynomial([]algebra.FieldElement{root}), m), m)
+	}
+	return z
+}
+
+// EvalZerofier evaluates the zerofier of the selected rows at x, see
+// Zerofier.
+func (s Selector) EvalZerofier(x, g algebra.FieldElement, n int) algebra.FieldElement {
+
+	if s.periodic(n) {
+		k := int64(n / s.Step)
+		return x.Field().Sub(x.Exp(algebra.FromInt64(k)), g.Exp(algebra.FromInt64(int64(s.Start)*k)))
+	}
+	return EvalZerofier(x, g, s.Rows(n))
+}
+
+// TransitionConstraint is a constraint numerator over the trace values at
+// the offsets of its TransitionConstraints enabled on the rows of its
+// selector.
+type TransitionConstraint struct {
+	Selector Selector
+	// Degree is the degree of the numerator in the trace values.
+	Degree int
+	// Eval evaluates the numerator at x given the generator g of the trace
+	// domain and the trace values f(g^k.x) for each offset k.
+	Eval func(x, g algebra.FieldElement, values []algebra.FieldElement) algebra.FieldElement
+}
+
+var errNoTransitionConstraints = errors.New("no transition constraints")
+
+// TransitionConstraints is the AIR of a set of transition constraints
+// reading the trace at the same offsets over a trace of TraceLen rows,
+// each constraint holds on the rows of its selector.
+type TransitionConstraints struct {
+	TraceLen    int
+	Shifts      []int
+	Constraints []TransitionConstraint
+}
+
+// Offsets returns the row offsets read by the constraints.
+func (tc TransitionConstraints) Offsets() []int {
+	return tc.Shifts
+}
+
+// NumConstraints returns the number of constraints.
+func (tc TransitionConstraints) NumConstraints() int {
+	return len(tc.Constraints)
+}
+
+// ConstraintRows returns the rows selected for the ith constraint.
+func (tc TransitionConstraints) ConstraintRows(i int) []int {
+	return tc.Constraints[i].Selector.Rows(tc.TraceLen)
+}
+
+// ConstraintDegree returns the degree of the ith numerator.
+func (tc TransitionConstraints) ConstraintDegree(i int) int {
+	return tc.Constraints[i].Degree
+}
+
+// EvalNumerators evaluates every numerator at x.
+func (tc TransitionConstraints) EvalNumerators(x, g algebra.FieldElement, values []algebra.FieldElement) ([]algebra.FieldElement, error) {
+
+	if len(tc.Constraints) == 0 {
+		return nil, errNoTransitionConstraints
+	}
+	if len(values) != len(tc.Shifts) {
+		return nil, errTraceValuesCount
+	}
+	nums := make([]algebra.FieldElement, len(tc.Constraints))
+	for i, c := range tc.Constraints {
+		nums[i] = c.Eval(x, g, values)
+	}
+	return nums, nil
+}
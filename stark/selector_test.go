@@ -0,0 +1,61 @@
+package stark
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectorRows(t *testing.T) {
+	assert.Equal(t, []int{0, 1, 2, 3}, AllRows().Rows(4))
+	assert.Equal(t, []int{0}, FirstRow().Rows(4))
+	assert.Equal(t, []int{1, 2}, RowRange(1, 3).Rows(4))
+	assert.Equal(t, []int{0, 1, 2}, Selector{Start: 0, End: -1, Step: 1}.Rows(4))
+	assert.Equal(t, []int{1, 3, 5, 7}, EveryNthRow(2, 1).Rows(8))
+	assert.Panics(t, func() { Selector{}.Rows(4) })
+
+	_, g := skipTrace()
+	x := PrimeField.NewFieldElementFromInt64(31415)
+	for _, s := range []Selector{AllRows(), FirstRow(), RowRange(2, 6), EveryNthRow(2, 1), EveryNthRow(4, 0)} {
+		z := s.Zerofier(g, 8)
+		assert.Equal(t, len(s.Rows(8)), z.Degree())
+		assert.True(t, z.EvalAt(x).Equal(EvalZerofier(x, g, s.Rows(8))))
+		assert.True(t, s.EvalZerofier(x, g, 8).Equal(EvalZerofier(x, g, s.Rows(8))))
+		for _, i := range s.Rows(8) {
+			assert.True(t, z.EvalAt(g.Exp(algebra.FromInt64(int64(i)))).IsZero())
+		}
+	}
+}
+
+func TestTransitionConstraintsSelector(t *testing.T) {
+	// a_{i+1} = a_i + 1 on the first half of the trace only
+	step := TransitionConstraint{
+		Selector: RowRange(0, 4),
+		Degree:   1,
+		Eval: func(x, g algebra.FieldElement, values []algebra.FieldElement) algebra.FieldElement {
+			return PrimeField.Sub(values[1], values[0].AddInt64(1))
+		},
+	}
+	air := TransitionConstraints{TraceLen: 8, Shifts: []int{0, 1}, Constraints: []TransitionConstraint{step}}
+
+	tb := NewTraceBuilder()
+	for _, v := range []int64{5, 6, 7, 8, 9, 100, 3, 42} {
+		tb.AppendRow([]algebra.FieldElement{PrimeField.NewFieldElementFromInt64(v)})
+	}
+	params, err := tb.Finalize(air, 8)
+	assert.NoError(t, err)
+	constraints, err := ConstraintQuotients(air, params)
+	assert.NoError(t, err)
+	assert.Len(t, constraints, 1)
+
+	// enabled on every row but the last the second half breaks it
+	step.Selector = Selector{Start: 0, End: -1, Step: 1}
+	everywhere := TransitionConstraints{TraceLen: 8, Shifts: []int{0, 1}, Constraints: []TransitionConstraint{step}}
+	assert.EqualError(t, params.CheckConstraintsOnTraceDomain(everywhere), "constraint 0 doesn't hold at row 4")
+	_, err = ConstraintQuotients(everywhere, params)
+	assert.Error(t, err)
+
+	_, err = TransitionConstraints{TraceLen: 8, Shifts: []int{0, 1}}.EvalNumerators(params.GeneratorG, params.GeneratorG, nil)
+	assert.ErrorIs(t, err, errNoTransitionConstraints)
+}
@@ -1,11 +1,15 @@
 package stark
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"math/big"
+	"math/bits"
 	"github.com/ayushn2/go-stark.git/algebra"
 	"github.com/ayushn2/go-stark.git/poly"
 	"github.com/ayushn2/go-stark.git/merkle"
@@ -108,84 +112,345 @@ func (params *DomainParameters) MarshalJSON() ([]byte, error) {
 }
 
 // UnmarshalJSON parses a JSON serialized domain parameters instance.
+// It never panics on arbitrary input, malformed numbers, a modulus lower
+// than 2, a trace longer than its subgroup or evaluations that don't match
+// the evaluation domain are reported as errors.
+// Values that aren't reduced modulo the field order (negative or greater
+// than the modulus) are normalized into the field, including the
+// polynomial evaluations, see UnmarshalJSONStrict to reject them instead.
 func (params *DomainParameters) UnmarshalJSON(b []byte) error {
+	return params.ParseJSON(b, false)
+}
 
-	var jsonDomParams JSONDomainParams
-	err := json.Unmarshal(b, &jsonDomParams)
+// UnmarshalJSONStrict parses a JSON serialized domain parameters instance
+// in strict mode (see ParseJSON).
+func (params *DomainParameters) UnmarshalJSONStrict(b []byte) error {
+	return params.ParseJSON(b, true)
+}
+
+// ReadDomainParameters reads JSON serialized domain parameters from r
+// (see UnmarshalJSON).
+func ReadDomainParameters(r io.Reader) (*DomainParameters, error) {
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	params := &DomainParameters{}
+	if err := params.UnmarshalJSON(b); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// WriteTo writes the JSON serialized domain parameters to w and returns
+// the number of bytes written, it implements io.WriterTo.
+func (params *DomainParameters) WriteTo(w io.Writer) (int64, error) {
 
+	b, err := params.MarshalJSON()
 	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+var (
+	errNotReduced   = errors.New("value isn't reduced modulo the field order")
+	errTrailingJSON = errors.New("trailing data after the domain parameters")
+)
+
+// ParseJSON parses a JSON serialized domain parameters instance, in strict
+// mode the values that aren't reduced modulo the field order, the unknown
+// fields (e.g a typo such as "G_genrator" which would otherwise leave the
+// field zero valued) and any data following the JSON object are rejected.
+func (params *DomainParameters) ParseJSON(b []byte, strict bool) error {
+
+	var jsonDomParams JSONDomainParams
+	var err error
+	if strict {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err = dec.Decode(&jsonDomParams); err != nil {
+			return err
+		}
+		if _, err = dec.Token(); err != io.EOF {
+			return errTrailingJSON
+		}
+	} else if err = json.Unmarshal(b, &jsonDomParams); err != nil {
 		return err
 	}
 
 	filedOrder, ok := new(big.Int).SetString(jsonDomParams.Field, 10)
-	field, _ := algebra.NewFiniteField(filedOrder)
 	if !ok {
 		return errors.New("bad number encoding")
 	}
-	params.Trace = make([]algebra.FieldElement, len(jsonDomParams.Trace))
-
-	for i, e := range jsonDomParams.Trace {
+	if filedOrder.Cmp(big.NewInt(1)) <= 0 {
+		return errBadModulus
+	}
+	field, _ := algebra.NewFiniteField(filedOrder)
+	if len(jsonDomParams.Trace) > len(jsonDomParams.SubgroupG) {
+		return errSubgroupSize
+	}
+	if len(jsonDomParams.PolynomialEvaluations) != len(jsonDomParams.EvaluationDomain) {
+		return errEvaluationsCount
+	}
 
+	// parse decodes a field element, normalizing or rejecting values
+	// outside of [0, q) depending on the mode
+	parse := func(e string) (algebra.FieldElement, error) {
 		elem, ok := new(big.Int).SetString(e, 10)
 		if !ok {
-			return errors.New("bad number encoding")
+			return algebra.FieldElement{}, errors.New("bad number encoding")
+		}
+		if strict && (elem.Sign() < 0 || elem.Cmp(filedOrder) >= 0) {
+			return algebra.FieldElement{}, errNotReduced
 		}
-		params.Trace[i] = field.NewFieldElement(elem)
+		return field.NewFieldElement(elem), nil
 	}
-	params.SubgroupG = make([]algebra.FieldElement, len(jsonDomParams.SubgroupG))
-	params.SubgroupH = make([]algebra.FieldElement, len(jsonDomParams.SubgroupH))
-
-	for i, e := range jsonDomParams.SubgroupG {
-		elem, ok := new(big.Int).SetString(e, 10)
-		if !ok {
-			return errors.New("bad number encoding")
+	parseAll := func(elems []string) ([]algebra.FieldElement, error) {
+		res := make([]algebra.FieldElement, len(elems))
+		for i, e := range elems {
+			if res[i], err = parse(e); err != nil {
+				return nil, err
+			}
 		}
-		params.SubgroupG[i] = field.NewFieldElement(elem)
+		return res, nil
 	}
-	for i, e := range jsonDomParams.SubgroupH {
-		elem, ok := new(big.Int).SetString(e, 10)
-		if !ok {
-			return errors.New("bad number encoding")
+
+	if params.Trace, err = parseAll(jsonDomParams.Trace); err != nil {
+		return err
+	}
+	if params.SubgroupG, err = parseAll(jsonDomParams.SubgroupG); err != nil {
+		return err
+	}
+	if params.SubgroupH, err = parseAll(jsonDomParams.SubgroupH); err != nil {
+		return err
+	}
+	if params.GeneratorG, err = parse(jsonDomParams.GeneratorG); err != nil {
+		return err
+	}
+	if params.GeneratorH, err = parse(jsonDomParams.GeneratorH); err != nil {
+		return err
+	}
+	if params.EvaluationDomain, err = parseAll(jsonDomParams.EvaluationDomain); err != nil {
+		return err
+	}
+
+	coeffs, err := parseAll(jsonDomParams.Polynomial)
+	if err != nil {
+		return err
+	}
+	params.Polynomial = poly.NewPolynomial(coeffs)
+
+	evals, err := parseAll(jsonDomParams.PolynomialEvaluations)
+	if err != nil {
+		return err
+	}
+	params.PolynomialEvaluations = make([]*big.Int, len(evals))
+	for i, e := range evals {
+		params.PolynomialEvaluations[i] = e.Big()
+	}
+
+	params.EvaluationRoot, err = hex.DecodeString(jsonDomParams.EvaluationRoot)
+
+	return err
+}
+
+var (
+	errBadModulus          = errors.New("field modulus must be greater than 1")
+	errSubgroupSize        = errors.New("trace is longer than the subgroup G")
+	errNoEvaluations       = errors.New("domain parameters have no polynomial evaluations")
+	errEvaluationsCount    = errors.New("polynomial evaluations count doesn't match the evaluation domain")
+	errEvaluationRootMatch = errors.New("evaluation root doesn't match the polynomial evaluations")
+)
+
+// RecomputeEvaluationRoot recomputes the merkle commitment of the
+// polynomial evaluations over the evaluation domain.
+func (params *DomainParameters) RecomputeEvaluationRoot() ([]byte, error) {
+
+	if len(params.PolynomialEvaluations) == 0 {
+		return nil, errNoEvaluations
+	}
+	field := PrimeField
+	if len(params.EvaluationDomain) > 0 {
+		field = params.EvaluationDomain[0].Field()
+	}
+	evals := make([]algebra.FieldElement, len(params.PolynomialEvaluations))
+	for i, e := range params.PolynomialEvaluations {
+		if e == nil {
+			return nil, errors.New("bad number encoding")
 		}
-		params.SubgroupH[i] = field.NewFieldElement(elem)
+		evals[i] = field.NewFieldElement(e)
 	}
+	return DomainHash(evals), nil
+}
 
-	elemG, _ := new(big.Int).SetString(jsonDomParams.GeneratorG, 10)
-	elemH, _ := new(big.Int).SetString(jsonDomParams.GeneratorH, 10)
+// Validate checks the consistency of the domain parameters i.e that
+// the evaluations cover the evaluation domain and that the evaluation
+// root commits to them.
+func (params *DomainParameters) Validate() error {
 
-	params.GeneratorG = field.NewFieldElement(elemG)
-	params.GeneratorH = field.NewFieldElement(elemH)
+	if len(params.PolynomialEvaluations) != len(params.EvaluationDomain) {
+		return errEvaluationsCount
+	}
+	root, err := params.RecomputeEvaluationRoot()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(root, params.EvaluationRoot) {
+		return errEvaluationRootMatch
+	}
+	return nil
+}
 
-	params.EvaluationDomain = make([]algebra.FieldElement, len(jsonDomParams.EvaluationDomain))
-	for i, e := range jsonDomParams.EvaluationDomain {
-		elem, ok := new(big.Int).SetString(e, 10)
-		if !ok {
-			return errors.New("bad number encoding")
+// TracePolynomialDegree returns the degree of the trace polynomial, an
+// interpolant of the n rows of the trace has degree at most n-1.
+func (params *DomainParameters) TracePolynomialDegree() int {
+	return params.Polynomial.Trim().Degree()
+}
+
+// CheckTraceDegree checks that the trace polynomial is reduced modulo the
+// zerofier x^|G| - 1 of the subgroup G i.e that its degree is lower than
+// the order of G. Two polynomials agreeing on G differ by a multiple of
+// x^|G| - 1 so a larger degree means the trace was interpolated over
+// another domain or the polynomial was altered, the commitments would then
+// fail the low degree test.
+func (params *DomainParameters) CheckTraceDegree() error {
+
+	if degree := params.TracePolynomialDegree(); degree >= len(params.SubgroupG) {
+		return fmt.Errorf("trace polynomial of degree %d isn't reduced modulo the subgroup of order %d", degree, len(params.SubgroupG))
+	}
+	return nil
+}
+
+// Equal checks that the domain parameters hold the same values as other,
+// when they don't it names the first field that differs e.g
+// "SubgroupH[12]" or "Trace length 1023 != 1024".
+// The polynomials are compared modulo the field without their zero
+// coefficients of higher degree, the evaluations modulo the field, so
+// parameters built differently e.g by DefaultFibonacciParameters and
+// decoded from JSON compare equal.
+func (params *DomainParameters) Equal(other *DomainParameters) (bool, string) {
+
+	if params == nil || other == nil {
+		if params == other {
+			return true, ""
 		}
-		params.EvaluationDomain[i] = field.NewFieldElement(elem)
+		return false, "nil parameters"
+	}
+	if diff := diffElements("Trace", params.Trace, other.Trace); diff != "" {
+		return false, diff
+	}
+	if !sameElement(params.GeneratorG, other.GeneratorG) {
+		return false, "GeneratorG"
+	}
+	if diff := diffElements("SubgroupG", params.SubgroupG, other.SubgroupG); diff != "" {
+		return false, diff
+	}
+	if !sameElement(params.GeneratorH, other.GeneratorH) {
+		return false, "GeneratorH"
+	}
+	if diff := diffElements("SubgroupH", params.SubgroupH, other.SubgroupH); diff != "" {
+		return false, diff
+	}
+	if diff := diffElements("EvaluationDomain", params.EvaluationDomain, other.EvaluationDomain); diff != "" {
+		return false, diff
 	}
 
-	coeffs := make([]algebra.FieldElement, len(jsonDomParams.Polynomial))
-	for i, e := range jsonDomParams.Polynomial {
-		elem, ok := new(big.Int).SetString(e, 10)
-		if !ok {
-			return errors.New("bad number encoding")
+	modulus := PrimeField.Modulus()
+	if len(params.Trace) > 0 && params.Trace[0].Field().Modulus() != nil {
+		modulus = params.Trace[0].Field().Modulus()
+	}
+	if !params.Polynomial.Sub(other.Polynomial, modulus).IsZero() {
+		return false, "Polynomial"
+	}
+	evals, otherEvals := params.PolynomialEvaluations, other.PolynomialEvaluations
+	if len(evals) != len(otherEvals) {
+		return false, fmt.Sprintf("PolynomialEvaluations length %d != %d", len(evals), len(otherEvals))
+	}
+	for i := range evals {
+		if evals[i] == nil || otherEvals[i] == nil {
+			if evals[i] != otherEvals[i] {
+				return false, fmt.Sprintf("PolynomialEvaluations[%d]", i)
+			}
+			continue
+		}
+		if algebra.Mod(evals[i], modulus).Cmp(algebra.Mod(otherEvals[i], modulus)) != 0 {
+			return false, fmt.Sprintf("PolynomialEvaluations[%d]", i)
 		}
-		coeffs[i] = field.NewFieldElement(elem)
 	}
+	if !bytes.Equal(params.EvaluationRoot, other.EvaluationRoot) {
+		return false, "EvaluationRoot"
+	}
+	return true, ""
+}
 
-	params.Polynomial = poly.NewPolynomial(coeffs)
-	params.PolynomialEvaluations = make([]*big.Int, len(jsonDomParams.PolynomialEvaluations))
-	for i, e := range jsonDomParams.PolynomialEvaluations {
-		elem, ok := new(big.Int).SetString(e, 10)
-		if !ok {
-			return errors.New("bad number encoding")
+// diffElements names the first element that differs between a and b, or
+// their lengths when they differ, it returns "" if they're equal.
+func diffElements(name string, a, b []algebra.FieldElement) string {
+	if len(a) != len(b) {
+		return fmt.Sprintf("%s length %d != %d", name, len(a), len(b))
+	}
+	for i := range a {
+		if !sameElement(a[i], b[i]) {
+			return fmt.Sprintf("%s[%d]", name, i)
 		}
-		params.PolynomialEvaluations[i] = elem
 	}
+	return ""
+}
+
+// sameElement compares field elements, the zero value FieldElement only
+// equals itself rather than panicking.
+func sameElement(a, b algebra.FieldElement) bool {
+	if a.Field().Modulus() == nil || b.Field().Modulus() == nil {
+		return a.Field().Modulus() == nil && b.Field().Modulus() == nil
+	}
+	return a.Equal(b)
+}
 
-	params.EvaluationRoot,_ = hex.DecodeString(jsonDomParams.EvaluationRoot)
+// CheckConstraintsOnTraceDomain evaluates the numerator of every constraint
+// of the AIR on every trace row where it must hold and reports the first
+// row and constraint where it doesn't vanish. Unlike the proof this check
+// works directly on the trace so it pinpoints constraint bugs.
+func (params *DomainParameters) CheckConstraintsOnTraceDomain(air AIR) error {
 
+	rows := make(map[int][]int)
+	for c := 0; c < air.NumConstraints(); c++ {
+		for _, r := range air.ConstraintRows(c) {
+			rows[r] = append(rows[r], c)
+		}
+	}
+	offsets := air.Offsets()
+	for r := 0; r < len(params.Trace); r++ {
+		constraints, ok := rows[r]
+		if !ok {
+			continue
+		}
+		values := make([]algebra.FieldElement, len(offsets))
+		for i, k := range offsets {
+			switch {
+			case r+k >= 0 && r+k < len(params.Trace):
+				values[i] = params.Trace[r+k]
+			case len(params.Polynomial) > 0:
+				// rows outside of the trace are read from its interpolant
+				x := params.GeneratorG.Exp(big.NewInt(int64(r + k)))
+				values[i] = x.Field().NewFieldElement(params.Polynomial.Eval(x.Big(), x.Field().Modulus()))
+			default:
+				return fmt.Errorf("constraint %d at row %d reads row %d outside of the trace", constraints[0], r, r+k)
+			}
+		}
+		x := params.GeneratorG.Exp(big.NewInt(int64(r)))
+		nums, err := air.EvalNumerators(x, params.GeneratorG, values)
+		if err != nil {
+			return err
+		}
+		for _, c := range constraints {
+			if !nums[c].IsZero() {
+				return fmt.Errorf("constraint %d doesn't hold at row %d", c, r)
+			}
+		}
+	}
 	return nil
 }
 
@@ -222,48 +487,64 @@ func GenSeq() []algebra.FieldElement {
 // GenElems returns the list of field elements of the subgroup G of order 1024
 func GenElems(generator algebra.FieldElement, order int) []algebra.FieldElement {
 
-	var subgroup = make([]algebra.FieldElement, order)
-
-	var i int64
+	exps := make([]*algebra.Integer, order)
+	for i := range exps {
+		exps[i] = big.NewInt(int64(i))
+	}
+	field := generator.Field()
+	powers := algebra.ModExpMany(generator.Big(), exps, field.Modulus())
 
-	for i = 0; i < int64(order); i++ {
-		subgroup[i] = generator.Exp(new(big.Int).SetInt64(i))
+	var subgroup = make([]algebra.FieldElement, order)
+	for i, p := range powers {
+		subgroup[i] = field.NewFieldElement(p)
 	}
 
 	return subgroup
 }
 
+// EvalDomainPoint returns the point offset.generator^index of the
+// evaluation domain, a verifier only needs the points at its queries so it
+// derives them on demand rather than holding the whole domain.
+func EvalDomainPoint(offset, generator algebra.FieldElement, index int) algebra.FieldElement {
+	return offset.Field().Mul(offset, generator.Exp(big.NewInt(int64(index))))
+}
+
 // GenerateDomainParameters reproduces the domain parameters required
 // for proof generation :
 // a : the trace of FibSeq(1,3141592)
 // g : generator of the subgroup of order 1024
 // G : the subgroup elements
-// h : generator of the larger evaluation domain of order 8192
+// h : generator of the larger evaluation domain of order 1024*blowup
 // H : the subgroup elements
 // f : interpolated polynomial over G
 // fEval : evaluation of f over the elements of H
 // fEvalCommitmentRoot : merkle commitment of the evaluations of over H
 // fsChan : fiat shamir channel initiated with the commitment root
-func GenerateDomainParameters() ([]algebra.FieldElement, algebra.FieldElement, []algebra.FieldElement, algebra.FieldElement, []algebra.FieldElement, []algebra.FieldElement, poly.Polynomial, []*big.Int, []byte, *Channel) {
+// The blowup must be a power of two, the proof uses a blowup of 8 and a
+// blowup of 1 gives an evaluation domain of the size of the trace domain
+// which is handy to debug constraints (see CheckConstraintsOnTraceDomain).
+func GenerateDomainParameters(blowup int) ([]algebra.FieldElement, algebra.FieldElement, []algebra.FieldElement, algebra.FieldElement, []algebra.FieldElement, []algebra.FieldElement, poly.Polynomial, []*big.Int, []byte, *Channel) {
+	domainSize, err := DomainSize(1024, blowup)
+	if err != nil {
+		panic(err)
+	}
+	size := int64(domainSize)
 	a := GenSeq()
 	g := PrimeFieldGen.Exp(new(big.Int).SetInt64(3145728))
 	G := GenElems(g, 1024)
 	points := generatePoints(G[:len(G)-1], a)
 	f := poly.Lagrange(points, PrimeField.Modulus())
-	hGenerator := PrimeFieldGen.Exp(big.NewInt(393216))
-	H := make([]algebra.FieldElement, 8192)
+	hGenerator := PrimeFieldGen.Exp(big.NewInt(3221225472 / size))
+	H := GenElems(hGenerator, int(size))
 	var i int64
-	for i = 0; i < 8192; i++ {
-		H[i] = hGenerator.Exp(big.NewInt(i))
-	}
-	evalDomain := make([]algebra.FieldElement, 8192)
-	for i = 0; i < 8192; i++ {
+	evalDomain := make([]algebra.FieldElement, size)
+	for i = 0; i < size; i++ {
 		evalDomain[i] = PrimeField.Mul(PrimeFieldGen, H[i])
 	}
 	h := PrimeFieldGen
 	hInv := h.Inv()
 	// Sanity checks
-	for i = 0; i < 8192; i++ {
+	for i = 0; i < size; i++ {
 		if !PrimeField.Mul(PrimeField.Mul(hInv, evalDomain[1]).Exp(big.NewInt(i)), h).Equal(evalDomain[i]) {
 			panic("error eval domain is incorrect")
 		}
@@ -290,6 +571,90 @@ func GenerateDomainParameters() ([]algebra.FieldElement, algebra.FieldElement, [
 	return a, g, G, hGenerator, H, evalDomain, f, cosetEval, commitmentRoot, fsChan
 
 }
+
+// DefaultFibonacciParameters builds in memory the domain parameters of
+// domainparams.json i.e those of GenerateDomainParameters with a blowup of
+// 8, so tests don't depend on the JSON file.
+// The 1023 rows are interpolated over the first 1023 elements of G with an
+// NTT rather than poly.Lagrange : the interpolant over G of degree lower
+// than 1024 has a leading coefficient (1/1024).Sum(v_i.g^i) since
+// g^-1023 = g, choosing v_1023 = -g.Sum_{i<1023}(v_i.g^i) cancels it and
+// leaves the interpolant of degree 1022 of the trace.
+func DefaultFibonacciParameters() (*DomainParameters, error) {
+
+	const traceLen, blowup = 1024, 8
+	size, err := DomainSize(traceLen, blowup)
+	if err != nil {
+		return nil, err
+	}
+	a := GenSeq()
+	g, h := subgroupGenerator(traceLen), subgroupGenerator(size)
+	G := GenElems(g, traceLen)
+
+	sum := PrimeField.Zero()
+	for i, v := range a {
+		sum = PrimeField.Add(sum, PrimeField.Mul(v, G[i]))
+	}
+	evals := append(append([]algebra.FieldElement{}, a...), PrimeField.Mul(g, sum).Neg())
+	f, err := InterpolateSubgroup(evals, g, PrimeField.Modulus())
+	if err != nil {
+		return nil, err
+	}
+	if f.Degree() != len(a)-1 {
+		return nil, fmt.Errorf("trace interpolant has degree %d instead of %d", f.Degree(), len(a)-1)
+	}
+
+	cosetEvals, err := f.EvalCosetNTT(PrimeFieldGen, h, size, PrimeField.Modulus())
+	if err != nil {
+		return nil, err
+	}
+	H := GenElems(h, int(size))
+	domain := make([]algebra.FieldElement, size)
+	cosetEval := make([]*big.Int, size)
+	for i := range domain {
+		domain[i] = PrimeField.Mul(PrimeFieldGen, H[i])
+		cosetEval[i] = cosetEvals[i].Big()
+	}
+
+	return &DomainParameters{
+		Trace:                 a,
+		GeneratorG:            g,
+		SubgroupG:             G,
+		GeneratorH:            h,
+		SubgroupH:             H,
+		EvaluationDomain:      domain,
+		Polynomial:            f,
+		PolynomialEvaluations: cosetEval,
+		EvaluationRoot:        DomainHash(cosetEvals),
+	}, nil
+}
+
+// maxDomainSize is the order of the largest power of two subgroup of the
+// prime field i.e q - 1 = 3.2^30.
+const maxDomainSize = 1 << 30
+
+// DomainSize returns the evaluation domain size traceLen * blowup, the
+// size must be a power of two within the largest power of two subgroup of
+// the prime field. The product is checked for overflow so large inputs
+// report an error instead of wrapping around.
+func DomainSize(traceLen, blowup int) (uint64, error) {
+
+	if traceLen < 1 || blowup < 1 {
+		return 0, fmt.Errorf("trace length %d and blowup %d must be positive", traceLen, blowup)
+	}
+	hi, size := bits.Mul64(uint64(traceLen), uint64(blowup))
+	if hi != 0 || size > math.MaxInt {
+		return 0, fmt.Errorf("domain size %d * %d overflows", traceLen, blowup)
+	}
+	if size&(size-1) != 0 {
+		return 0, fmt.Errorf("domain size %d * %d = %d isn't a power of two", traceLen, blowup, size)
+	}
+	if size > maxDomainSize {
+		return 0, fmt.Errorf("domain size %d exceeds the largest subgroup of order %d", size, maxDomainSize)
+	}
+	return size, nil
+}
+
 func generatePoints(x []algebra.FieldElement, y []algebra.FieldElement) []poly.Point {
 
 	if len(x) != len(y) {
@@ -3,7 +3,9 @@ package stark
 import (
 	"encoding/hex"
 	"fmt"
+	"math"
 	"os"
+	"strings"
 	"testing"
 	"time"
 	"runtime"
@@ -11,6 +13,7 @@ import (
 	"github.com/ayushn2/go-stark.git/poly"
 	"github.com/stretchr/testify/assert"
 	"bytes"
+	"math/big"
 )
 
 // Measure CPU utilization
@@ -134,17 +137,26 @@ func TestZKGen(t *testing.T) {
 		// Start timing the proof verification
 		startTime := time.Now()
 
-		friDomains, friPolys, friLayers, friRoots := GenerateFRICommitment(compositionPoly, paramsInstance.EvaluationDomain, compositionPolyEvals, compositionPolyEvalsRoot, *fsChannel)
+		replay := &Channel{State: append([]byte{}, fsChannel.State...)}
+		friDomains, friPolys, friLayers, friRoots, err := GenerateFRICommitment(compositionPoly, paramsInstance.EvaluationDomain, compositionPolyEvals, compositionPolyEvalsRoot, fsChannel)
+		assert.NoError(t, err)
 
 		// Log FRI layers and roots information
-		assert.Len(t, friLayers, 11)
+		assert.Len(t, friLayers, ExpectedFRILayers(uint64(len(paramsInstance.EvaluationDomain)), 2, 8))
 		assert.Len(t, friLayers[len(friLayers)-1], 8)
-		expectedLastLayerConstant := PrimeField.NewFieldElementFromInt64(2550486681)
-		for _, x := range friLayers[len(friLayers)-1] {
-			assert.True(t, x.Equal(expectedLastLayerConstant))
+		// the challenges are replayed from the transcript before the FRI
+		var betas []algebra.FieldElement
+		for _, root := range friRoots[1:] {
+			betas = append(betas, PrimeField.NewFieldElement(replay.RandFE(PrimeField.Modulus())))
+			replay.Send(root)
 		}
+		expectedLastLayerConstant, err := ExpectedLastLayerConstant(compositionPoly, 2, betas)
+		assert.NoError(t, err)
+		lastLayerConstant, ok := IsConstantLayer(friLayers[len(friLayers)-1])
+		assert.True(t, ok)
+		assert.True(t, lastLayerConstant.Equal(expectedLastLayerConstant))
 
-		assert.Equal(t, friPolys[len(friPolys)-1].Degree(), 0)
+		assert.True(t, friPolys[len(friPolys)-1].IsConstant())
 
 		t.Log("FRI-Layer Count :", len(friLayers))
 		t.Log("FRI-Root Count", len(friRoots))
@@ -160,7 +172,8 @@ func TestZKGen(t *testing.T) {
 
 		// Now perform proof verification
 		cosetEvals := paramsInstance.PolynomialEvaluations
-		FRIDecommit(fsChannel, cosetEvals, friLayers)
+		_, err = FRIDecommit(fsChannel, cosetEvals, friLayers)
+		assert.NoError(t, err)
 
 		// End timing the proof verification
 		elapsedTime := time.Since(startTime)
@@ -254,7 +267,10 @@ func TestProofGenerationTime(t *testing.T) {
 	}
 	compositionPolyEvalsRoot := DomainHash(compositionPolyEvals)
 
-	GenerateFRICommitment(compositionPoly, paramsInstance.EvaluationDomain, compositionPolyEvals, compositionPolyEvalsRoot, *fsChannel)
+	_, _, _, _, err = GenerateFRICommitment(compositionPoly, paramsInstance.EvaluationDomain, compositionPolyEvals, compositionPolyEvalsRoot, fsChannel)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	elapsedTime := time.Since(startTime)
 	fmt.Printf("Proof generation time: %v\n", elapsedTime)
@@ -277,3 +293,180 @@ func TestProofGenerationTime(t *testing.T) {
 
 }
 
+
+func TestRecomputeEvaluationRoot(t *testing.T) {
+	params, _ := loadFibonacci(t)
+
+	root, err := params.RecomputeEvaluationRoot()
+	assert.NoError(t, err)
+	assert.Equal(t, params.EvaluationRoot, root)
+	assert.NoError(t, params.Validate())
+
+	tampered := *params
+	tampered.PolynomialEvaluations = append([]*big.Int{}, params.PolynomialEvaluations...)
+	tampered.PolynomialEvaluations[42] = new(big.Int).Add(tampered.PolynomialEvaluations[42], big.NewInt(1))
+
+	root, err = tampered.RecomputeEvaluationRoot()
+	assert.NoError(t, err)
+	assert.NotEqual(t, params.EvaluationRoot, root)
+	assert.Error(t, tampered.Validate())
+}
+
+func TestUnmarshalJSONNormalization(t *testing.T) {
+	// 20 and -3 aren't reduced modulo 17
+	b := []byte(`{"Field":"17","computation_trace":["1","20"],"G_generator":"4","G_subgroup":["1","4","16","13"],"H_generator":"2","H_subgroup":["1","2"],"evaluation_domain":["3","6"],"interpoland_polynomial":["1","1"],"polynomial_evaluations":["4","-3"],"evaluation_commitment":"00ff"}`)
+
+	params := &DomainParameters{}
+	assert.NoError(t, params.UnmarshalJSON(b))
+	assert.Equal(t, "3", params.Trace[1].Big().String())
+	assert.Equal(t, "14", params.PolynomialEvaluations[1].String())
+
+	strict := &DomainParameters{}
+	assert.ErrorIs(t, strict.UnmarshalJSONStrict(b), errNotReduced)
+
+	reduced := bytes.ReplaceAll(bytes.ReplaceAll(b, []byte(`"20"`), []byte(`"3"`)), []byte(`"-3"`), []byte(`"14"`))
+	assert.NoError(t, strict.UnmarshalJSONStrict(reduced))
+	assert.True(t, strict.Trace[1].Equal(params.Trace[1]))
+	assert.Equal(t, 0, strict.PolynomialEvaluations[1].Cmp(params.PolynomialEvaluations[1]))
+}
+
+func TestParseJSONStrict(t *testing.T) {
+	valid := `{"Field":"17","computation_trace":["1","2"],"G_generator":"4","G_subgroup":["1","4","16","13"],"H_generator":"2","H_subgroup":["1","2"],"evaluation_domain":["3","6"],"interpoland_polynomial":["1","1"],"polynomial_evaluations":["4","7"],"evaluation_commitment":"00ff"}`
+	typo := strings.Replace(valid, `"evaluation_commitment"`, `"evaluation_comitment"`, 1)
+	trailing := valid + ` {"Field":"17"}`
+
+	for _, strict := range []bool{false, true} {
+		params := &DomainParameters{}
+		assert.NoError(t, params.ParseJSON([]byte(valid), strict))
+		assert.NoError(t, params.ParseJSON([]byte(valid+"\n"), strict))
+	}
+
+	// the typo leaves the evaluation root empty unless strict
+	params := &DomainParameters{}
+	assert.NoError(t, params.ParseJSON([]byte(typo), false))
+	assert.Empty(t, params.EvaluationRoot)
+	assert.ErrorContains(t, params.ParseJSON([]byte(typo), true), "evaluation_comitment")
+
+	assert.Error(t, params.ParseJSON([]byte(trailing), false))
+	assert.ErrorIs(t, params.ParseJSON([]byte(trailing), true), errTrailingJSON)
+	assert.ErrorIs(t, params.ParseJSON([]byte(valid+" garbage"), true), errTrailingJSON)
+}
+
+func TestDomainSize(t *testing.T) {
+	size, err := DomainSize(1024, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8192), size)
+
+	// near the 32 bits limits : not a power of two, beyond the field
+	// subgroups or overflowing, never wrapping around
+	_, err = DomainSize(math.MaxInt32, 2)
+	assert.ErrorContains(t, err, "isn't a power of two")
+	_, err = DomainSize(1<<16, 1<<16)
+	assert.ErrorContains(t, err, "exceeds the largest subgroup")
+	_, err = DomainSize(math.MaxInt, math.MaxInt32)
+	assert.ErrorContains(t, err, "overflows")
+	_, err = DomainSize(0, 8)
+	assert.Error(t, err)
+}
+
+func TestReadWriteDomainParameters(t *testing.T) {
+	params, _ := loadFibonacci(t)
+
+	var buf bytes.Buffer
+	n, err := params.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	read, err := ReadDomainParameters(&buf)
+	assert.NoError(t, err)
+	assert.True(t, read.GeneratorG.Equal(params.GeneratorG))
+	assert.Equal(t, params.EvaluationRoot, read.EvaluationRoot)
+	assert.Len(t, read.EvaluationDomain, len(params.EvaluationDomain))
+	assert.Equal(t, 0, read.Polynomial.Compare(&params.Polynomial))
+
+	_, err = ReadDomainParameters(strings.NewReader("{"))
+	assert.Error(t, err)
+}
+
+func TestTracePolynomialDegree(t *testing.T) {
+	params, _ := loadFibonacci(t)
+
+	assert.Equal(t, len(params.Trace)-1, params.TracePolynomialDegree())
+	assert.NoError(t, params.CheckTraceDegree())
+
+	// adding the zerofier of G keeps the trace values but not the degree
+	m := PrimeField.Modulus()
+	zerofier := poly.NewPolynomialInts(1).MulXPow(len(params.SubgroupG)).Sub(poly.NewPolynomialInts(1), m)
+	tampered := *params
+	tampered.Polynomial = params.Polynomial.Add(zerofier, m)
+	assert.Equal(t, params.Polynomial.EvalAt(params.SubgroupG[7]), tampered.Polynomial.EvalAt(params.SubgroupG[7]))
+	assert.Equal(t, len(params.SubgroupG), tampered.TracePolynomialDegree())
+	assert.Error(t, tampered.CheckTraceDegree())
+}
+
+func TestEvalDomainPoint(t *testing.T) {
+	params, _ := loadFibonacci(t)
+	offset, h := params.EvaluationDomain[0], params.GeneratorH
+
+	for _, i := range []int{0, 1, 7, 1905, 4096, len(params.EvaluationDomain) - 1} {
+		assert.True(t, EvalDomainPoint(offset, h, i).Equal(params.EvaluationDomain[i]), "index %d", i)
+	}
+	// the domain is cyclic
+	assert.True(t, EvalDomainPoint(offset, h, len(params.EvaluationDomain)).Equal(offset))
+}
+
+func TestDefaultFibonacciParameters(t *testing.T) {
+	paramBytes, err := os.ReadFile("domainparams.json")
+	assert.NoError(t, err)
+	expected := &DomainParameters{}
+	assert.NoError(t, expected.UnmarshalJSON(paramBytes))
+
+	params, err := DefaultFibonacciParameters()
+	assert.NoError(t, err)
+	equal, diff := params.Equal(expected)
+	assert.True(t, equal, diff)
+	assert.NoError(t, params.Validate())
+}
+
+func TestDomainParametersEqual(t *testing.T) {
+	params, _ := loadFibonacci(t)
+	m := PrimeField.Modulus()
+
+	// trailing zero and unreduced coefficients are representation quirks
+	other := *params
+	other.Polynomial = append(params.Polynomial.Clone(0), new(big.Int))
+	other.Polynomial[0].Add(other.Polynomial[0], m)
+	other.PolynomialEvaluations = append([]*big.Int{}, params.PolynomialEvaluations...)
+	other.PolynomialEvaluations[5] = new(big.Int).Add(params.PolynomialEvaluations[5], m)
+	equal, diff := params.Equal(&other)
+	assert.True(t, equal, diff)
+
+	for _, tc := range []struct {
+		diff   string
+		modify func(p *DomainParameters)
+	}{
+		{"Trace length 1023 != 1022", func(p *DomainParameters) { p.Trace = p.Trace[:1022] }},
+		{"GeneratorG", func(p *DomainParameters) { p.GeneratorG = algebra.FieldElement{} }},
+		{"SubgroupH[12]", func(p *DomainParameters) {
+			p.SubgroupH = append([]algebra.FieldElement{}, p.SubgroupH...)
+			p.SubgroupH[12] = p.SubgroupH[12].Double()
+		}},
+		{"EvaluationDomain length 8192 != 0", func(p *DomainParameters) { p.EvaluationDomain = nil }},
+		{"Polynomial", func(p *DomainParameters) { p.Polynomial = p.Polynomial.MulXPow(1) }},
+		{"PolynomialEvaluations[3]", func(p *DomainParameters) {
+			p.PolynomialEvaluations = append([]*big.Int{}, p.PolynomialEvaluations...)
+			p.PolynomialEvaluations[3] = nil
+		}},
+		{"EvaluationRoot", func(p *DomainParameters) { p.EvaluationRoot = p.EvaluationRoot[1:] }},
+	} {
+		other := *params
+		tc.modify(&other)
+		equal, diff := params.Equal(&other)
+		assert.False(t, equal, tc.diff)
+		assert.Equal(t, tc.diff, diff)
+	}
+
+	equal, diff = params.Equal(nil)
+	assert.False(t, equal)
+	assert.Equal(t, "nil parameters", diff)
+}
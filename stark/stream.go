@@ -0,0 +1,141 @@
+package stark
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+)
+
+// A proof is mostly made of its query openings, VerifyStream replays the
+// header of a JSON proof and then checks each query as it is decoded so
+// the verifier only holds one query at a time instead of the whole proof.
+// MarshalJSON writes the queries last, the header fields must all be read
+// before the queries are.
+
+var errStreamOrder = errors.New("proof header field after the queries")
+
+// VerifyStream verifies the JSON proof read from r as Verify does, failing
+// on the first query that doesn't verify without reading the next ones.
+// Proofs holding more than DefaultMaxQueries queries are rejected.
+func VerifyStream(modulus *algebra.Integer, traceRoot []byte, publicInputs PublicInputs, r io.Reader, cfg ProverConfig) (bool, error) {
+
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return false, err
+	}
+	header := make(map[string]json.RawMessage)
+	verified := false
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return false, err
+		}
+		key, _ := token.(string)
+		if verified {
+			return false, fmt.Errorf("%w : %s", errStreamOrder, key)
+		}
+		if key != "queries" {
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return false, err
+			}
+			header[key] = value
+			continue
+		}
+		for _, required := range []string{"field", "fri_roots", "last_layer"} {
+			if _, ok := header[required]; !ok {
+				return false, fmt.Errorf("%w : %s", errStreamOrder, required)
+			}
+		}
+		ok, err := verifyQueries(dec, modulus, traceRoot, publicInputs, header, cfg)
+		if !ok || err != nil {
+			return false, err
+		}
+		verified = true
+	}
+	if !verified {
+		return false, errNoQueries
+	}
+	return true, expectDelim(dec, '}')
+}
+
+// verifyQueries decodes the proof header and checks the queries array read
+// from dec one query at a time.
+func verifyQueries(dec *json.Decoder, modulus *algebra.Integer, traceRoot []byte, publicInputs PublicInputs, header map[string]json.RawMessage, cfg ProverConfig) (bool, error) {
+
+	b, err := json.Marshal(header)
+	if err != nil {
+		return false, err
+	}
+	var jsonProof jsonStarkProof
+	if err := json.Unmarshal(b, &jsonProof); err != nil {
+		return false, err
+	}
+	proof, err := jsonProof.decode()
+	if err != nil {
+		return false, err
+	}
+	v, err := newVerifier(modulus, traceRoot, publicInputs, proof, cfg)
+	if err != nil {
+		return false, err
+	}
+	if err := expectDelim(dec, '['); err != nil {
+		return false, err
+	}
+
+	q := 0
+	for ; dec.More(); q++ {
+		if q >= DefaultMaxQueries {
+			return false, errTooManyQueries
+		}
+		var jsonQuery jsonFRIQuery
+		if err := dec.Decode(&jsonQuery); err != nil {
+			return false, err
+		}
+		query, err := jsonQuery.decode(v.field)
+		if err != nil {
+			return false, err
+		}
+		// the number of composition columns is read from the first query
+		if q == 0 {
+			columns := 0
+			if len(proof.ColumnsRoot) > 0 {
+				columns = len(query.Columns.Values)
+			}
+			if result, err := v.replayHeader(columns); result.Failure != nil || err != nil {
+				return false, err
+			}
+		}
+		if q >= len(v.indices) {
+			return false, errQueriesCount
+		}
+		if result, err := v.checkQuery(q, query); result.Failure != nil || err != nil {
+			return false, err
+		}
+	}
+	if q == 0 {
+		if result, err := v.replayHeader(0); result.Failure != nil || err != nil {
+			return false, err
+		}
+	}
+	if q != len(v.indices) {
+		return false, errQueriesCount
+	}
+	return true, expectDelim(dec, ']')
+}
+
+// expectDelim reads the next token of dec which must be the delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("invalid proof encoding : expected %v, got %v", delim, token)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package stark
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r *bytes.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestVerifyStream(t *testing.T) {
+	params, proof := loadFibonacciProof(t)
+	pub := params.PublicInputs()
+	m := PrimeField.Modulus()
+	b, err := json.Marshal(proof)
+	assert.NoError(t, err)
+
+	ok, err := VerifyStream(m, params.EvaluationRoot, pub, bytes.NewReader(b), ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = VerifyStream(m, params.EvaluationRoot, pub, bytes.NewReader(b), ProverConfig{ProofOfWorkBits: 8})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// the first query fails, the following ones aren't read
+	tampered := proof
+	tampered.FRI.Queries = append([]FRIQuery{}, proof.FRI.Queries...)
+	for i := 0; i < 64; i++ {
+		tampered.FRI.Queries = append(tampered.FRI.Queries, proof.FRI.Queries...)
+	}
+	tampered.FRI.Queries[0].Index++
+	tb, err := json.Marshal(tampered)
+	assert.NoError(t, err)
+	r := &countingReader{r: bytes.NewReader(tb)}
+	ok, err = VerifyStream(m, params.EvaluationRoot, pub, r, ProverConfig{})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Less(t, r.n, len(tb)/8)
+
+	// an extra query is read before it is rejected as Verify does
+	tampered.FRI.Queries = tampered.FRI.Queries[:len(proof.FRI.Queries)+1]
+	tampered.FRI.Queries[0] = proof.FRI.Queries[0]
+	tb, err = json.Marshal(tampered)
+	assert.NoError(t, err)
+	_, err = VerifyStream(m, params.EvaluationRoot, pub, bytes.NewReader(tb), ProverConfig{})
+	assert.ErrorIs(t, err, errQueriesCount)
+
+	// the header must be read before the queries
+	reordered := append([]byte(`{"queries":[],`), bytes.TrimPrefix(b, []byte("{"))...)
+	_, err = VerifyStream(m, params.EvaluationRoot, pub, bytes.NewReader(reordered), ProverConfig{})
+	assert.ErrorIs(t, err, errStreamOrder)
+	_, err = VerifyStream(m, params.EvaluationRoot, pub, bytes.NewReader(b[:len(b)/2]), ProverConfig{})
+	assert.Error(t, err)
+}
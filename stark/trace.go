@@ -0,0 +1,142 @@
+package stark
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+)
+
+// Programs that produce their trace step by step (e.g a VM execution) feed
+// the rows to a TraceBuilder as the computation runs, Finalize then builds
+// the domain parameters GenerateDomainParameters builds for the Fibonacci
+// program :
+// - The trace is padded to a power of two by repeating its last row
+// - The trace polynomial is interpolated over the subgroup G of that order
+// - It is evaluated over the coset PrimeFieldGen.H of order |G|.blowup
+// - The evaluations are committed with DomainHash
+// Both the interpolation and the evaluation use an NTT.
+
+var (
+	errEmptyTrace = errors.New("trace has no rows")
+	errTraceWidth = errors.New("domain parameters hold a single trace column")
+)
+
+// TraceBuilder accumulates the rows of an execution trace.
+type TraceBuilder struct {
+	rows [][]algebra.FieldElement
+}
+
+// NewTraceBuilder creates an empty trace builder.
+func NewTraceBuilder() *TraceBuilder {
+	return &TraceBuilder{}
+}
+
+// Len returns the number of rows appended so far.
+func (tb *TraceBuilder) Len() int {
+	return len(tb.rows)
+}
+
+// AppendRow appends a row to the trace, the row is copied.
+// It panics if the row is empty or its width differs from the first row.
+func (tb *TraceBuilder) AppendRow(row []algebra.FieldElement) {
+
+	if len(row) == 0 || (len(tb.rows) > 0 && len(row) != len(tb.rows[0])) {
+		panic(fmt.Sprintf("invalid trace row of width %d", len(row)))
+	}
+	tb.rows = append(tb.rows, append([]algebra.FieldElement{}, row...))
+}
+
+// Finalize pads the trace to a power of two, interpolates and commits it
+// over an evaluation domain blowup times larger and checks that the padded
+// trace satisfies the constraints of the AIR.
+func (tb *TraceBuilder) Finalize(air AIR, blowup int) (*DomainParameters, error) {
+
+	if len(tb.rows) == 0 {
+		return nil, errEmptyTrace
+	}
+	if len(tb.rows[0]) != 1 {
+		return nil, errTraceWidth
+	}
+	n := 1
+	for n < len(tb.rows) {
+		n <<= 1
+	}
+	size, err := DomainSize(n, blowup)
+	if err != nil {
+		return nil, err
+	}
+
+	trace := make([]algebra.FieldElement, n)
+	for i := range trace {
+		trace[i] = tb.rows[len(tb.rows)-1][0]
+		if i < len(tb.rows) {
+			trace[i] = tb.rows[i][0]
+		}
+	}
+
+	g, h := subgroupGenerator(uint64(n)), subgroupGenerator(size)
+
+	f, err := InterpolateSubgroup(trace, g, PrimeField.Modulus())
+	if err != nil {
+		return nil, err
+	}
+	evals, err := f.EvalCosetNTT(PrimeFieldGen, h, size, PrimeField.Modulus())
+	if err != nil {
+		return nil, err
+	}
+
+	H := GenElems(h, int(size))
+	domain := make([]algebra.FieldElement, size)
+	cosetEval := make([]*big.Int, size)
+	for i := range domain {
+		domain[i] = PrimeField.Mul(PrimeFieldGen, H[i])
+		cosetEval[i] = evals[i].Big()
+	}
+
+	params := &DomainParameters{
+		Trace:                 trace,
+		GeneratorG:            g,
+		SubgroupG:             GenElems(g, n),
+		GeneratorH:            h,
+		SubgroupH:             H,
+		EvaluationDomain:      domain,
+		Polynomial:            f,
+		PolynomialEvaluations: cosetEval,
+		EvaluationRoot:        DomainHash(evals),
+	}
+	if err := params.CheckConstraintsOnTraceDomain(air); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// InterpolateSubgroup returns the polynomial of degree less than n taking
+// the values evals[i] at root^i with an inverse NTT in O(n log n), root
+// must be a primitive nth root of unity for n = len(evals) a power of two.
+// Unlike poly.Lagrange, which interpolates any set of points in O(n^3),
+// this only works over a subgroup.
+func InterpolateSubgroup(evals []algebra.FieldElement, root algebra.FieldElement, modulus *algebra.Integer) (poly.Polynomial, error) {
+
+	if modulus == nil || root.Field().Modulus().Cmp(modulus) != 0 {
+		return nil, errModulusMismatch
+	}
+	plan, err := poly.NewNTTPlan(root, uint64(len(evals)))
+	if err != nil {
+		return nil, err
+	}
+	coeffs, err := plan.Inverse(evals)
+	if err != nil {
+		return nil, err
+	}
+	return poly.NewPolynomial(coeffs), nil
+}
+
+// subgroupGenerator returns a generator of the subgroup of the given order
+// of the prime field, the order must divide q - 1.
+func subgroupGenerator(order uint64) algebra.FieldElement {
+	groupOrder := new(big.Int).Sub(PrimeField.Modulus(), big.NewInt(1))
+	return PrimeFieldGen.Exp(new(big.Int).Div(groupOrder, new(big.Int).SetUint64(order)))
+}
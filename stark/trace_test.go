@@ -0,0 +1,120 @@
+package stark
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceBuilder(t *testing.T) {
+	tb := NewTraceBuilder()
+	_, err := tb.Finalize(FibonacciAIR{}, 8)
+	assert.ErrorIs(t, err, errEmptyTrace)
+
+	a, b := PrimeField.NewFieldElementFromInt64(1), PrimeField.NewFieldElementFromInt64(3141592)
+	tb.AppendRow([]algebra.FieldElement{a})
+	tb.AppendRow([]algebra.FieldElement{b})
+	for tb.Len() < 1023 {
+		a, b = b, PrimeField.Add(a.Square(), b.Square())
+		tb.AppendRow([]algebra.FieldElement{b})
+	}
+	assert.Panics(t, func() { tb.AppendRow([]algebra.FieldElement{a, b}) })
+
+	params, err := tb.Finalize(FibonacciAIR{}, 8)
+	assert.NoError(t, err)
+	assert.Len(t, params.Trace, 1024)
+	assert.Equal(t, GenSeq(), params.Trace[:1023])
+	assert.Equal(t, params.Trace[1022], params.Trace[1023])
+	assert.Len(t, params.EvaluationDomain, 8192)
+	assert.NoError(t, params.Validate())
+	for i, x := range params.SubgroupG[:1023] {
+		assert.Equal(t, 0, params.Polynomial.Eval(x.Big(), PrimeField.Modulus()).Cmp(params.Trace[i].Big()), "row %d", i)
+	}
+
+	state, err := Prove(params, ProverConfig{})
+	assert.NoError(t, err)
+	proof, err := state.Proof()
+	assert.NoError(t, err)
+	ok, err := VerifyWithCommitment(PrimeField.Modulus(), params.EvaluationRoot, params.PublicInputs(), proof, ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = tb.Finalize(FibonacciAIR{}, 3)
+	assert.Error(t, err)
+
+	wide := NewTraceBuilder()
+	wide.AppendRow([]algebra.FieldElement{a, b})
+	_, err = wide.Finalize(FibonacciAIR{}, 8)
+	assert.ErrorIs(t, err, errTraceWidth)
+
+	// a trace breaking the transition constraint is rejected
+	broken := NewTraceBuilder()
+	for i, v := range GenSeq() {
+		if i == 500 {
+			v = v.AddInt64(1)
+		}
+		broken.AppendRow([]algebra.FieldElement{v})
+	}
+	_, err = broken.Finalize(FibonacciAIR{}, 8)
+	assert.Error(t, err)
+}
+
+func TestInterpolateSubgroup(t *testing.T) {
+	m := PrimeField.Modulus()
+	const n = 64
+	g := subgroupGenerator(n)
+	evals := make([]algebra.FieldElement, n)
+	for i := range evals {
+		evals[i] = PrimeField.NewFieldElementFromInt64(int64(3141592*i + 1))
+	}
+
+	p, err := InterpolateSubgroup(evals, g, m)
+	assert.NoError(t, err)
+	assert.Less(t, p.Degree(), n)
+	for i, x := range GenElems(g, n) {
+		assert.True(t, p.EvalAt(x).Equal(evals[i]), "point %d", i)
+	}
+
+	_, err = InterpolateSubgroup(evals[:48], g, m)
+	assert.Error(t, err)
+	// g^2 only generates a subgroup of order n/2
+	_, err = InterpolateSubgroup(evals, g.Square(), m)
+	assert.Error(t, err)
+	_, err = InterpolateSubgroup(evals, g, nil)
+	assert.ErrorIs(t, err, errModulusMismatch)
+}
+
+// benchmarkSubgroupPoints returns n evaluations over the subgroup of order n.
+func benchmarkSubgroupPoints(n int) (algebra.FieldElement, []algebra.FieldElement, []poly.Point) {
+	g := subgroupGenerator(uint64(n))
+	evals := make([]algebra.FieldElement, n)
+	points := make([]poly.Point, n)
+	for i, x := range GenElems(g, n) {
+		evals[i] = PrimeField.NewFieldElementFromInt64(int64(i + 1))
+		points[i] = poly.NewPoint(x.Big(), evals[i].Big())
+	}
+	return g, evals, points
+}
+
+// Lagrange interpolation is cubic so it is compared on 2^8 points, the
+// inverse NTT also runs on the 2^14 points of a real trace.
+func BenchmarkInterpolateSubgroup(b *testing.B) {
+	m := PrimeField.Modulus()
+	for _, n := range []int{1 << 8, 1 << 14} {
+		g, evals, _ := benchmarkSubgroupPoints(n)
+		b.Run(fmt.Sprintf("ntt-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				InterpolateSubgroup(evals, g, m)
+			}
+		})
+	}
+	_, _, points := benchmarkSubgroupPoints(1 << 8)
+	b.Run("lagrange-256", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			poly.Lagrange(points, m)
+		}
+	})
+}
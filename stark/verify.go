@@ -0,0 +1,657 @@
+package stark
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+)
+
+// The verifier never sees the trace or composition polynomials, it only
+// receives the values opened by the prover at the queried points of the
+// evaluation domain. Soundness comes from re-deriving what those values
+// must be from the AIR and checking the prover agrees.
+
+// ColumnOpening holds the values opened by the prover at a query.
+type ColumnOpening struct {
+	// Index of the query in the evaluation domain.
+	Index int
+	// X is the evaluation domain point at Index.
+	X algebra.FieldElement
+	// Trace holds f(g^k.x) for each offset k of the AIR.
+	Trace []algebra.FieldElement
+	// Composition holds the composition polynomial evaluation cp(x).
+	Composition algebra.FieldElement
+	// Path is the authentication path of Trace[0] at Index when it is
+	// opened against a trace commitment.
+	Path [][]byte
+}
+
+var (
+	errCoeffsCount = errors.New("composition coefficients count doesn't match AIR constraints")
+	errDEEPPoint   = errors.New("query point coincides with the out of domain point")
+	errNoTrace     = errors.New("opening has no trace values")
+
+	errOffsetNotOpened = errors.New("trace row offset read by the AIR isn't opened")
+)
+
+// CheckCompositionAtQueries re-derives the composition polynomial value
+// at each opening as the random linear combination of the AIR constraints
+// evaluated on the opened trace values, and compares it to the opened
+// composition value. z is the generator of the trace domain.
+func CheckCompositionAtQueries(openings []ColumnOpening, coeffs []algebra.FieldElement, air AIR, z algebra.FieldElement) (bool, error) {
+
+	if len(coeffs) != air.NumConstraints() {
+		return false, errCoeffsCount
+	}
+
+	for _, opening := range openings {
+		if len(opening.Trace) != len(air.Offsets()) {
+			return false, errTraceValuesCount
+		}
+		evals, err := EvalConstraints(air, opening.X, z, opening.Trace)
+		if err != nil {
+			return false, err
+		}
+		field := opening.X.Field()
+		expected := field.Zero()
+		for i, eval := range evals {
+			expected = field.Add(expected, field.Mul(coeffs[i], eval))
+		}
+		if !expected.Equal(opening.Composition) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// CheckTransitionAtQuery checks a single query point x where the trace is
+// opened row by row : traceOpenings maps each offset k of the AIR to the
+// opened value f(g^k.x) e.g f(x) and f(g.x) for a transition between
+// adjacent rows. The constraints are recomputed from these values and
+// combined with coeffs, the result must be the opened composition value
+// (see CheckCompositionAtQueries). Every offset read by the AIR must be
+// opened.
+func CheckTransitionAtQuery(traceOpenings map[int]algebra.FieldElement, air AIR, x, g algebra.FieldElement, coeffs []algebra.FieldElement, composition algebra.FieldElement) (bool, error) {
+
+	offsets := air.Offsets()
+	if len(traceOpenings) != len(offsets) {
+		return false, errTraceValuesCount
+	}
+	values := make([]algebra.FieldElement, len(offsets))
+	for i, k := range offsets {
+		v, ok := traceOpenings[k]
+		if !ok {
+			return false, fmt.Errorf("%w : offset %d", errOffsetNotOpened, k)
+		}
+		values[i] = v
+	}
+	opening := ColumnOpening{X: x, Trace: values, Composition: composition}
+	return CheckCompositionAtQueries([]ColumnOpening{opening}, coeffs, air, g)
+}
+
+// The DEEP (Domain Extension for Eliminating Pretenders) technique samples
+// an out of domain point z, the prover claims f(z) and commits trough FRI to
+// the quotient (f(x) - f(z)) / (x - z) which is a polynomial only if the
+// claim is correct.
+
+// CheckDEEPQuotient checks that the quotient value opened at the query point
+// x is consistent with the opened trace value f(x) and the claimed f(z) i.e
+// quotientVal.(x - z) = f(x) - f(z) mod modulus.
+// The query point x must differ from z.
+func CheckDEEPQuotient(opening ColumnOpening, z, fz algebra.FieldElement, x algebra.FieldElement, quotientVal algebra.FieldElement, modulus *algebra.Integer) (bool, error) {
+
+	if len(opening.Trace) == 0 {
+		return false, errNoTrace
+	}
+	den := algebra.ModSub(x.Big(), z.Big(), modulus)
+	if den.Sign() == 0 {
+		return false, errDEEPPoint
+	}
+	num := algebra.ModSub(opening.Trace[0].Big(), fz.Big(), modulus)
+	lhs := algebra.ModMul(quotientVal.Big(), den, modulus)
+
+	return lhs.Cmp(num) == 0, nil
+}
+
+// Boundary claims the trace holds Value at Row e.g a public output.
+type Boundary struct {
+	Row   int
+	Value algebra.FieldElement
+}
+
+var errBoundaryNotOpened = errors.New("boundary row isn't opened")
+
+// VerifyBoundaries checks the boundary claims against a commitment to the
+// trace rows made by CommitRows over the width registers of the trace.
+// Each claimed row must be opened, the first register of the opening at
+// Index = Row must equal the claimed value and the leaf must hash up to
+// rowsRoot.
+// The EvaluationRoot of the domain parameters can't be used here : it
+// commits to the evaluations over the coset which holds none of the trace
+// rows g^i, the prover must commit to the rows with CommitRows and open
+// them with OpenRow.
+// This validates the public inputs without a full verification, it says
+// nothing about the trace satisfying the AIR.
+func VerifyBoundaries(rowsRoot []byte, width int, ff algebra.FiniteField, boundaries []Boundary, openings []RowOpening) (bool, error) {
+
+	if width < 1 {
+		return false, errNoTrace
+	}
+	opened := make(map[int]RowOpening, len(openings))
+	for _, opening := range openings {
+		opened[opening.Index] = opening
+	}
+	for _, boundary := range boundaries {
+		opening, ok := opened[boundary.Row]
+		if !ok {
+			return false, errBoundaryNotOpened
+		}
+		values, ok := VerifyRow(rowsRoot, opening, width, ff)
+		if !ok || !values[0].Equal(boundary.Value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+var (
+	errModulusMismatch      = errors.New("public inputs aren't defined over the given modulus")
+	errNoFRIRoots           = errors.New("proof has no FRI roots")
+	errQueriesCount         = errors.New("proof queries count doesn't match the drawn queries")
+	errQueryOpenings        = errors.New("query openings count doesn't match the FRI layers")
+	errLastLayerNotConstant = errors.New("last FRI layer isn't constant")
+	errNoQueries            = errors.New("queries aren't opened, run OpenQueries first")
+	errExtensionRoots       = errors.New("proof extension roots don't match the AIR extension columns")
+)
+
+// VerificationCheck names a check run by Verify.
+type VerificationCheck string
+
+// The checks run by Verify.
+const (
+	CheckPublicOutputs   VerificationCheck = "public outputs"
+	CheckTranscript      VerificationCheck = "transcript replay"
+	CheckProofOfWork     VerificationCheck = "proof of work"
+	CheckCompositionRoot VerificationCheck = "composition root"
+	CheckMerkle          VerificationCheck = "merkle openings"
+	CheckComposition     VerificationCheck = "composition consistency"
+	CheckFolding         VerificationCheck = "folding relation"
+	CheckLastLayer       VerificationCheck = "last layer constancy"
+)
+
+// CheckResult reports a check, Query and Layer locate a failure and are
+// -1 when they don't apply.
+type CheckResult struct {
+	Check  VerificationCheck
+	OK     bool
+	Query  int
+	Layer  int
+	Detail string
+}
+
+// VerificationResult lists the checks run by Verify in the order they were
+// first run, the verification stops at the first failing check which is
+// reported as Failure. A check listed as OK passed everywhere it ran.
+type VerificationResult struct {
+	OK      bool
+	Checks  []CheckResult
+	Failure *CheckResult
+}
+
+// checkLog records the checks run by Verify.
+type checkLog struct {
+	checks []CheckResult
+	index  map[VerificationCheck]int
+}
+
+// pass records that the check passed.
+func (l *checkLog) pass(check VerificationCheck) {
+	if _, ok := l.index[check]; !ok {
+		l.index[check] = len(l.checks)
+		l.checks = append(l.checks, CheckResult{Check: check, OK: true, Query: -1, Layer: -1})
+	}
+}
+
+// fail records the failure of the check and returns the failed result.
+func (l *checkLog) fail(check VerificationCheck, query, layer int, detail string) VerificationResult {
+	failure := CheckResult{Check: check, Query: query, Layer: layer, Detail: detail}
+	if i, ok := l.index[check]; ok {
+		l.checks[i] = failure
+	} else {
+		l.checks = append(l.checks, failure)
+	}
+	return VerificationResult{Checks: l.checks, Failure: &failure}
+}
+
+// VerifyWithCommitment verifies the proof against the trace commitment
+// traceRoot supplied by the caller, see Verify for the failure details.
+func VerifyWithCommitment(modulus *algebra.Integer, traceRoot []byte, publicInputs PublicInputs, proof StarkProof, cfg ProverConfig) (bool, error) {
+	result, err := Verify(modulus, traceRoot, publicInputs, proof, cfg)
+	return result.OK, err
+}
+
+// Verify verifies the proof against the trace commitment traceRoot
+// supplied by the caller, a proof over another field is rejected by its
+// field header. The Fiat-Shamir transcript of the prover is
+// replayed starting with traceRoot so the composition weights, the FRI
+// challenges and the query indices are all bound to it and to the public
+// outputs of the proof, which must be those of the boundary constraints of
+// the AIR. The proof mustn't fold more FRI rounds than the composition
+// degree bound of the AIR allows (see ExpectedFRIRounds). A proof of work
+// difficulty above 40 bits is rejected as ProofOfWork does. After checking
+// that the proof was ground with the proof of work
+// difficulty of cfg and its nonce if enabled, for each query it checks :
+// - The trace and FRI layer openings against their commitments
+// - The composition value re-derived from the AIR and the trace openings
+// - The folding of each FRI layer into the next one down to the last layer
+// The proof must carry a root per extension column of a RandomizedAIR, the
+// extension openings are checked against them along with the trace
+// openings and feed the composition value. A proof splitting the
+// composition in columns (see SplitComposition) mustn't have more columns
+// than the composition degree bound allows, the opened columns must match
+// their commitment and their combination the first FRI layer, which then
+// mustn't fold more rounds than a column of the trace domain size.
+// A malformed proof is reported as an error, a proof that doesn't verify
+// returns a result reporting the failed check.
+func Verify(modulus *algebra.Integer, traceRoot []byte, publicInputs PublicInputs, proof StarkProof, cfg ProverConfig) (VerificationResult, error) {
+
+	v, err := newVerifier(modulus, traceRoot, publicInputs, proof, cfg)
+	if err != nil {
+		return VerificationResult{}, err
+	}
+	columns := 0
+	if len(proof.ColumnsRoot) > 0 && len(proof.FRI.Queries) > 0 {
+		columns = len(proof.FRI.Queries[0].Columns.Values)
+	}
+	if result, err := v.replayHeader(columns); result.Failure != nil || err != nil {
+		return result, err
+	}
+	if len(proof.FRI.Queries) != len(v.indices) {
+		return VerificationResult{}, errQueriesCount
+	}
+	for q, query := range proof.FRI.Queries {
+		if result, err := v.checkQuery(q, query); result.Failure != nil || err != nil {
+			return result, err
+		}
+	}
+	return VerificationResult{OK: true, Checks: v.log.checks}, nil
+}
+
+// verifier replays the transcript of a proof, replayHeader replays the
+// commitments up to the query indices and checkQuery the openings of each
+// query in the order they were drawn.
+type verifier struct {
+	field     algebra.FiniteField
+	traceRoot []byte
+	pub       PublicInputs
+	cfg       ProverConfig
+	air       AIR
+	rap       RandomizedAIR
+	// randomized is true when air is a RandomizedAIR.
+	randomized  bool
+	n           int
+	traceDomain int
+	// header holds the commitments of the proof, its queries are ignored.
+	header StarkProof
+
+	log       *checkLog
+	ch        *Channel
+	challenge algebra.FieldElement
+	coeffs    []algebra.FieldElement
+	weights   []algebra.FieldElement
+	betas     []algebra.FieldElement
+	columns   int
+	indices   []int
+}
+
+// newVerifier checks the field and the commitments shape of the proof
+// header before its transcript is replayed.
+func newVerifier(modulus *algebra.Integer, traceRoot []byte, publicInputs PublicInputs, header StarkProof, cfg ProverConfig) (*verifier, error) {
+
+	field := publicInputs.TraceGenerator.Field()
+	if modulus == nil || field.Modulus() == nil || field.Modulus().Cmp(modulus) != 0 {
+		return nil, errModulusMismatch
+	}
+	if cfg.ProofOfWorkBits > maxPowBits {
+		return nil, errPowDifficulty
+	}
+	if err := header.Field.check(field); err != nil {
+		return nil, err
+	}
+	air := publicInputs.air()
+	n := publicInputs.DomainSize
+	roots := header.FRI.Roots
+	if len(roots) == 0 {
+		return nil, errNoFRIRoots
+	}
+	rounds, err := ExpectedFRIRounds(air, n, publicInputs.Blowup)
+	if err != nil {
+		return nil, err
+	}
+	traceDomain := n / publicInputs.Blowup
+	if len(header.ColumnsRoot) > 0 {
+		// FRI bounds the degree of every column
+		rounds = bits.Len(uint(traceDomain - 1))
+	}
+	if len(roots)-1 > rounds {
+		return nil, fmt.Errorf("%w : %d rounds, expected at most %d", errFRIRounds, len(roots)-1, rounds)
+	}
+	rap, randomized := air.(RandomizedAIR)
+	if randomized && len(header.ExtensionRoots) != rap.NumExtensionColumns() {
+		return nil, fmt.Errorf("%w : %d roots, the AIR has %d extension columns", errExtensionRoots, len(header.ExtensionRoots), rap.NumExtensionColumns())
+	}
+	if !randomized && len(header.ExtensionRoots) != 0 {
+		return nil, fmt.Errorf("%w : %d roots, the AIR has no extension columns", errExtensionRoots, len(header.ExtensionRoots))
+	}
+	return &verifier{
+		field:       field,
+		traceRoot:   traceRoot,
+		pub:         publicInputs,
+		cfg:         cfg,
+		air:         air,
+		rap:         rap,
+		randomized:  randomized,
+		n:           n,
+		traceDomain: traceDomain,
+		header:      header,
+		log:         &checkLog{index: make(map[VerificationCheck]int)},
+	}, nil
+}
+
+// replayHeader replays the commitments of the proof with the given number
+// of composition columns, 0 when the composition isn't split, down to the
+// query indices.
+func (v *verifier) replayHeader(columns int) (VerificationResult, error) {
+
+	proof, log, field, modulus := v.header, v.log, v.field, v.field.Modulus()
+	if len(proof.ColumnsRoot) > 0 && columns == 0 {
+		return VerificationResult{}, errQueryOpenings
+	}
+	if columns > MaxCompositionColumns(v.air, v.traceDomain) {
+		return VerificationResult{}, fmt.Errorf("%w : %d columns, expected at most %d", errCompositionColumns, columns, MaxCompositionColumns(v.air, v.traceDomain))
+	}
+	v.columns = columns
+
+	ch := NewChannel()
+	v.ch = ch
+	ch.Send(v.traceRoot)
+	if err := checkPublicOutputs(v.air, v.pub.TraceGenerator, proof.PublicOutputs); err != nil {
+		return log.fail(CheckPublicOutputs, -1, -1, err.Error()), nil
+	}
+	log.pass(CheckPublicOutputs)
+	sendPublicOutputs(ch, proof.PublicOutputs)
+	if v.randomized {
+		v.challenge = field.NewFieldElement(ch.RandFE(modulus))
+		for _, root := range proof.ExtensionRoots {
+			ch.Send(root)
+		}
+	}
+	v.coeffs = v.cfg.combiner().Coefficients(v.air.NumConstraints(), ch)
+	if columns > 0 {
+		ch.Send(proof.ColumnsRoot)
+		for j := 0; j < columns; j++ {
+			v.weights = append(v.weights, field.NewFieldElement(ch.RandFE(modulus)))
+		}
+	}
+	roots := proof.FRI.Roots
+	ch.Send(roots[0])
+
+	for _, root := range roots[1:] {
+		v.betas = append(v.betas, field.NewFieldElement(ch.RandFE(modulus)))
+		ch.Send(root)
+	}
+	ch.Send(proof.FRI.LastLayer.Big().Bytes())
+
+	if proof.ProofOfWorkBits != v.cfg.ProofOfWorkBits {
+		return log.fail(CheckProofOfWork, -1, -1, fmt.Sprintf("proof ground %d bits of proof of work, expected %d", proof.ProofOfWorkBits, v.cfg.ProofOfWorkBits)), nil
+	}
+	if v.cfg.ProofOfWorkBits > 0 {
+		if !ch.VerifyProofOfWork(proof.ProofOfWorkNonce, v.cfg.ProofOfWorkBits) {
+			return log.fail(CheckProofOfWork, -1, -1, fmt.Sprintf("nonce doesn't reach %d bits", v.cfg.ProofOfWorkBits)), nil
+		}
+		log.pass(CheckProofOfWork)
+	}
+
+	v.indices = drawQueryIndices(ch, v.n)
+	return VerificationResult{}, nil
+}
+
+// checkQuery checks the openings of the qth query and replays them.
+func (v *verifier) checkQuery(q int, query FRIQuery) (VerificationResult, error) {
+
+	proof, log, n, ch := v.header, v.log, v.n, v.ch
+	roots, offsets := proof.FRI.Roots, v.air.Offsets()
+	if query.Index != v.indices[q] {
+		return log.fail(CheckTranscript, q, -1, fmt.Sprintf("query index %d, the transcript draws %d", query.Index, v.indices[q])), nil
+	}
+	log.pass(CheckTranscript)
+	if len(query.Trace) != len(offsets) || len(query.Layers) != len(v.betas) || len(query.Siblings) != len(v.betas) ||
+		len(query.Extension) != len(proof.ExtensionRoots)*len(offsets) || len(query.Columns.Values) != v.columns {
+		return VerificationResult{}, errQueryOpenings
+	}
+
+	trace := make([]algebra.FieldElement, len(offsets))
+	for i, k := range offsets {
+		opening := query.Trace[i]
+		if opening.Index != ((query.Index+k*v.pub.Blowup)%n+n)%n {
+			return log.fail(CheckMerkle, q, -1, fmt.Sprintf("trace opening %d at index %d", i, opening.Index)), nil
+		}
+		if !VerifyLayerOpening(v.traceRoot, opening.Value, opening.Index, opening.Path) {
+			return log.fail(CheckMerkle, q, -1, fmt.Sprintf("trace opening %d doesn't match the trace commitment", i)), nil
+		}
+		ch.Send(opening.Value.Big().Bytes())
+		ch.Send(serializeLayerPath(opening.Index, opening.Path))
+		trace[i] = opening.Value
+	}
+
+	// the extension openings are sent after the FRI layers as the
+	// prover does
+	extension := make([][]algebra.FieldElement, len(proof.ExtensionRoots))
+	for c, root := range proof.ExtensionRoots {
+		extension[c] = make([]algebra.FieldElement, len(offsets))
+		for i, k := range offsets {
+			opening := query.Extension[c*len(offsets)+i]
+			if opening.Index != ((query.Index+k*v.pub.Blowup)%n+n)%n {
+				return log.fail(CheckMerkle, q, -1, fmt.Sprintf("extension column %d opening %d at index %d", c, i, opening.Index)), nil
+			}
+			if !VerifyLayerOpening(root, opening.Value, opening.Index, opening.Path) {
+				return log.fail(CheckMerkle, q, -1, fmt.Sprintf("extension column %d opening %d doesn't match its commitment", c, i)), nil
+			}
+			extension[c][i] = opening.Value
+		}
+	}
+
+	x := EvalDomainPoint(v.pub.DomainOffset, v.pub.DomainGenerator, query.Index)
+	// a constant composition (e.g only degree 0 constraints) isn't
+	// folded, its value is the last layer constant
+	composition := proof.FRI.LastLayer
+	if len(query.Layers) > 0 {
+		// the composition value must hash up to the committed root
+		// before it is checked against the constraints
+		opened := query.Layers[0]
+		if opened.Index != query.Index || !VerifyLayerOpening(roots[0], opened.Value, opened.Index, opened.Path) {
+			return log.fail(CheckCompositionRoot, q, 0, "composition opening doesn't match the composition commitment"), nil
+		}
+		log.pass(CheckCompositionRoot)
+		composition = opened.Value
+	}
+	if v.columns > 0 {
+		// FRI runs on the combination of the columns, the composition
+		// value is recombined from the columns
+		opened := query.Columns
+		if opened.Index != query.Index {
+			return log.fail(CheckMerkle, q, -1, fmt.Sprintf("composition columns opening at index %d", opened.Index)), nil
+		}
+		cp, ok := RecombineComposition(proof.ColumnsRoot, opened, x, v.traceDomain)
+		if !ok {
+			return log.fail(CheckMerkle, q, -1, "composition columns don't match their commitment"), nil
+		}
+		if !mixColumns(v.weights, opened.Values).Equal(composition) {
+			return log.fail(CheckCompositionRoot, q, 0, "composition columns don't match the FRI commitment"), nil
+		}
+		log.pass(CheckCompositionRoot)
+		composition = cp
+	}
+	var ok bool
+	var err error
+	if v.randomized {
+		ok, err = checkExtendedComposition(v.rap, x, v.pub.TraceGenerator, v.challenge, trace, extension, v.coeffs, composition)
+	} else {
+		opening := ColumnOpening{Index: query.Index, X: x, Trace: trace, Composition: composition}
+		ok, err = CheckCompositionAtQueries([]ColumnOpening{opening}, v.coeffs, v.air, v.pub.TraceGenerator)
+	}
+	if err != nil {
+		return VerificationResult{}, err
+	}
+	if !ok {
+		return log.fail(CheckComposition, q, 0, "composition value doesn't match the constraints"), nil
+	}
+	log.pass(CheckComposition)
+
+	if check, layer, ok := verifyFRILayers(ch, query, roots, v.betas, proof.FRI.LastLayer, x, n); !ok {
+		return log.fail(check, q, layer, fmt.Sprintf("FRI layer %d", layer)), nil
+	}
+	for _, opening := range query.Extension {
+		ch.Send(opening.Value.Big().Bytes())
+		ch.Send(serializeLayerPath(opening.Index, opening.Path))
+	}
+	if v.columns > 0 {
+		ch.Send(PackFieldElements(query.Columns.Values))
+		ch.Send(serializeLayerPath(query.Columns.Index, query.Columns.Path))
+	}
+	if len(query.Layers) > 0 {
+		log.pass(CheckMerkle)
+		log.pass(CheckFolding)
+		log.pass(CheckLastLayer)
+	}
+	return VerificationResult{}, nil
+}
+
+// checkExtendedComposition re-derives the composition value at x from the
+// trace and extension values as CheckCompositionAtQueries does.
+func checkExtendedComposition(air RandomizedAIR, x, g, challenge algebra.FieldElement, trace []algebra.FieldElement, extension [][]algebra.FieldElement, coeffs []algebra.FieldElement, composition algebra.FieldElement) (bool, error) {
+
+	if len(coeffs) != air.NumConstraints() {
+		return false, errCoeffsCount
+	}
+	evals, err := EvalExtendedConstraints(air, x, g, challenge, trace, extension)
+	if err != nil {
+		return false, err
+	}
+	field := x.Field()
+	expected := field.Zero()
+	for i, eval := range evals {
+		expected = field.Add(expected, field.Mul(coeffs[i], eval))
+	}
+	return expected.Equal(composition), nil
+}
+
+// verifyFRILayers checks the FRI layer openings of the query at x against
+// the layer roots and their folding down to the last layer, the openings
+// are sent trough the channel as DecommitFRILayers does.
+// It returns the failed check and the layer it failed at.
+func verifyFRILayers(ch *Channel, query FRIQuery, roots [][]byte, betas []algebra.FieldElement, lastLayer, x algebra.FieldElement, n int) (VerificationCheck, int, bool) {
+
+	length := n
+	for i := range query.Layers {
+		elem, sibling := query.Layers[i], query.Siblings[i]
+		if elem.Index != query.Index%length || sibling.Index != (elem.Index+length/2)%length {
+			return CheckMerkle, i, false
+		}
+		if !VerifyLayerOpening(roots[i], elem.Value, elem.Index, elem.Path) ||
+			!VerifyLayerOpening(roots[i], sibling.Value, sibling.Index, sibling.Path) {
+			return CheckMerkle, i, false
+		}
+		ch.Send(elem.Value.Big().Bytes())
+		ch.Send(serializeLayerPath(elem.Index, elem.Path))
+		ch.Send(sibling.Value.Big().Bytes())
+		ch.Send(serializeLayerPath(sibling.Index, sibling.Path))
+
+		// the layer is folded as the prover does (see FoldLayer)
+		folded := foldPair(elem.Value, sibling.Value, x, betas[i])
+
+		if i+1 < len(query.Layers) {
+			if !folded.Equal(query.Layers[i+1].Value) {
+				return CheckFolding, i, false
+			}
+		} else if !folded.Equal(lastLayer) {
+			// the last layer is the constant sent by the prover
+			return CheckLastLayer, i, false
+		}
+		x = x.Square()
+		length /= 2
+	}
+	ch.Send(lastLayer.Big().Bytes())
+	return "", -1, true
+}
+
+var (
+	errFirstRoot        = errors.New("first FRI root doesn't match the committed root")
+	errQueryNotInProof  = errors.New("query index isn't drawn by the transcript")
+	errUnknownLayerSize = errors.New("FRI layer size can't be derived from the proof openings")
+)
+
+// VerifyFRISubset verifies the FRI queries of the proof at the given
+// domain indices only, it is a triage aid to quickly check a few queries
+// while developing : a proof passing it isn't sound since the unchecked
+// queries may be forged, use Verify for anything else.
+// ch holds the transcript up to the commitment of the first FRI layer e.g
+// after the constraint coefficients are drawn, firstRoot is sent trough it
+// and must be the first root of the proof. The evaluation domain is the
+// coset PrimeFieldGen.H, its size is derived from the authentication paths.
+// The proof may hold the first queries drawn only (see StarkProof.Prune).
+// Proofs carrying a proof of work nonce aren't supported.
+func VerifyFRISubset(proof FRIProof, firstRoot []byte, queryIndices []int, ch *Channel) (bool, error) {
+
+	roots := proof.Roots
+	if len(roots) == 0 {
+		return false, errNoFRIRoots
+	}
+	if !bytes.Equal(roots[0], firstRoot) {
+		return false, errFirstRoot
+	}
+	if len(proof.Queries) == 0 || len(proof.Queries[0].Layers) == 0 {
+		return false, errUnknownLayerSize
+	}
+	n := 1 << len(proof.Queries[0].Layers[0].Path)
+	modulus := PrimeField.Modulus()
+
+	ch.Send(firstRoot)
+	betas := make([]algebra.FieldElement, 0, len(roots)-1)
+	for _, root := range roots[1:] {
+		betas = append(betas, PrimeField.NewFieldElement(ch.RandFE(modulus)))
+		ch.Send(root)
+	}
+	ch.Send(proof.LastLayer.Big().Bytes())
+
+	indices := drawQueryIndices(ch, n)
+	if len(proof.Queries) > len(indices) {
+		return false, errQueriesCount
+	}
+	queries := make(map[int]FRIQuery, len(indices))
+	for q, query := range proof.Queries {
+		if query.Index != indices[q] {
+			return false, nil
+		}
+		queries[query.Index] = query
+	}
+
+	generator := subgroupGenerator(uint64(n))
+	for _, index := range queryIndices {
+		query, ok := queries[index]
+		if !ok {
+			return false, errQueryNotInProof
+		}
+		if len(query.Layers) != len(betas) || len(query.Siblings) != len(betas) {
+			return false, errQueryOpenings
+		}
+		x := EvalDomainPoint(PrimeFieldGen, generator, index)
+		if _, _, ok := verifyFRILayers(ch, query, roots, betas, proof.LastLayer, x, n); !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
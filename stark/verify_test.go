@@ -0,0 +1,434 @@
+package stark
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/ayushn2/go-stark.git/poly"
+	"github.com/stretchr/testify/assert"
+)
+
+// fibOpening opens the trace and composition values at index of the
+// evaluation domain, g = h^8 so f(g.x) sits 8 indexes further.
+func fibOpening(params *DomainParameters, cp poly.Polynomial, index int) ColumnOpening {
+	x := params.EvaluationDomain[index]
+	return ColumnOpening{
+		Index: index,
+		X:     x,
+		Trace: []algebra.FieldElement{
+			PrimeField.NewFieldElement(params.PolynomialEvaluations[index]),
+			PrimeField.NewFieldElement(params.PolynomialEvaluations[index+8]),
+			PrimeField.NewFieldElement(params.PolynomialEvaluations[index+16]),
+		},
+		Composition: PrimeField.NewFieldElement(cp.Eval(x.Big(), PrimeField.Modulus())),
+	}
+}
+
+func TestCheckCompositionAtQueries(t *testing.T) {
+	params, constraints := loadFibonacci(t)
+
+	coeffs := []algebra.FieldElement{
+		PrimeField.NewFieldElementFromInt64(7),
+		PrimeField.NewFieldElementFromInt64(11),
+		PrimeField.NewFieldElementFromInt64(13),
+	}
+	cp := poly.NewPolynomialInts(0)
+	for i, c := range constraints {
+		cp = cp.Add(c.Mul(poly.NewPolynomialBigInt(coeffs[i].Big()), PrimeField.Modulus()), PrimeField.Modulus())
+	}
+
+	openings := []ColumnOpening{
+		fibOpening(params, cp, 17),
+		fibOpening(params, cp, 1000),
+		fibOpening(params, cp, 4321),
+	}
+
+	ok, err := CheckCompositionAtQueries(openings, coeffs, FibonacciAIR{}, params.GeneratorG)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	openings[1].Trace[2] = openings[1].Trace[2].Double()
+	ok, err = CheckCompositionAtQueries(openings, coeffs, FibonacciAIR{}, params.GeneratorG)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestCheckTransitionAtQuery(t *testing.T) {
+	params, constraints := loadFibonacci(t)
+
+	coeffs := []algebra.FieldElement{
+		PrimeField.NewFieldElementFromInt64(2),
+		PrimeField.NewFieldElementFromInt64(3),
+		PrimeField.NewFieldElementFromInt64(5),
+	}
+	cp := combine(constraints, coeffs)
+	opening := fibOpening(params, cp, 2024)
+	rows := map[int]algebra.FieldElement{0: opening.Trace[0], 1: opening.Trace[1], 2: opening.Trace[2]}
+
+	ok, err := CheckTransitionAtQuery(rows, FibonacciAIR{}, opening.X, params.GeneratorG, coeffs, opening.Composition)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// f(g.x) is manipulated, the adjacent rows no longer satisfy the
+	// transition constraint
+	rows[1] = rows[1].Double()
+	ok, err = CheckTransitionAtQuery(rows, FibonacciAIR{}, opening.X, params.GeneratorG, coeffs, opening.Composition)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	delete(rows, 1)
+	rows[3] = opening.Trace[1]
+	_, err = CheckTransitionAtQuery(rows, FibonacciAIR{}, opening.X, params.GeneratorG, coeffs, opening.Composition)
+	assert.ErrorIs(t, err, errOffsetNotOpened)
+	delete(rows, 3)
+	_, err = CheckTransitionAtQuery(rows, FibonacciAIR{}, opening.X, params.GeneratorG, coeffs, opening.Composition)
+	assert.ErrorIs(t, err, errTraceValuesCount)
+}
+
+func TestCheckDEEPQuotient(t *testing.T) {
+	f := poly.NewPolynomialInts(3, 1, 4, 1, 5, 9, 2, 6)
+	m := PrimeField.Modulus()
+
+	z := PrimeField.NewFieldElementFromInt64(2718281)
+	fz := PrimeField.NewFieldElement(f.Eval(z.Big(), m))
+	// (f(x) - f(z)) / (x - z) is a polynomial
+	quotient := f.Sub(poly.NewPolynomialBigInt(fz.Big()), m).Quo(poly.NewPolynomialBigInt(new(algebra.Integer).Neg(z.Big()), algebra.FromInt64(1)), m)
+
+	x := PrimeField.NewFieldElementFromInt64(31415)
+	opening := ColumnOpening{X: x, Trace: []algebra.FieldElement{PrimeField.NewFieldElement(f.Eval(x.Big(), m))}}
+	quotientVal := PrimeField.NewFieldElement(quotient.Eval(x.Big(), m))
+
+	ok, err := CheckDEEPQuotient(opening, z, fz, x, quotientVal, m)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = CheckDEEPQuotient(opening, z, fz, x, quotientVal.AddInt64(1), m)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = CheckDEEPQuotient(opening, z, fz, z, quotientVal, m)
+	assert.Error(t, err)
+}
+
+func TestVerifyWithCommitment(t *testing.T) {
+	params, proof := loadFibonacciProof(t)
+	pub := params.PublicInputs()
+	m := PrimeField.Modulus()
+
+	ok, err := VerifyWithCommitment(m, params.EvaluationRoot, pub, proof, ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// the challenges are bound to the trace commitment
+	wrongRoot := append([]byte{}, params.EvaluationRoot...)
+	wrongRoot[0] ^= 1
+	ok, err = VerifyWithCommitment(m, wrongRoot, pub, proof, ProverConfig{})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// the weights drawn by another combiner don't match the composition
+	ok, err = VerifyWithCommitment(m, params.EvaluationRoot, pub, proof, ProverConfig{Combiner: AlphaPowersCombiner{}})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// a tampered trace opening doesn't match the trace commitment
+	tampered := proof
+	tampered.FRI.Queries = append([]FRIQuery{}, proof.FRI.Queries...)
+	tampered.FRI.Queries[0].Trace = append([]FRILayerOpening{}, proof.FRI.Queries[0].Trace...)
+	tampered.FRI.Queries[0].Trace[1].Value = tampered.FRI.Queries[0].Trace[1].Value.Double()
+	ok, err = VerifyWithCommitment(m, params.EvaluationRoot, pub, tampered, ProverConfig{})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = VerifyWithCommitment(algebra.FromInt64(17), params.EvaluationRoot, pub, proof, ProverConfig{})
+	assert.ErrorIs(t, err, errModulusMismatch)
+}
+
+func TestVerifyBoundaries(t *testing.T) {
+	trace := GenSeq()[:8]
+	squares := make([]algebra.FieldElement, len(trace))
+	for i, x := range trace {
+		squares[i] = x.Square()
+	}
+	tree, err := CommitRows([][]algebra.FieldElement{trace, squares})
+	assert.NoError(t, err)
+	root := tree.Root()
+	open := func(row int) RowOpening {
+		opening, err := tree.OpenRow(row)
+		assert.NoError(t, err)
+		return opening
+	}
+	openings := []RowOpening{open(0), open(7)}
+
+	boundaries := []Boundary{{0, PrimeField.One()}, {7, trace[7]}}
+	ok, err := VerifyBoundaries(root, 2, PrimeField, boundaries, openings)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// mismatched claim
+	boundaries[1].Value = trace[6]
+	ok, err = VerifyBoundaries(root, 2, PrimeField, boundaries, openings)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// an opened row matching the claim but not the commitment
+	openings[1].Leaf = PackFieldElements([]algebra.FieldElement{trace[6], squares[7]})
+	ok, err = VerifyBoundaries(root, 2, PrimeField, boundaries, openings)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// a commitment to the values rather than the rows
+	ok, err = VerifyBoundaries(DomainHash(trace), 2, PrimeField, boundaries[:1], openings)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = VerifyBoundaries(root, 2, PrimeField, []Boundary{{3, trace[3]}}, openings)
+	assert.Equal(t, errBoundaryNotOpened, err)
+}
+
+func TestVerifyFailureReasons(t *testing.T) {
+	params, proof := loadFibonacciProof(t)
+	pub := params.PublicInputs()
+	m := PrimeField.Modulus()
+
+	result, err := Verify(m, params.EvaluationRoot, pub, proof, ProverConfig{})
+	assert.NoError(t, err)
+	assert.True(t, result.OK)
+	assert.Nil(t, result.Failure)
+	checks := make([]VerificationCheck, len(result.Checks))
+	for i, c := range result.Checks {
+		assert.True(t, c.OK)
+		checks[i] = c.Check
+	}
+	assert.Equal(t, []VerificationCheck{CheckPublicOutputs, CheckTranscript, CheckCompositionRoot, CheckComposition, CheckMerkle, CheckFolding, CheckLastLayer}, checks)
+
+	// tamper copies the proof queries before modifying the qth one
+	tamper := func(q int, modify func(*FRIQuery)) StarkProof {
+		tampered := proof
+		tampered.FRI.Queries = append([]FRIQuery{}, proof.FRI.Queries...)
+		query := tampered.FRI.Queries[q]
+		query.Trace = append([]FRILayerOpening{}, query.Trace...)
+		query.Layers = append([]FRILayerOpening{}, query.Layers...)
+		query.Trace[0].Path = append([][]byte{}, query.Trace[0].Path...)
+		query.Layers[0].Path = append([][]byte{}, query.Layers[0].Path...)
+		modify(&query)
+		tampered.FRI.Queries[q] = query
+		return tampered
+	}
+	wrongRoot := append([]byte{}, params.EvaluationRoot...)
+	wrongRoot[0] ^= 1
+
+	for _, tc := range []struct {
+		name  string
+		root  []byte
+		proof StarkProof
+		cfg   ProverConfig
+		check VerificationCheck
+		query int
+		layer int
+	}{
+		{"trace root", wrongRoot, proof, ProverConfig{}, CheckTranscript, 0, -1},
+		{"proof of work", params.EvaluationRoot, proof, ProverConfig{ProofOfWorkBits: 16}, CheckProofOfWork, -1, -1},
+		{"trace path", params.EvaluationRoot, tamper(1, func(q *FRIQuery) {
+			q.Trace[0].Path[0] = q.Trace[1].Path[0]
+		}), ProverConfig{}, CheckMerkle, 1, -1},
+		{"composition", params.EvaluationRoot, tamper(2, func(q *FRIQuery) {
+			q.Layers[0].Value = q.Layers[0].Value.Double()
+		}), ProverConfig{}, CheckCompositionRoot, 2, 0},
+		{"composition path", params.EvaluationRoot, tamper(1, func(q *FRIQuery) {
+			q.Layers[0].Path[0] = q.Trace[0].Path[0]
+		}), ProverConfig{}, CheckCompositionRoot, 1, 0},
+		{"folding", params.EvaluationRoot, tamper(0, func(q *FRIQuery) {
+			q.Layers[3].Value = q.Layers[3].Value.Double()
+		}), ProverConfig{}, CheckFolding, 0, 2},
+	} {
+		result, err := Verify(m, tc.root, pub, tc.proof, tc.cfg)
+		assert.NoError(t, err, tc.name)
+		assert.False(t, result.OK, tc.name)
+		if assert.NotNil(t, result.Failure, tc.name) {
+			assert.Equal(t, tc.check, result.Failure.Check, tc.name)
+			assert.Equal(t, tc.query, result.Failure.Query, tc.name)
+			assert.Equal(t, tc.layer, result.Failure.Layer, tc.name)
+			assert.Contains(t, result.Checks, *result.Failure, tc.name)
+		}
+
+		// the streaming verifier fails on the same proof bytes
+		b, err := json.Marshal(tc.proof)
+		assert.NoError(t, err, tc.name)
+		ok, err := VerifyStream(m, tc.root, pub, bytes.NewReader(b), tc.cfg)
+		assert.NoError(t, err, tc.name)
+		assert.False(t, ok, tc.name)
+	}
+}
+
+func TestVerifyProofOfWorkBits(t *testing.T) {
+	params, proof := loadFibonacciProof(t)
+	m := PrimeField.Modulus()
+
+	// a proof from a prover that didn't grind
+	result, err := Verify(m, params.EvaluationRoot, params.PublicInputs(), proof, ProverConfig{ProofOfWorkBits: 8})
+	assert.NoError(t, err)
+	assert.False(t, result.OK)
+	if assert.NotNil(t, result.Failure) {
+		assert.Equal(t, CheckProofOfWork, result.Failure.Check)
+		assert.Equal(t, "proof ground 0 bits of proof of work, expected 8", result.Failure.Detail)
+	}
+
+	state, err := Prove(params, ProverConfig{ProofOfWorkBits: 8})
+	assert.NoError(t, err)
+	ground, err := state.Proof()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(8), ground.ProofOfWorkBits)
+
+	b, err := json.Marshal(ground)
+	assert.NoError(t, err)
+	var decoded StarkProof
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, uint(8), decoded.ProofOfWorkBits)
+
+	result, err = Verify(m, params.EvaluationRoot, params.PublicInputs(), decoded, ProverConfig{ProofOfWorkBits: 8})
+	assert.NoError(t, err)
+	assert.True(t, result.OK)
+	result, err = Verify(m, params.EvaluationRoot, params.PublicInputs(), decoded, ProverConfig{ProofOfWorkBits: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, "proof ground 8 bits of proof of work, expected 4", result.Failure.Detail)
+
+	// difficulties that can't be ground are rejected upfront
+	_, err = Verify(m, params.EvaluationRoot, params.PublicInputs(), decoded, ProverConfig{ProofOfWorkBits: 257})
+	assert.ErrorIs(t, err, errPowDifficulty)
+	_, err = OpenQueries(&ProverState{
+		Config:    ProverConfig{ProofOfWorkBits: 257},
+		Channel:   NewChannel(),
+		FRILayers: [][]algebra.FieldElement{{}},
+	})
+	assert.ErrorIs(t, err, errPowDifficulty)
+}
+
+func TestVerifyFRIRounds(t *testing.T) {
+	params, proof := loadFibonacciProof(t)
+	pub := params.PublicInputs()
+
+	rounds, err := ExpectedFRIRounds(pub.air(), pub.DomainSize, pub.Blowup)
+	assert.NoError(t, err)
+	assert.Equal(t, 11, rounds)
+	// the trace of 1023 rows has a composition of degree 1023 folded in a
+	// round less than the bound of the trace domain
+	assert.Len(t, proof.FRI.Roots, 11)
+
+	// folding rounds beyond the bound turn any composition into a constant
+	extra := proof
+	last := proof.FRI.Roots[len(proof.FRI.Roots)-1]
+	extra.FRI.Roots = append(append([][]byte{}, proof.FRI.Roots...), last, last)
+	_, err = Verify(PrimeField.Modulus(), params.EvaluationRoot, pub, extra, ProverConfig{})
+	assert.ErrorIs(t, err, errFRIRounds)
+
+	_, err = ExpectedFRIRounds(FibonacciAIR{}, 8192, 0)
+	assert.Error(t, err)
+}
+
+func TestVerifyFRILayersLastLayer(t *testing.T) {
+	fri := newSmallFRI(t)
+	n := len(fri.domain)
+
+	// replay the FRI commitment transcript
+	ch := NewChannel()
+	ch.Send(fri.roots[0])
+	var betas []algebra.FieldElement
+	for _, root := range fri.roots[1:] {
+		betas = append(betas, PrimeField.NewFieldElement(ch.RandFE(PrimeField.Modulus())))
+		ch.Send(root)
+	}
+
+	const index = 77
+	query, err := DecommitFRILayers(index, NewChannel(), fri.layers)
+	assert.NoError(t, err)
+	lastLayer := fri.layers[len(fri.layers)-1][0]
+	_, _, ok := verifyFRILayers(NewChannel(), query, fri.roots, betas, lastLayer, fri.domain[index], n)
+	assert.True(t, ok)
+
+	check, layer, ok := verifyFRILayers(NewChannel(), query, fri.roots, betas, lastLayer.Double(), fri.domain[index], n)
+	assert.False(t, ok)
+	assert.Equal(t, CheckLastLayer, check)
+	assert.Equal(t, len(betas)-1, layer)
+}
+
+// claimedOutputAIR is the Fibonacci AIR claiming another value at row 1022.
+type claimedOutputAIR struct {
+	FibonacciAIR
+	claimed algebra.FieldElement
+}
+
+func (a claimedOutputAIR) EvalNumerators(x, g algebra.FieldElement, values []algebra.FieldElement) ([]algebra.FieldElement, error) {
+	nums, err := a.FibonacciAIR.EvalNumerators(x, g, values)
+	if err != nil {
+		return nil, err
+	}
+	nums[1] = x.Field().Sub(values[0], a.claimed)
+	return nums, nil
+}
+
+func TestVerifyPublicOutputs(t *testing.T) {
+	params, proof := loadFibonacciProof(t)
+	pub := params.PublicInputs()
+	m := PrimeField.Modulus()
+
+	assert.Equal(t, []Boundary{{0, params.Trace[0]}, {1022, params.Trace[1022]}}, proof.PublicOutputs)
+
+	altered := proof
+	altered.PublicOutputs = []Boundary{proof.PublicOutputs[0], {1022, proof.PublicOutputs[1].Value.AddInt64(1)}}
+	result, err := Verify(m, params.EvaluationRoot, pub, altered, ProverConfig{})
+	assert.NoError(t, err)
+	assert.False(t, result.OK)
+	assert.Equal(t, CheckPublicOutputs, result.Failure.Check)
+
+	// an AIR claiming the altered output accepts it but the FRI of the
+	// proof was bound to the original outputs
+	pub.AIR = claimedOutputAIR{claimed: altered.PublicOutputs[1].Value}
+	result, err = Verify(m, params.EvaluationRoot, pub, altered, ProverConfig{})
+	assert.NoError(t, err)
+	assert.False(t, result.OK)
+	assert.Equal(t, CheckTranscript, result.Failure.Check)
+
+	altered.PublicOutputs = altered.PublicOutputs[:1]
+	result, err = Verify(m, params.EvaluationRoot, params.PublicInputs(), altered, ProverConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, CheckPublicOutputs, result.Failure.Check)
+}
+
+func TestVerifyFRISubset(t *testing.T) {
+	params, proof := loadFibonacciProof(t)
+	transcript := func() *Channel {
+		ch := NewChannel()
+		ch.Send(params.EvaluationRoot)
+		sendPublicOutputs(ch, proof.PublicOutputs)
+		IndependentRandomCombiner{}.Coefficients(FibonacciAIR{}.NumConstraints(), ch)
+		return ch
+	}
+	subset := []int{proof.FRI.Queries[1].Index}
+
+	ok, err := VerifyFRISubset(proof.FRI, proof.FRI.Roots[0], subset, transcript())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = VerifyFRISubset(proof.FRI, proof.FRI.Roots[1], subset, transcript())
+	assert.Equal(t, errFirstRoot, err)
+	_, err = VerifyFRISubset(proof.FRI, proof.FRI.Roots[0], []int{proof.FRI.Queries[1].Index + 1}, transcript())
+	assert.Equal(t, errQueryNotInProof, err)
+
+	// a tampered query outside of the subset goes unnoticed, inside it fails
+	tampered := proof.FRI
+	tampered.Queries = append([]FRIQuery{}, proof.FRI.Queries...)
+	query := tampered.Queries[1]
+	query.Layers = append([]FRILayerOpening{}, query.Layers...)
+	query.Layers[2].Value = query.Layers[2].Value.Double()
+	tampered.Queries[1] = query
+
+	ok, err = VerifyFRISubset(tampered, proof.FRI.Roots[0], []int{tampered.Queries[0].Index}, transcript())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = VerifyFRISubset(tampered, proof.FRI.Roots[0], subset, transcript())
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,92 @@
+package stark
+
+import (
+	"fmt"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+)
+
+// CheckWitness checks the rows of a trace against the constraints of an AIR
+// before anything is interpolated or committed, so a broken trace or AIR is
+// reported as the first row and constraint that don't hold rather than as
+// a composition polynomial of the wrong degree.
+
+// WitnessError describes the first constraint a trace violates, Computed
+// holds the value of the constraint numerator at the row and Expected the
+// zero it must evaluate to.
+// Err is set instead when the trace can't be checked e.g a constraint holds
+// on a row outside of the trace.
+type WitnessError struct {
+	Row        int
+	Constraint int
+	Computed   algebra.FieldElement
+	Expected   algebra.FieldElement
+	Err        error
+}
+
+// Error implements error.
+func (e WitnessError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("constraint %d doesn't hold at row %d : numerator is %s, expected %s", e.Constraint, e.Row, e.Computed.Big(), e.Expected.Big())
+}
+
+// Unwrap returns the error that prevented checking the trace.
+func (e WitnessError) Unwrap() error {
+	return e.Err
+}
+
+// CheckWitness evaluates the constraints of the AIR on each row of the
+// trace, trace[i] holds the single column of the ith row as built by a
+// TraceBuilder and g generates the trace domain. The offsets reading past
+// the trace (e.g f(g.x) for the boundary constraint on the last row) read
+// zero, a constraint that depends on them is reported as violated.
+// It returns true if every constraint holds on its rows, otherwise false
+// and the WitnessError pinpointing the first violation in row order.
+func CheckWitness(trace [][]algebra.FieldElement, air AIR, g algebra.FieldElement) (bool, WitnessError) {
+
+	if len(trace) == 0 {
+		return false, WitnessError{Row: -1, Constraint: -1, Err: errEmptyTrace}
+	}
+	for i, row := range trace {
+		if len(row) != 1 {
+			return false, WitnessError{Row: i, Constraint: -1, Err: errTraceWidth}
+		}
+	}
+	rows := make(map[int][]int)
+	for c := 0; c < air.NumConstraints(); c++ {
+		for _, r := range air.ConstraintRows(c) {
+			if r < 0 || r >= len(trace) {
+				err := fmt.Errorf("constraint %d holds at row %d outside of the trace", c, r)
+				return false, WitnessError{Row: r, Constraint: c, Err: err}
+			}
+			rows[r] = append(rows[r], c)
+		}
+	}
+	offsets := air.Offsets()
+	zero := g.Field().Zero()
+	for r := range trace {
+		constraints, ok := rows[r]
+		if !ok {
+			continue
+		}
+		values := make([]algebra.FieldElement, len(offsets))
+		for i, k := range offsets {
+			values[i] = zero
+			if r+k >= 0 && r+k < len(trace) {
+				values[i] = trace[r+k][0]
+			}
+		}
+		nums, err := air.EvalNumerators(g.Exp(algebra.FromInt64(int64(r))), g, values)
+		if err != nil {
+			return false, WitnessError{Row: r, Constraint: -1, Err: err}
+		}
+		for _, c := range constraints {
+			if !nums[c].IsZero() {
+				return false, WitnessError{Row: r, Constraint: c, Computed: nums[c], Expected: zero}
+			}
+		}
+	}
+	return true, WitnessError{}
+}
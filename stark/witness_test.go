@@ -0,0 +1,37 @@
+package stark
+
+import (
+	"testing"
+
+	"github.com/ayushn2/go-stark.git/algebra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckWitness(t *testing.T) {
+	fib, _ := loadFibonacci(t)
+	trace := make([][]algebra.FieldElement, len(fib.Trace))
+	for i, v := range fib.Trace {
+		trace[i] = []algebra.FieldElement{v}
+	}
+	ok, werr := CheckWitness(trace, FibonacciAIR{}, fib.GeneratorG)
+	assert.True(t, ok)
+	assert.Equal(t, WitnessError{}, werr)
+
+	// row 500 is first read by the transition constraint at row 498
+	trace[500] = []algebra.FieldElement{trace[500][0].AddInt64(1)}
+	ok, werr = CheckWitness(trace, FibonacciAIR{}, fib.GeneratorG)
+	assert.False(t, ok)
+	assert.NoError(t, werr.Err)
+	assert.Equal(t, 498, werr.Row)
+	assert.Equal(t, 2, werr.Constraint)
+	assert.True(t, werr.Computed.Equal(PrimeField.NewFieldElementFromInt64(1)))
+	assert.True(t, werr.Expected.IsZero())
+	assert.EqualError(t, werr, "constraint 2 doesn't hold at row 498 : numerator is 1, expected 0")
+
+	ok, werr = CheckWitness(trace[:1000], FibonacciAIR{}, fib.GeneratorG)
+	assert.False(t, ok)
+	assert.EqualError(t, werr, "constraint 1 holds at row 1022 outside of the trace")
+	trace[3] = append(trace[3], trace[3][0])
+	_, werr = CheckWitness(trace, FibonacciAIR{}, fib.GeneratorG)
+	assert.ErrorIs(t, werr, errTraceWidth)
+}